@@ -210,9 +210,11 @@ var completeCmds = map[string]complete.Predictor{
 	"/rb":        complete.PredictOr(s3Complete{deepLevel: 2}, fsCompleter),
 	"/cat":       complete.PredictOr(s3Completer, fsCompleter),
 	"/head":      complete.PredictOr(s3Completer, fsCompleter),
+	"/tail":      complete.PredictOr(s3Completer, fsCompleter),
 	"/diff":      complete.PredictOr(s3Completer, fsCompleter),
 	"/find":      complete.PredictOr(s3Completer, fsCompleter),
 	"/mirror":    complete.PredictOr(s3Completer, fsCompleter),
+	"/sync":      complete.PredictOr(s3Completer, fsCompleter),
 	"/pipe":      complete.PredictOr(s3Completer, fsCompleter),
 	"/stat":      complete.PredictOr(s3Completer, fsCompleter),
 	"/watch":     complete.PredictOr(s3Completer, fsCompleter),
@@ -223,11 +225,20 @@ var completeCmds = map[string]complete.Predictor{
 	"/sql":       s3Completer,
 	"/lock":      complete.PredictOr(s3Complete{deepLevel: 2}),
 	"/mb":        aliasCompleter,
+	"/ping":      aliasCompleter,
 
 	"/event/add":    aliasCompleter,
 	"/event/list":   aliasCompleter,
 	"/event/remove": aliasCompleter,
 
+	"/legalhold/set":   s3Completer,
+	"/legalhold/clear": s3Completer,
+	"/legalhold/info":  s3Completer,
+
+	"/retention/set":   s3Completer,
+	"/retention/clear": s3Completer,
+	"/retention/info":  s3Completer,
+
 	"/share/download": s3Completer,
 	"/share/list":     nil,
 	"/share/upload":   s3Completer,
@@ -21,6 +21,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
 	"strings"
 	"syscall"
@@ -32,10 +33,20 @@ import (
 )
 
 var (
-	// This is kept dummy for future purposes
-	// and also to add ioFlags and globalFlags
-	// in CLI registration.
-	catFlags = []cli.Flag{}
+	catFlags = []cli.Flag{
+		cli.Int64Flag{
+			Name:  "offset",
+			Usage: "start reading at 'offset' bytes, a negative offset reads the last bytes",
+		},
+		cli.Int64Flag{
+			Name:  "length",
+			Usage: "read at most 'length' bytes",
+		},
+		cli.BoolFlag{
+			Name:  "decompress",
+			Usage: "decompress 'gzip', 'bzip2' and 'zstd' compressed objects",
+		},
+	}
 )
 
 // Display contents of a file.
@@ -73,6 +84,16 @@ EXAMPLES:
   5. Display the content of encrypted object. In case the encryption key contains non-printable character like tab, pass the
      base64 encoded string as key.
      {{.Prompt}} {{.HelpName}} --encrypt-key "play/my-bucket/=MzJieXRlc2xvbmdzZWNyZXRrZQltdXN0YmVnaXZlbjE="  play/my-bucket/my-object
+
+  6. Display 1KiB of an object starting at byte 4096.
+     {{.Prompt}} {{.HelpName}} --offset 4096 --length 1024 play/my-bucket/my-object
+
+  7. Display the last 512 bytes of an object.
+     {{.Prompt}} {{.HelpName}} --offset -512 play/my-bucket/my-object
+
+  8. Display a compressed log, the compression is detected from the object name, its
+     Content-Encoding or its content.
+     {{.Prompt}} {{.HelpName}} --decompress s3/logs/server.log.zst
 `,
 }
 
@@ -136,15 +157,109 @@ func checkCatSyntax(ctx *cli.Context) {
 			fatalIf(probe.NewError(errors.New("")), fmt.Sprintf("Unknown flag `%s` passed.", arg))
 		}
 	}
+	if ctx.IsSet("length") && ctx.Int64("length") < 0 {
+		fatalIf(errInvalidArgument().Trace(ctx.String("length")), "Length cannot be negative.")
+	}
+	if ctx.Int64("offset") < 0 {
+		if ctx.IsSet("length") {
+			fatalIf(errInvalidArgument().Trace(ctx.String("offset")), "Length cannot be used with a negative offset.")
+		}
+		if ctx.Bool("decompress") {
+			fatalIf(errInvalidArgument().Trace(ctx.String("offset")), "Negative offset cannot be used with --decompress.")
+		}
+		for _, arg := range args {
+			if arg == "-" {
+				fatalIf(errInvalidArgument().Trace(ctx.String("offset")), "Negative offset cannot be used with standard input.")
+			}
+		}
+	}
+}
+
+// catRange - byte range of the sources to display.
+type catRange struct {
+	offset int64
+	// length is negative to read till the end.
+	length int64
+}
+
+// newCatRange - returns the byte range from the command line flags.
+func newCatRange(ctx *cli.Context) catRange {
+	r := catRange{offset: ctx.Int64("offset"), length: -1}
+	if ctx.IsSet("length") {
+		r.length = ctx.Int64("length")
+	}
+	return r
+}
+
+// size - returns the number of bytes in the range of an object of size bytes.
+func (r catRange) size(size int64) int64 {
+	if r.offset < 0 {
+		if -r.offset < size {
+			return -r.offset
+		}
+		return size
+	}
+	if size -= r.offset; size < 0 {
+		size = 0
+	}
+	if r.length >= 0 && r.length < size {
+		return r.length
+	}
+	return size
+}
+
+// limit - returns the range of a stream, negative offsets are not
+// supported since the size of the stream is unknown.
+func (r catRange) limit(reader io.Reader) (io.Reader, error) {
+	if r.offset > 0 {
+		if _, e := io.CopyN(ioutil.Discard, reader, r.offset); e != nil && e != io.EOF {
+			return nil, e
+		}
+	}
+	if r.length >= 0 {
+		reader = io.LimitReader(reader, r.length)
+	}
+	return reader, nil
 }
 
 // catURL displays contents of a URL to stdout.
-func catURL(sourceURL string, encKeyDB map[string][]prefixSSEPair) *probe.Error {
-	var reader io.ReadCloser
+// The range of decompressed objects is read from their decompressed content.
+func catURL(sourceURL string, byteRange catRange, decompress bool, encKeyDB map[string][]prefixSSEPair) *probe.Error {
+	var reader io.Reader
 	size := int64(-1)
-	switch sourceURL {
-	case "-":
+	switch {
+	case sourceURL == "-":
 		reader = os.Stdin
+		if decompress {
+			rc, err := newDecompressReader(os.Stdin, "", nil)
+			if err != nil {
+				return err.Trace(sourceURL)
+			}
+			reader = rc
+		}
+		var e error
+		if reader, e = byteRange.limit(reader); e != nil {
+			return probe.NewError(e).Trace(sourceURL)
+		}
+	case decompress:
+		var metadata map[string]string
+		if _, content, err := url2Stat(sourceURL, false, false, encKeyDB); err == nil {
+			metadata = content.Metadata
+		}
+		rc, err := getSourceStreamFromURL(sourceURL, encKeyDB)
+		if err != nil {
+			return err.Trace(sourceURL)
+		}
+		drc, err := newDecompressReader(rc, sourceURL, metadata)
+		if err != nil {
+			rc.Close()
+			return err.Trace(sourceURL)
+		}
+		defer drc.Close()
+		var e error
+		if reader, e = byteRange.limit(drc); e != nil {
+			return probe.NewError(e).Trace(sourceURL)
+		}
 	default:
 		var err *probe.Error
 		// Try to stat the object, the purpose is to extract the
@@ -154,12 +269,18 @@ func catURL(sourceURL string, encKeyDB map[string][]prefixSSEPair) *probe.Error
 		// have contents like files under /proc.
 		client, content, err := url2Stat(sourceURL, false, false, encKeyDB)
 		if err == nil && client.GetURL().Type == objectStorage {
-			size = content.Size
+			size = byteRange.size(content.Size)
+		}
+		// An empty range cannot be requested, there is nothing to display.
+		if size == 0 {
+			return nil
 		}
-		if reader, err = getSourceStreamFromURL(sourceURL, encKeyDB); err != nil {
+		var rc io.ReadCloser
+		if rc, err = getSourceStreamRangeFromURL(sourceURL, byteRange.offset, byteRange.length, encKeyDB); err != nil {
 			return err.Trace(sourceURL)
 		}
-		defer reader.Close()
+		defer rc.Close()
+		reader = rc
 	}
 	return catOut(reader, size).Trace(sourceURL)
 }
@@ -226,7 +347,7 @@ func mainCat(ctx *cli.Context) error {
 
 	// handle std input data.
 	if stdinMode {
-		fatalIf(catOut(os.Stdin, -1).Trace(), "Unable to read from standard input.")
+		fatalIf(catURL("-", newCatRange(ctx), ctx.Bool("decompress"), encKeyDB).Trace(), "Unable to read from standard input.")
 		return nil
 	}
 
@@ -244,7 +365,7 @@ func mainCat(ctx *cli.Context) error {
 
 	// Convert arguments to URLs: expand alias, fix format.
 	for _, url := range args {
-		fatalIf(catURL(url, encKeyDB).Trace(url), "Unable to read from `"+url+"`.")
+		fatalIf(catURL(url, newCatRange(ctx), ctx.Bool("decompress"), encKeyDB).Trace(url), "Unable to read from `"+url+"`.")
 	}
 
 	return nil
@@ -18,9 +18,15 @@ package cmd
 
 import (
 	"bytes"
+	"fmt"
 	"io"
 	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
 	"testing"
+
+	"github.com/minio/mc/pkg/probe"
 )
 
 func TestPrettyStdout(t *testing.T) {
@@ -62,3 +68,67 @@ func TestPrettyStdout(t *testing.T) {
 		}
 	}
 }
+
+// Tests that no object is requested when the range to display is empty.
+func TestCatURLEmptyRange(t *testing.T) {
+	var size int64
+	gets := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := r.URL.Query()["location"]; ok {
+			w.Write([]byte("<LocationConstraint xmlns=\"http://doc.s3.amazonaws.com/2006-03-01\"></LocationConstraint>"))
+			return
+		}
+		if r.URL.Path == "/bucket/" {
+			fmt.Fprintf(w, "<ListBucketResult xmlns=\"http://s3.amazonaws.com/doc/2006-03-01/\"><Name>bucket</Name><Contents><Key>object</Key><Size>%d</Size><ETag>\"etag\"</ETag><LastModified>2019-11-05T18:24:21.097Z</LastModified><StorageClass>STANDARD</StorageClass></Contents><IsTruncated>false</IsTruncated></ListBucketResult>", size)
+			return
+		}
+		if r.URL.Path != "/bucket/object" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("ETag", "\"d41d8cd98f00b204e9800998ecf8427e\"")
+		w.Header().Set("Last-Modified", "Tue, 05 Nov 2019 18:24:21 GMT")
+		if r.Method == http.MethodGet {
+			gets++
+			if r.Header.Get("Range") != "" {
+				w.WriteHeader(http.StatusRequestedRangeNotSatisfiable)
+				return
+			}
+		}
+		w.Header().Set("Content-Length", strconv.FormatInt(size, 10))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	defer func(load func() (*configV9, *probe.Error)) { loadMcConfig = load }(loadMcConfig)
+	loadMcConfig = func() (*configV9, *probe.Error) {
+		config := newMcConfig()
+		config.Hosts["fake"] = hostConfigV9{
+			URL:       server.URL,
+			AccessKey: "WLGDGYAQYIGI833EV05A",
+			SecretKey: "BYvgJM101sHngl2uzjXS/OBF/aMxAN06JrJ3qJlF",
+			API:       "S3v4",
+			Lookup:    "path",
+		}
+		return config, nil
+	}
+
+	testCases := []struct {
+		size      int64
+		byteRange catRange
+	}{
+		{5, catRange{offset: 5, length: -1}},
+		{5, catRange{offset: 10, length: 2}},
+		{5, catRange{offset: 1, length: 0}},
+		{0, catRange{offset: -3, length: -1}},
+	}
+	for i, testCase := range testCases {
+		size, gets = testCase.size, 0
+		if err := catURL("fake/bucket/object", testCase.byteRange, false, nil); err != nil {
+			t.Errorf("Test %d: expected no error, got %v", i+1, err)
+		}
+		if gets != 0 {
+			t.Errorf("Test %d: expected no object request, got %d", i+1, gets)
+		}
+	}
+}
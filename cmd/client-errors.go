@@ -153,6 +153,15 @@ func (e ObjectMissing) Error() string {
 	return "Object does not exist"
 }
 
+// ObjectLocked - object is protected by retention or legal hold.
+type ObjectLocked struct {
+	Object string
+}
+
+func (e ObjectLocked) Error() string {
+	return "Object `" + e.Object + "` is locked by retention or legal hold."
+}
+
 // UnexpectedShortWrite - write wrote less bytes than expected.
 type UnexpectedShortWrite struct {
 	InputSize int
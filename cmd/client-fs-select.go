@@ -0,0 +1,128 @@
+/*
+ * MinIO Client (C) 2019 MinIO, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"os"
+
+	"github.com/minio/mc/pkg/probe"
+	minio "github.com/minio/minio-go/v6"
+	"github.com/minio/minio-go/v6/pkg/encrypt"
+	"github.com/minio/minio/pkg/s3select"
+)
+
+// selectResponseWriter - http.ResponseWriter streaming the event
+// messages of a query evaluated locally.
+type selectResponseWriter struct {
+	io.Writer
+	header http.Header
+}
+
+func (w selectResponseWriter) Header() http.Header        { return w.header }
+func (w selectResponseWriter) WriteHeader(statusCode int) {}
+func (w selectResponseWriter) Flush()                     {}
+
+// selectResults - query results which also stop the evaluation when
+// closed before all the results are read.
+type selectResults struct {
+	*minio.SelectResults
+	pipeReader *io.PipeReader
+}
+
+// Close fails the pending and next writes of the evaluation, which
+// then exits, before closing the results.
+func (r selectResults) Close() error {
+	r.pipeReader.CloseWithError(io.ErrClosedPipe)
+	return r.SelectResults.Close()
+}
+
+// Select replies a stream of query results, the query is evaluated
+// locally with the same engine and serialization options as an S3
+// Select request on a MinIO server.
+func (f *fsClient) Select(expression string, sse encrypt.ServerSide, selOpts SelectObjectOpts) (io.ReadCloser, *probe.Error) {
+	fpath := f.PathURL.Path
+	opts := minio.SelectObjectOptions{
+		Expression:     expression,
+		ExpressionType: minio.QueryExpressionTypeSQL,
+	}
+	opts.InputSerialization = selectObjectInputOpts(selOpts, fpath)
+	opts.OutputSerialization = selectObjectOutputOpts(selOpts, opts.InputSerialization)
+
+	request, e := xml.Marshal(opts)
+	if e != nil {
+		return nil, probe.NewError(e)
+	}
+	s3Select, e := s3select.NewS3Select(bytes.NewReader(request))
+	if e != nil {
+		return nil, probe.NewError(e)
+	}
+
+	st, err := f.fsStat(false)
+	if err != nil {
+		return nil, err.Trace(fpath)
+	}
+	if st.IsDir() {
+		return nil, probe.NewError(PathIsNotRegular{Path: fpath})
+	}
+	getReader := func(offset, length int64) (io.ReadCloser, error) {
+		file, e := os.Open(fpath)
+		if e != nil {
+			return nil, e
+		}
+		if offset < 0 {
+			// A negative offset is relative to the end of the file.
+			offset += st.Size()
+		}
+		if _, e = file.Seek(offset, io.SeekStart); e != nil {
+			file.Close()
+			return nil, e
+		}
+		if length < 0 {
+			return file, nil
+		}
+		return struct {
+			io.Reader
+			io.Closer
+		}{io.LimitReader(file, length), file}, nil
+	}
+	if e = s3Select.Open(getReader); e != nil {
+		return nil, probe.NewError(e)
+	}
+
+	// Records are sent as event stream messages, which are decoded
+	// exactly like a response of a server. The evaluation stops on
+	// the first failed write, once the results are closed.
+	pipeReader, pipeWriter := io.Pipe()
+	go func() {
+		defer s3Select.Close()
+		s3Select.Evaluate(selectResponseWriter{Writer: pipeWriter, header: make(http.Header)})
+		pipeWriter.Close()
+	}()
+	results, e := minio.NewSelectResults(&http.Response{
+		StatusCode: http.StatusOK,
+		Body:       pipeReader,
+	}, "")
+	if e != nil {
+		pipeReader.CloseWithError(e)
+		return nil, probe.NewError(e)
+	}
+	return selectResults{SelectResults: results, pipeReader: pipeReader}, nil
+}
@@ -115,11 +115,6 @@ func (f *fsClient) GetURL() clientURL {
 	return *f.PathURL
 }
 
-// Select replies a stream of query results.
-func (f *fsClient) Select(expression string, sse encrypt.ServerSide, opts SelectObjectOpts) (io.ReadCloser, *probe.Error) {
-	return nil, probe.NewError(APINotImplemented{})
-}
-
 // Watches for all fs events on an input path.
 func (f *fsClient) Watch(params watchParams) (*watchObject, *probe.Error) {
 	eventChan := make(chan EventInfo)
@@ -462,7 +457,7 @@ func (f *fsClient) Copy(source string, size int64, progress io.Reader, srcSSE, t
 }
 
 // get - get wrapper returning object reader.
-func (f *fsClient) get() (io.ReadCloser, *probe.Error) {
+func (f *fsClient) get(offset, length int64) (io.ReadCloser, *probe.Error) {
 	tmppath := f.PathURL.Path
 	// Golang strips trailing / if you clean(..) or
 	// EvalSymlinks(..). Adding '.' prevents it from doing so.
@@ -481,12 +476,31 @@ func (f *fsClient) get() (io.ReadCloser, *probe.Error) {
 		err := f.toClientError(e, f.PathURL.Path)
 		return nil, err.Trace(f.PathURL.Path)
 	}
-	return fileData, nil
+	if offset < 0 {
+		// Read the last -offset bytes, or the whole file if it is smaller.
+		if offset, e = fileData.Seek(offset, io.SeekEnd); e != nil {
+			offset, e = fileData.Seek(0, io.SeekStart)
+		}
+	} else if offset > 0 {
+		_, e = fileData.Seek(offset, io.SeekStart)
+	}
+	if e != nil {
+		fileData.Close()
+		err := f.toClientError(e, f.PathURL.Path)
+		return nil, err.Trace(f.PathURL.Path)
+	}
+	if length < 0 {
+		return fileData, nil
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{io.LimitReader(fileData, length), fileData}, nil
 }
 
 // Get returns reader and any additional metadata.
-func (f *fsClient) Get(sse encrypt.ServerSide) (io.ReadCloser, *probe.Error) {
-	return f.get()
+func (f *fsClient) Get(offset, length int64, sse encrypt.ServerSide) (io.ReadCloser, *probe.Error) {
+	return f.get(offset, length)
 }
 
 // Check if the given error corresponds to ENOTEMPTY for unix
@@ -534,7 +548,7 @@ func deleteFile(deletePath string) error {
 }
 
 // Remove - remove entry read from clientContent channel.
-func (f *fsClient) Remove(isIncomplete, isRemoveBucket bool, contentCh <-chan *clientContent) <-chan *probe.Error {
+func (f *fsClient) Remove(isIncomplete, isRemoveBucket, isBypass bool, contentCh <-chan *clientContent) <-chan *probe.Error {
 	errorCh := make(chan *probe.Error)
 
 	// Goroutine reads from contentCh and removes the entry in content.
@@ -565,6 +579,40 @@ func (f *fsClient) Remove(isIncomplete, isRemoveBucket bool, contentCh <-chan *c
 	return errorCh
 }
 
+// ListFrom - list files and folders after the start key, which is the
+// path of a file. Like object keys, paths are listed in lexical order
+// with folders sorted by their name followed by a separator.
+func (f *fsClient) ListFrom(startAfter string, isRecursive, isIncomplete, isMetadata bool, showDir DirOpt) <-chan *clientContent {
+	contentCh := f.List(isRecursive, isIncomplete, isMetadata, showDir)
+	if startAfter == "" {
+		return contentCh
+	}
+
+	separator := string(f.PathURL.Separator)
+	afterCh := make(chan *clientContent)
+	go func() {
+		defer close(afterCh)
+		for c := range contentCh {
+			if c.Err == nil {
+				key := c.URL.Path
+				if c.Type.IsDir() {
+					// Folders holding the start key are still listed.
+					key = strings.TrimSuffix(key, separator) + separator
+					if strings.HasPrefix(startAfter, key) {
+						afterCh <- c
+						continue
+					}
+				}
+				if key <= startAfter {
+					continue
+				}
+			}
+			afterCh <- c
+		}
+	}()
+	return afterCh
+}
+
 // List - list files and folders.
 func (f *fsClient) List(isRecursive, isIncomplete, isMetadata bool, showDir DirOpt) <-chan *clientContent {
 	contentCh := make(chan *clientContent)
@@ -1031,11 +1079,31 @@ func (f *fsClient) GetAccessRules() (map[string]string, *probe.Error) {
 	})
 }
 
+// PutObjectLegalHold - unsupported API
+func (f *fsClient) PutObjectLegalHold(status legalHoldStatus) *probe.Error {
+	return probe.NewError(APINotImplemented{API: "PutObjectLegalHold", APIType: "filesystem"})
+}
+
+// GetObjectLegalHold - unsupported API
+func (f *fsClient) GetObjectLegalHold() (legalHoldStatus, *probe.Error) {
+	return "", probe.NewError(APINotImplemented{API: "GetObjectLegalHold", APIType: "filesystem"})
+}
+
+// GetTags - unsupported API
+func (f *fsClient) GetTags() (map[string]string, *probe.Error) {
+	return nil, probe.NewError(APINotImplemented{API: "GetObjectTagging", APIType: "filesystem"})
+}
+
 // Set object retention for a given object.
-func (f *fsClient) PutObjectRetention(mode *minio.RetentionMode, retainUntilDate *time.Time) *probe.Error {
+func (f *fsClient) PutObjectRetention(versionID string, mode *minio.RetentionMode, retainUntilDate *time.Time, bypassGovernance bool) *probe.Error {
 	return probe.NewError(APINotImplemented{API: "PutObjectRetention", APIType: "filesystem"})
 }
 
+// GetObjectRetention - unsupported API
+func (f *fsClient) GetObjectRetention(versionID string) (*minio.RetentionMode, *time.Time, *probe.Error) {
+	return nil, nil, probe.NewError(APINotImplemented{API: "GetObjectRetention", APIType: "filesystem"})
+}
+
 // GetAccess - get access policy permissions.
 func (f *fsClient) GetAccess() (access string, policyJSON string, err *probe.Error) {
 	// For windows this feature is not implemented.
@@ -18,16 +18,50 @@ package cmd
 
 import (
 	"bytes"
+	"compress/gzip"
 	"context"
 	"io"
 	"io/ioutil"
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
+	"time"
 
 	. "gopkg.in/check.v1"
 )
 
+// Test list files after a start key.
+func (s *TestSuite) TestListFrom(c *C) {
+	root, e := ioutil.TempDir(os.TempDir(), "fs-")
+	c.Assert(e, IsNil)
+	defer os.RemoveAll(root)
+
+	for _, name := range []string{"a-b/1", "a/1", "a/2", "b", "c/d/1"} {
+		objectPath := filepath.Join(root, filepath.FromSlash(name))
+		c.Assert(os.MkdirAll(filepath.Dir(objectPath), 0700), IsNil)
+		c.Assert(ioutil.WriteFile(objectPath, []byte("hello"), 0600), IsNil)
+	}
+
+	fsClient, err := fsNew(root)
+	c.Assert(err, IsNil)
+
+	var names []string
+	for content := range fsClient.ListFrom(filepath.Join(root, "a", "1"), true, false, false, DirNone) {
+		c.Assert(content.Err, IsNil)
+		names = append(names, filepath.ToSlash(strings.TrimPrefix(content.URL.Path, root)))
+	}
+	c.Assert(names, DeepEquals, []string{"/a/2", "/b", "/c/d/1"})
+
+	// Folders holding the start key are listed as well.
+	names = nil
+	for content := range fsClient.ListFrom(filepath.Join(root, "c", "d", "0"), true, false, false, DirFirst) {
+		c.Assert(content.Err, IsNil)
+		names = append(names, filepath.ToSlash(strings.TrimPrefix(content.URL.Path, root)))
+	}
+	c.Assert(names, DeepEquals, []string{"", "/c", "/c/d", "/c/d/1"})
+}
+
 // Test list files in a folder.
 func (s *TestSuite) TestList(c *C) {
 	root, e := ioutil.TempDir(os.TempDir(), "fs-")
@@ -275,7 +309,7 @@ func (s *TestSuite) TestGet(c *C) {
 	c.Assert(err, IsNil)
 	c.Assert(n, Equals, int64(len(data)))
 
-	reader, err = fsClient.Get(nil)
+	reader, err = fsClient.Get(0, -1, nil)
 	c.Assert(err, IsNil)
 	var results bytes.Buffer
 	_, e = io.Copy(&results, reader)
@@ -303,7 +337,7 @@ func (s *TestSuite) TestGetRange(c *C) {
 	c.Assert(err, IsNil)
 	c.Assert(n, Equals, int64(len(data)))
 
-	reader, err = fsClient.Get(nil)
+	reader, err = fsClient.Get(0, -1, nil)
 	c.Assert(err, IsNil)
 	var results bytes.Buffer
 	buf := make([]byte, 5)
@@ -313,6 +347,24 @@ func (s *TestSuite) TestGetRange(c *C) {
 	_, e = results.Write(buf)
 	c.Assert(e, IsNil)
 	c.Assert([]byte("hello"), DeepEquals, results.Bytes())
+
+	for _, testCase := range []struct {
+		offset, length int64
+		data           string
+	}{
+		{6, -1, "world"},
+		{2, 3, "llo"},
+		{-5, -1, "world"},
+		{-20, -1, "hello world"},
+		{8, 10, "rld"},
+		{20, -1, ""},
+	} {
+		reader, err = fsClient.Get(testCase.offset, testCase.length, nil)
+		c.Assert(err, IsNil)
+		data, e := ioutil.ReadAll(reader)
+		c.Assert(e, IsNil)
+		c.Assert(string(data), Equals, testCase.data)
+	}
 }
 
 // Test stat file.
@@ -363,3 +415,84 @@ func (s *TestSuite) TestCopy(c *C) {
 	err = fsClientTarget.Copy(sourcePath, int64(len(data)), nil, nil, nil, nil)
 	c.Assert(err, IsNil)
 }
+
+// Test querying csv and compressed json files.
+func (s *TestSuite) TestSelect(c *C) {
+	root, e := ioutil.TempDir(os.TempDir(), "fs-")
+	c.Assert(e, IsNil)
+	defer os.RemoveAll(root)
+
+	csvPath := filepath.Join(root, "people.csv")
+	c.Assert(ioutil.WriteFile(csvPath, []byte("name,age\nann,34\nbob,27\ncid,45\n"), 0600), IsNil)
+
+	var jsonData bytes.Buffer
+	gw := gzip.NewWriter(&jsonData)
+	_, e = gw.Write([]byte("{\"name\":\"ann\",\"age\":34}\n{\"name\":\"bob\",\"age\":27}\n"))
+	c.Assert(e, IsNil)
+	c.Assert(gw.Close(), IsNil)
+	jsonPath := filepath.Join(root, "people.json.gz")
+	c.Assert(ioutil.WriteFile(jsonPath, jsonData.Bytes(), 0600), IsNil)
+
+	query := func(path, expression string, opts SelectObjectOpts) string {
+		fsClient, err := fsNew(path)
+		c.Assert(err, IsNil)
+		reader, err := fsClient.Select(expression, nil, opts)
+		c.Assert(err, IsNil)
+		defer reader.Close()
+		data, e := ioutil.ReadAll(reader)
+		c.Assert(e, IsNil)
+		return string(data)
+	}
+
+	c.Assert(query(csvPath, "select s.name from S3Object s where cast(s.age as int) > 30 limit 1", SelectObjectOpts{}),
+		Equals, "ann\n")
+	c.Assert(query(csvPath, "select count(*), sum(cast(s.age as int)) from S3Object s", SelectObjectOpts{}),
+		Equals, "3,106\n")
+	c.Assert(query(csvPath, "select s._2 from S3Object s", SelectObjectOpts{
+		InputSerOpts: map[string]map[string]string{"csv": {fileHeaderType: "NONE"}},
+	}), Equals, "age\n34\n27\n45\n")
+	c.Assert(query(jsonPath, "select s.name from S3Object s where s.age < 30", SelectObjectOpts{}),
+		Equals, "{\"name\":\"bob\"}\n")
+
+	fsClient, err := fsNew(csvPath)
+	c.Assert(err, IsNil)
+	_, err = fsClient.Select("selec * from S3Object", nil, SelectObjectOpts{})
+	c.Assert(err, NotNil)
+}
+
+// Test closing query results before reading all of them.
+func (s *TestSuite) TestSelectClose(c *C) {
+	root, e := ioutil.TempDir(os.TempDir(), "fs-")
+	c.Assert(e, IsNil)
+	defer os.RemoveAll(root)
+
+	var data bytes.Buffer
+	data.WriteString("name,age\n")
+	for i := 0; i < 1000000; i++ {
+		data.WriteString("ann,34\n")
+	}
+	csvPath := filepath.Join(root, "people.csv")
+	c.Assert(ioutil.WriteFile(csvPath, data.Bytes(), 0600), IsNil)
+
+	query := func(readAll bool) time.Duration {
+		fsClient, err := fsNew(csvPath)
+		c.Assert(err, IsNil)
+		reader, err := fsClient.Select("select * from S3Object", nil, SelectObjectOpts{})
+		c.Assert(err, IsNil)
+		start := time.Now()
+		if readAll {
+			_, e = io.Copy(ioutil.Discard, reader)
+		} else {
+			_, e = io.ReadFull(reader, make([]byte, 10))
+		}
+		c.Assert(e, IsNil)
+		c.Assert(reader.Close(), IsNil)
+		return time.Since(start)
+	}
+
+	// The evaluation stops on its next write instead of running
+	// through the whole file.
+	full := query(true)
+	closed := query(false)
+	c.Assert(closed < full/4, Equals, true)
+}
@@ -0,0 +1,272 @@
+/*
+ * MinIO Client (C) 2019 MinIO, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"strings"
+
+	minio "github.com/minio/minio-go/v6"
+)
+
+// listShardMaxDepth - maximum number of prefix levels expanded while
+// looking for enough prefixes to list in parallel.
+const listShardMaxDepth = 3
+
+// listShardMaxObjects - maximum number of objects listed directly
+// below a prefix while expanding it, a prefix with more objects has
+// too few prefixes to be worth expanding and is listed as a whole.
+const listShardMaxObjects = 1000
+
+// listShard is a lexical range of a recursive listing, it is either
+// a prefix whose objects are all listed recursively or the level of
+// a prefix whose objects directly below it are listed.
+type listShard struct {
+	key   string
+	level bool
+}
+
+// expandListShard - lists the objects and the common prefixes
+// directly below prefix, returns the level of prefix followed by its
+// common prefixes. Returns false if prefix has too many objects
+// directly below it to be expanded.
+func (c *s3Client) expandListShard(bucket, prefix string) ([]listShard, bool, error) {
+	delimiter := string(c.targetURL.Separator)
+	doneCh := make(chan struct{})
+	defer close(doneCh)
+
+	var shards []listShard
+	objects := 0
+	isRecursive := false
+	for object := range c.listObjectWrapper(bucket, prefix, isRecursive, doneCh, false, "") {
+		if object.Err != nil {
+			return nil, false, object.Err
+		}
+		// Only common prefixes end with the delimiter, except for a
+		// folder object named after the listed prefix.
+		if strings.HasSuffix(object.Key, delimiter) && object.Key != prefix {
+			shards = append(shards, listShard{key: object.Key})
+			continue
+		}
+		if objects++; objects > listShardMaxObjects {
+			return nil, false, nil
+		}
+	}
+	if objects > 0 {
+		shards = append([]listShard{{key: prefix, level: true}}, shards...)
+	}
+	return shards, true, nil
+}
+
+// discoverListShards - discovers the common prefixes below prefix,
+// level by level, until there are at least as many prefixes as
+// workers. Discovery stops early once a level has too few prefixes.
+// The prefixes are returned in lexical order.
+func (c *s3Client) discoverListShards(bucket, prefix string, workers int) ([]listShard, error) {
+	shards := []listShard{{key: prefix}}
+	for depth := 0; depth < listShardMaxDepth; depth++ {
+		prefixes := 0
+		for _, shard := range shards {
+			if !shard.level {
+				prefixes++
+			}
+		}
+		if prefixes == 0 || prefixes >= workers {
+			break
+		}
+
+		// Shards are expanded in place, common prefixes are listed
+		// in lexical order.
+		var expanded []listShard
+		isExpanded := false
+		for _, shard := range shards {
+			if shard.level {
+				expanded = append(expanded, shard)
+				continue
+			}
+			children, ok, e := c.expandListShard(bucket, shard.key)
+			if e != nil {
+				return nil, e
+			}
+			if !ok {
+				expanded = append(expanded, shard)
+				continue
+			}
+			isExpanded = true
+			expanded = append(expanded, children...)
+		}
+		shards = expanded
+		if !isExpanded {
+			break
+		}
+	}
+	return shards, nil
+}
+
+// listShardHead is the next object of a listing.
+type listShardHead struct {
+	objectCh  <-chan minio.ObjectInfo
+	object    minio.ObjectInfo
+	ok        bool
+	isStarted bool
+}
+
+func (h *listShardHead) next() {
+	h.object, h.ok = <-h.objectCh
+	h.isStarted = true
+}
+
+// listObjectsParallel - lists recursively the objects below prefix
+// with up to workers concurrent listings, one per discovered prefix.
+// Objects are sent in the same lexical order as a sequential listing.
+func (c *s3Client) listObjectsParallel(bucket, prefix string, workers int, doneCh chan struct{}, metadata bool, startAfter string) <-chan minio.ObjectInfo {
+	objectCh := make(chan minio.ObjectInfo, 1)
+
+	go func() {
+		defer close(objectCh)
+
+		shards, e := c.discoverListShards(bucket, prefix, workers)
+		if e != nil {
+			objectCh <- minio.ObjectInfo{Err: e}
+			return
+		}
+
+		// Stops the shard listings when returning early.
+		shardDoneCh := make(chan struct{})
+		defer close(shardDoneCh)
+
+		// listShardObjects - lists a shard from the start key, if it
+		// is in the shard. The common prefixes of a level are skipped.
+		delimiter := string(c.targetURL.Separator)
+		listShardObjects := func(shard listShard, shardCh chan minio.ObjectInfo) {
+			defer close(shardCh)
+			shardStartAfter := ""
+			if strings.HasPrefix(startAfter, shard.key) {
+				shardStartAfter = startAfter
+			}
+			isRecursive := !shard.level
+			for object := range c.listObjectWrapper(bucket, shard.key, isRecursive, shardDoneCh, metadata, shardStartAfter) {
+				if shard.level && strings.HasSuffix(object.Key, delimiter) && object.Key != shard.key {
+					continue
+				}
+				select {
+				case shardCh <- object:
+				case <-shardDoneCh:
+					return
+				}
+			}
+		}
+
+		// Skip the shards entirely before the start key. Levels are
+		// all listed at once, their objects are merged with the
+		// objects of the prefixes in lexical order.
+		var prefixes []listShard
+		var levels []*listShardHead
+		for _, shard := range shards {
+			if shard.key <= startAfter && !strings.HasPrefix(startAfter, shard.key) {
+				continue
+			}
+			if !shard.level {
+				prefixes = append(prefixes, shard)
+				continue
+			}
+			levelCh := make(chan minio.ObjectInfo, 1000)
+			go listShardObjects(shard, levelCh)
+			levels = append(levels, &listShardHead{objectCh: levelCh})
+		}
+
+		// sendObject - sends an object, returns false once listing
+		// should stop.
+		sendObject := func(object minio.ObjectInfo) bool {
+			select {
+			case objectCh <- object:
+			case <-doneCh:
+				return false
+			}
+			return object.Err == nil
+		}
+
+		// sendLevels - sends the objects of the levels before key, or
+		// all of them if key is empty.
+		sendLevels := func(key string) bool {
+			for {
+				var first *listShardHead
+				for _, head := range levels {
+					if !head.isStarted {
+						head.next()
+					}
+					if head.ok && (first == nil || head.object.Key < first.object.Key) {
+						first = head
+					}
+				}
+				if first == nil || (key != "" && first.object.Key >= key) {
+					return true
+				}
+				if !sendObject(first.object) {
+					return false
+				}
+				first.next()
+			}
+		}
+
+		// Each prefix is listed into its own buffered channel, at most
+		// workers prefixes are listed at once and are started in order.
+		// The channel of a prefix is only created once it is started
+		// and is queued for the channels to be drained in order as well.
+		type prefixListing struct {
+			key      string
+			objectCh chan minio.ObjectInfo
+		}
+		prefixChs := make(chan prefixListing, workers)
+		go func() {
+			defer close(prefixChs)
+			workerCh := make(chan struct{}, workers)
+			for _, shard := range prefixes {
+				select {
+				case workerCh <- struct{}{}:
+				case <-shardDoneCh:
+					return
+				}
+				prefixCh := make(chan minio.ObjectInfo, 1000)
+				go func(shard listShard) {
+					defer func() { <-workerCh }()
+					listShardObjects(shard, prefixCh)
+				}(shard)
+				select {
+				case prefixChs <- prefixListing{key: shard.key, objectCh: prefixCh}:
+				case <-shardDoneCh:
+					return
+				}
+			}
+		}()
+
+		// A prefix is only empty when listing all the objects of a
+		// bucket without any level.
+		for listing := range prefixChs {
+			if !sendLevels(listing.key) {
+				return
+			}
+			for object := range listing.objectCh {
+				if !sendObject(object) {
+					return
+				}
+			}
+		}
+		sendLevels("")
+	}()
+
+	return objectCh
+}
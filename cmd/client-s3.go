@@ -19,11 +19,17 @@ package cmd
 import (
 	"bytes"
 	"context"
+	"crypto/md5"
+	"crypto/sha256"
 	"crypto/tls"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
+	"encoding/xml"
 	"errors"
 	"hash/fnv"
 	"io"
+	"io/ioutil"
 	"net"
 	"net/http"
 	"net/url"
@@ -41,6 +47,7 @@ import (
 	"github.com/minio/minio-go/v6/pkg/credentials"
 	"github.com/minio/minio-go/v6/pkg/encrypt"
 	"github.com/minio/minio-go/v6/pkg/policy"
+	"github.com/minio/minio-go/v6/pkg/s3signer"
 	"github.com/minio/minio-go/v6/pkg/s3utils"
 	"github.com/minio/minio/pkg/mimedb"
 )
@@ -50,6 +57,8 @@ type s3Client struct {
 	mutex        *sync.Mutex
 	targetURL    *clientURL
 	api          *minio.Client
+	httpClient   *http.Client
+	creds        *credentials.Credentials
 	virtualStyle bool
 }
 
@@ -61,6 +70,10 @@ const (
 
 	defaultRecordDelimiter = "\n"
 	defaultFieldDelimiter  = ","
+
+	// Number of objects removed concurrently when governance
+	// retention is bypassed, which removes objects one by one.
+	removeBypassWorkers = 16
 )
 
 const (
@@ -76,6 +89,8 @@ const (
 	AmzObjectLockMode = "X-Amz-Object-Lock-Mode"
 	// AmzObjectLockRetainUntilDate sets object lock retain until date
 	AmzObjectLockRetainUntilDate = "X-Amz-Object-Lock-Retain-Until-Date"
+	// AmzObjectLockLegalHold sets object lock legal hold status
+	AmzObjectLockLegalHold = "X-Amz-Object-Lock-Legal-Hold"
 )
 
 // cseHeaders is list of client side encryption headers
@@ -90,6 +105,7 @@ var timeSentinel = time.Unix(0, 0).UTC()
 // newFactory encloses New function with client cache.
 func newFactory() func(config *Config) (Client, *probe.Error) {
 	clientCache := make(map[uint32]*minio.Client)
+	transportCache := make(map[uint32]http.RoundTripper)
 	mutex := &sync.Mutex{}
 
 	// Return New function.
@@ -125,18 +141,19 @@ func newFactory() func(config *Config) (Client, *probe.Error) {
 		confHash.Write([]byte(hostName + config.AccessKey + config.SecretKey))
 		confSum := confHash.Sum32()
 
+		// if Signature version '4' use NewV4 directly.
+		creds := credentials.NewStaticV4(config.AccessKey, config.SecretKey, "")
+		// if Signature version '2' use NewV2 directly.
+		if strings.ToUpper(config.Signature) == "S3V2" {
+			creds = credentials.NewStaticV2(config.AccessKey, config.SecretKey, "")
+		}
+
 		// Lookup previous cache by hash.
 		mutex.Lock()
 		defer mutex.Unlock()
 		var api *minio.Client
 		var found bool
 		if api, found = clientCache[confSum]; !found {
-			// if Signature version '4' use NewV4 directly.
-			creds := credentials.NewStaticV4(config.AccessKey, config.SecretKey, "")
-			// if Signature version '2' use NewV2 directly.
-			if strings.ToUpper(config.Signature) == "S3V2" {
-				creds = credentials.NewStaticV2(config.AccessKey, config.SecretKey, "")
-			}
 			// Not found. Instantiate a new MinIO
 			var e error
 
@@ -218,10 +235,15 @@ func newFactory() func(config *Config) (Client, *probe.Error) {
 
 			// Cache the new MinIO Client with hash of config as key.
 			clientCache[confSum] = api
+			transportCache[confSum] = transport
 		}
 
 		// Store the new api object.
 		s3Clnt.api = api
+		// Requests not implemented by minio-go share its transport
+		// and credentials.
+		s3Clnt.httpClient = &http.Client{Transport: transportCache[confSum]}
+		s3Clnt.creds = creds
 
 		return s3Clnt, nil
 	}
@@ -730,17 +752,49 @@ func (c *s3Client) Watch(params watchParams) (*watchObject, *probe.Error) {
 
 	go func() {
 		wg.Wait()
+		close(wo.eventInfoChan)
+		close(wo.errorChan)
 		wo.Close()
 	}()
 
 	return wo, nil
 }
 
+// rangeReader - reads a range of an object, a range starting past the
+// end of the object reads nothing like a file read past its end.
+type rangeReader struct {
+	*minio.Object
+}
+
+func (r rangeReader) Read(p []byte) (int, error) {
+	n, e := r.Object.Read(p)
+	if e != nil && minio.ToErrorResponse(e).StatusCode == http.StatusRequestedRangeNotSatisfiable {
+		return n, io.EOF
+	}
+	return n, e
+}
+
 // Get - get object with metadata.
-func (c *s3Client) Get(sse encrypt.ServerSide) (io.ReadCloser, *probe.Error) {
+func (c *s3Client) Get(offset, length int64, sse encrypt.ServerSide) (io.ReadCloser, *probe.Error) {
 	bucket, object := c.url2BucketAndObject()
 	opts := minio.GetObjectOptions{}
 	opts.ServerSideEncryption = sse
+	if length == 0 {
+		// An empty range cannot be requested.
+		return ioutil.NopCloser(bytes.NewReader(nil)), nil
+	}
+	var e error
+	switch {
+	case offset < 0:
+		e = opts.SetRange(0, offset)
+	case length > 0:
+		e = opts.SetRange(offset, offset+length-1)
+	case offset > 0:
+		e = opts.SetRange(offset, 0)
+	}
+	if e != nil {
+		return nil, probe.NewError(e)
+	}
 	reader, e := c.api.GetObject(bucket, object, opts)
 	if e != nil {
 		errResponse := minio.ToErrorResponse(e)
@@ -759,6 +813,9 @@ func (c *s3Client) Get(sse encrypt.ServerSide) (io.ReadCloser, *probe.Error) {
 		}
 		return nil, probe.NewError(e)
 	}
+	if offset != 0 {
+		return rangeReader{reader}, nil
+	}
 	return reader, nil
 }
 
@@ -940,12 +997,97 @@ func (c *s3Client) removeIncompleteObjects(bucket string, objectsCh <-chan strin
 	return removeObjectErrorCh
 }
 
+// Remove objects bypassing governance retention, minio-go multi-object
+// delete does not allow bypassing it.
+func (c *s3Client) removeObjectsWithBypass(bucket string, objectsCh <-chan string) <-chan minio.RemoveObjectError {
+	removeObjectErrorCh := make(chan minio.RemoveObjectError)
+
+	// Workers read from objectsCh and send errors to removeObjectErrorCh if any.
+	var wg sync.WaitGroup
+	opts := minio.RemoveObjectOptions{GovernanceBypass: true}
+	for i := 0; i < removeBypassWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for object := range objectsCh {
+				if err := c.api.RemoveObjectWithOptions(bucket, object, opts); err != nil {
+					removeObjectErrorCh <- minio.RemoveObjectError{ObjectName: object, Err: err}
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(removeObjectErrorCh)
+	}()
+
+	return removeObjectErrorCh
+}
+
+// removeObjects - removes objects or incomplete uploads of a bucket.
+func (c *s3Client) removeObjects(bucket string, objectsCh <-chan string, isIncomplete, isBypass bool) <-chan minio.RemoveObjectError {
+	switch {
+	case isIncomplete:
+		return c.removeIncompleteObjects(bucket, objectsCh)
+	case isBypass:
+		return c.removeObjectsWithBypass(bucket, objectsCh)
+	}
+	return c.api.RemoveObjects(bucket, objectsCh)
+}
+
+// objectLockedMessages - messages of the AccessDenied errors returned
+// by MinIO and AWS S3 when an object is protected by object lock.
+var objectLockedMessages = []string{
+	"Object is WORM protected and cannot be overwritten",
+	"Access Denied because object protected by object lock.",
+}
+
+// isObjectLockError - returns true if the error denies changing an
+// object protected by retention or legal hold.
+func isObjectLockError(e error) bool {
+	errResp := minio.ToErrorResponse(e)
+	switch errResp.Code {
+	case "ObjectLocked":
+		return true
+	case "AccessDenied":
+		for _, message := range objectLockedMessages {
+			if errResp.Message == message {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isObjectLockConfigMissing - returns true if the error reports an
+// object or a bucket without any object lock configuration.
+func isObjectLockConfigMissing(e error) bool {
+	errResp := minio.ToErrorResponse(e)
+	switch errResp.Code {
+	case "NoSuchObjectLockConfiguration":
+		return true
+	case "InvalidRequest":
+		return strings.Contains(errResp.Message, "ObjectLockConfiguration")
+	}
+	return false
+}
+
+// removeError - converts a remove status into a probe error, objects
+// protected by retention or legal hold are reported as ObjectLocked.
+func removeError(bucket string, removeStatus minio.RemoveObjectError) *probe.Error {
+	if isObjectLockError(removeStatus.Err) {
+		return probe.NewError(ObjectLocked{Object: bucket + "/" + removeStatus.ObjectName})
+	}
+	return probe.NewError(removeStatus.Err)
+}
+
 func (c *s3Client) AddUserAgent(app string, version string) {
 	c.api.SetAppInfo(app, version)
 }
 
-// Remove - remove object or bucket(s).
-func (c *s3Client) Remove(isIncomplete, isRemoveBucket bool, contentCh <-chan *clientContent) <-chan *probe.Error {
+// Remove - remove object or bucket(s), governance retention is
+// bypassed when isBypass is set.
+func (c *s3Client) Remove(isIncomplete, isRemoveBucket, isBypass bool, contentCh <-chan *clientContent) <-chan *probe.Error {
 	errorCh := make(chan *probe.Error)
 
 	prevBucket := ""
@@ -975,11 +1117,7 @@ func (c *s3Client) Remove(isIncomplete, isRemoveBucket bool, contentCh <-chan *c
 			if prevBucket == "" {
 				objectsCh = make(chan string)
 				prevBucket = bucket
-				if isIncomplete {
-					statusCh = c.removeIncompleteObjects(bucket, objectsCh)
-				} else {
-					statusCh = c.api.RemoveObjects(bucket, objectsCh)
-				}
+				statusCh = c.removeObjects(bucket, objectsCh, isIncomplete, isBypass)
 			}
 
 			if prevBucket != bucket {
@@ -987,7 +1125,7 @@ func (c *s3Client) Remove(isIncomplete, isRemoveBucket bool, contentCh <-chan *c
 					close(objectsCh)
 				}
 				for removeStatus := range statusCh {
-					errorCh <- probe.NewError(removeStatus.Err)
+					errorCh <- removeError(prevBucket, removeStatus)
 				}
 				// Remove bucket if it qualifies.
 				if isRemoveBucket && !isIncomplete {
@@ -997,11 +1135,7 @@ func (c *s3Client) Remove(isIncomplete, isRemoveBucket bool, contentCh <-chan *c
 				}
 				// Re-init objectsCh for next bucket
 				objectsCh = make(chan string)
-				if isIncomplete {
-					statusCh = c.removeIncompleteObjects(bucket, objectsCh)
-				} else {
-					statusCh = c.api.RemoveObjects(bucket, objectsCh)
-				}
+				statusCh = c.removeObjects(bucket, objectsCh, isIncomplete, isBypass)
 				prevBucket = bucket
 			}
 
@@ -1015,7 +1149,7 @@ func (c *s3Client) Remove(isIncomplete, isRemoveBucket bool, contentCh <-chan *c
 					case objectsCh <- objectName:
 						sent = true
 					case removeStatus := <-statusCh:
-						errorCh <- probe.NewError(removeStatus.Err)
+						errorCh <- removeError(prevBucket, removeStatus)
 					}
 				}
 			} else {
@@ -1033,7 +1167,7 @@ func (c *s3Client) Remove(isIncomplete, isRemoveBucket bool, contentCh <-chan *c
 		// Write remove objects status to errorCh
 		if statusCh != nil {
 			for removeStatus := range statusCh {
-				errorCh <- probe.NewError(removeStatus.Err)
+				errorCh <- removeError(prevBucket, removeStatus)
 			}
 		}
 		// Remove last bucket if it qualifies.
@@ -1197,13 +1331,59 @@ func (c *s3Client) SetAccess(bucketPolicy string, isJSON bool) *probe.Error {
 }
 
 // listObjectWrapper - select ObjectList version depending on the target hostname
-func (c *s3Client) listObjectWrapper(bucket, object string, isRecursive bool, doneCh chan struct{}, metadata bool) <-chan minio.ObjectInfo {
+func (c *s3Client) listObjectWrapper(bucket, object string, isRecursive bool, doneCh chan struct{}, metadata bool, startAfter string) <-chan minio.ObjectInfo {
+	if startAfter != "" {
+		return c.listObjectsStartAfter(bucket, object, isRecursive, doneCh, startAfter)
+	}
 	if metadata {
 		return c.api.ListObjectsV2WithMetadata(bucket, object, isRecursive, doneCh)
 	}
 	return c.api.ListObjectsV2(bucket, object, isRecursive, doneCh)
 }
 
+// listObjectsStartAfter lists the objects after the start key, the
+// metadata listing extension of MinIO is not available with it.
+func (c *s3Client) listObjectsStartAfter(bucket, object string, isRecursive bool, doneCh chan struct{}, startAfter string) <-chan minio.ObjectInfo {
+	objectCh := make(chan minio.ObjectInfo, 1)
+	// Default listing is delimited at "/"
+	delimiter := string(c.targetURL.Separator)
+	if isRecursive {
+		delimiter = ""
+	}
+
+	go func() {
+		defer close(objectCh)
+		core := minio.Core{Client: c.api}
+		var continuationToken string
+		for {
+			result, e := core.ListObjectsV2(bucket, object, continuationToken, false, delimiter, 0, startAfter)
+			if e != nil {
+				objectCh <- minio.ObjectInfo{Err: e}
+				return
+			}
+
+			objects := result.Contents
+			for _, commonPrefix := range result.CommonPrefixes {
+				objects = append(objects, minio.ObjectInfo{Key: commonPrefix.Prefix})
+			}
+			for _, objectInfo := range objects {
+				objectInfo.ETag = strings.Trim(objectInfo.ETag, "\"")
+				select {
+				case objectCh <- objectInfo:
+				case <-doneCh:
+					return
+				}
+			}
+
+			if !result.IsTruncated {
+				return
+			}
+			continuationToken = result.NextContinuationToken
+		}
+	}()
+	return objectCh
+}
+
 // Stat - send a 'HEAD' on a bucket or object to fetch its metadata.
 func (c *s3Client) Stat(isIncomplete, isFetchMeta, isPreserve bool, sse encrypt.ServerSide) (*clientContent, *probe.Error) {
 	c.mutex.Lock()
@@ -1268,7 +1448,7 @@ func (c *s3Client) Stat(isIncomplete, isFetchMeta, isPreserve bool, sse encrypt.
 	opts := minio.StatObjectOptions{}
 	opts.ServerSideEncryption = sse
 
-	for objectStat := range c.listObjectWrapper(bucket, prefix, nonRecursive, nil, false) {
+	for objectStat := range c.listObjectWrapper(bucket, prefix, nonRecursive, nil, false, "") {
 		if objectStat.Err != nil {
 			return nil, probe.NewError(objectStat.Err)
 		}
@@ -1452,10 +1632,26 @@ func (c *s3Client) splitPath(path string) (bucketName, objectName string) {
 
 // List - list at delimited path, if not recursive.
 func (c *s3Client) List(isRecursive, isIncomplete, isMetadata bool, showDir DirOpt) <-chan *clientContent {
+	return c.ListFrom("", isRecursive, isIncomplete, isMetadata, showDir)
+}
+
+// ListFrom - list at delimited path, if not recursive, only objects
+// after the start key are listed. The start key is an object key
+// within the bucket, it is not applied when listing all buckets.
+func (c *s3Client) ListFrom(startAfter string, isRecursive, isIncomplete, isMetadata bool, showDir DirOpt) <-chan *clientContent {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
 
 	contentCh := make(chan *clientContent)
+	if isIncomplete && startAfter != "" {
+		go func() {
+			defer close(contentCh)
+			contentCh <- &clientContent{
+				Err: probe.NewError(APINotImplemented{API: "ListFrom", APIType: "incomplete uploads"}),
+			}
+		}()
+		return contentCh
+	}
 	if isIncomplete {
 		if isRecursive {
 			if showDir == DirNone {
@@ -1469,12 +1665,12 @@ func (c *s3Client) List(isRecursive, isIncomplete, isMetadata bool, showDir DirO
 	} else {
 		if isRecursive {
 			if showDir == DirNone {
-				go c.listRecursiveInRoutine(contentCh, isMetadata)
+				go c.listRecursiveInRoutine(contentCh, isMetadata, startAfter)
 			} else {
-				go c.listRecursiveInRoutineDirOpt(contentCh, showDir, isMetadata)
+				go c.listRecursiveInRoutineDirOpt(contentCh, showDir, isMetadata, startAfter)
 			}
 		} else {
-			go c.listInRoutine(contentCh, isMetadata)
+			go c.listInRoutine(contentCh, isMetadata, startAfter)
 		}
 	}
 
@@ -1742,6 +1938,7 @@ func (c *s3Client) objectInfo2ClientContent(bucket string, entry minio.ObjectInf
 	content.URL = url
 	content.Size = entry.Size
 	content.ETag = entry.ETag
+	content.StorageClass = entry.StorageClass
 	content.Time = entry.LastModified
 	content.Expires = entry.Expires
 	content.UserMetadata = map[string]string{}
@@ -1771,14 +1968,15 @@ func (c *s3Client) bucketStat(bucket string) (*clientContent, *probe.Error) {
 }
 
 // Recursively lists objects.
-func (c *s3Client) listRecursiveInRoutineDirOpt(contentCh chan *clientContent, dirOpt DirOpt, metadata bool) {
+func (c *s3Client) listRecursiveInRoutineDirOpt(contentCh chan *clientContent, dirOpt DirOpt, metadata bool, startAfter string) {
 	defer close(contentCh)
 	// Closure function reads list objects and sends to contentCh. If a directory is found, it lists
-	// objects of the directory content recursively.
+	// objects of the directory content recursively. Directories are only listed by S3 after the
+	// start key if they have objects after it, so the same start key applies to all of them.
 	var listDir func(bucket, object string) bool
 	listDir = func(bucket, object string) (isStop bool) {
 		isRecursive := false
-		for entry := range c.listObjectWrapper(bucket, object, isRecursive, nil, metadata) {
+		for entry := range c.listObjectWrapper(bucket, object, isRecursive, nil, metadata, startAfter) {
 			if entry.Err != nil {
 				url := *c.targetURL
 				url.Path = c.joinPath(bucket, object)
@@ -1868,7 +2066,7 @@ func (c *s3Client) listRecursiveInRoutineDirOpt(contentCh chan *clientContent, d
 	}
 }
 
-func (c *s3Client) listInRoutine(contentCh chan *clientContent, metadata bool) {
+func (c *s3Client) listInRoutine(contentCh chan *clientContent, metadata bool, startAfter string) {
 	defer close(contentCh)
 	// get bucket and object from URL.
 	b, o := c.url2BucketAndObject()
@@ -1900,7 +2098,7 @@ func (c *s3Client) listInRoutine(contentCh chan *clientContent, metadata bool) {
 		contentCh <- content
 	default:
 		isRecursive := false
-		for object := range c.listObjectWrapper(b, o, isRecursive, nil, metadata) {
+		for object := range c.listObjectWrapper(b, o, isRecursive, nil, metadata, startAfter) {
 			if object.Err != nil {
 				contentCh <- &clientContent{
 					Err: probe.NewError(object.Err),
@@ -1922,7 +2120,7 @@ func (c *s3Client) listInRoutine(contentCh chan *clientContent, metadata bool) {
 // different use cases, following list captures these.
 const (
 	// General purpose.
-	// s3StorageClassStandard = "STANDARD"
+	s3StorageClassStandard = "STANDARD"
 	// Infrequent access.
 	// s3StorageClassInfrequent = "STANDARD_IA"
 	// Reduced redundancy access.
@@ -1931,7 +2129,7 @@ const (
 	s3StorageClassGlacier = "GLACIER"
 )
 
-func (c *s3Client) listRecursiveInRoutine(contentCh chan *clientContent, metadata bool) {
+func (c *s3Client) listRecursiveInRoutine(contentCh chan *clientContent, metadata bool, startAfter string) {
 	defer close(contentCh)
 	// get bucket and object from URL.
 	b, o := c.url2BucketAndObject()
@@ -1946,7 +2144,7 @@ func (c *s3Client) listRecursiveInRoutine(contentCh chan *clientContent, metadat
 		}
 		for _, bucket := range buckets {
 			isRecursive := true
-			for object := range c.listObjectWrapper(bucket.Name, o, isRecursive, nil, metadata) {
+			for object := range c.listObjectWrapper(bucket.Name, o, isRecursive, nil, metadata, "") {
 				if object.Err != nil {
 					contentCh <- &clientContent{
 						Err: probe.NewError(object.Err),
@@ -1972,7 +2170,13 @@ func (c *s3Client) listRecursiveInRoutine(contentCh chan *clientContent, metadat
 		}
 	default:
 		isRecursive := true
-		for object := range c.listObjectWrapper(b, o, isRecursive, nil, metadata) {
+		var objectCh <-chan minio.ObjectInfo
+		if globalParallelList > 1 {
+			objectCh = c.listObjectsParallel(b, o, globalParallelList, nil, metadata, startAfter)
+		} else {
+			objectCh = c.listObjectWrapper(b, o, isRecursive, nil, metadata, startAfter)
+		}
+		for object := range objectCh {
 			if object.Err != nil {
 				contentCh <- &clientContent{
 					Err: probe.NewError(object.Err),
@@ -1984,6 +2188,7 @@ func (c *s3Client) listRecursiveInRoutine(contentCh chan *clientContent, metadat
 			// Join bucket and incoming object key.
 			url.Path = c.joinPath(b, object.Key)
 			content.URL = url
+			content.StorageClass = object.StorageClass
 			content.Size = object.Size
 			content.ETag = object.ETag
 			content.Time = object.LastModified
@@ -2052,13 +2257,33 @@ func (c *s3Client) SetObjectLockConfig(mode *minio.RetentionMode, validity *uint
 	return nil
 }
 
-// Set object retention for a given object.
-func (c *s3Client) PutObjectRetention(mode *minio.RetentionMode, retainUntilDate *time.Time) *probe.Error {
+// Set object retention for a given object, or a version of it. The
+// retention is cleared when mode is not set.
+func (c *s3Client) PutObjectRetention(versionID string, mode *minio.RetentionMode, retainUntilDate *time.Time, bypassGovernance bool) *probe.Error {
 	bucket, object := c.url2BucketAndObject()
 
+	// minio-go always sends a mode, an empty retention is sent instead.
+	if mode == nil {
+		reqParams := url.Values{"retention": []string{""}}
+		if versionID != "" {
+			reqParams.Set("versionId", versionID)
+		}
+		header := make(http.Header)
+		if bypassGovernance {
+			header.Set("X-Amz-Bypass-Governance-Retention", "true")
+		}
+		body, err := c.signedRequest(http.MethodPut, bucket, object, reqParams, header, []byte("<Retention/>"))
+		if err != nil {
+			return err.Trace(bucket, object)
+		}
+		return probe.NewError(body.Close())
+	}
+
 	opts := minio.PutObjectRetentionOptions{
-		RetainUntilDate: retainUntilDate,
-		Mode:            mode,
+		GovernanceBypass: bypassGovernance,
+		RetainUntilDate:  retainUntilDate,
+		Mode:             mode,
+		VersionID:        versionID,
 	}
 	err := c.api.PutObjectRetention(bucket, object, opts)
 	if err != nil {
@@ -2068,6 +2293,175 @@ func (c *s3Client) PutObjectRetention(mode *minio.RetentionMode, retainUntilDate
 	return nil
 }
 
+// Get object retention of a given object, or a version of it. No mode
+// is returned when the object has no retention.
+func (c *s3Client) GetObjectRetention(versionID string) (*minio.RetentionMode, *time.Time, *probe.Error) {
+	bucket, object := c.url2BucketAndObject()
+
+	mode, retainUntilDate, e := c.api.GetObjectRetention(bucket, object, versionID)
+	if e != nil {
+		if minio.ToErrorResponse(e).Code == "NoSuchKey" {
+			return nil, nil, probe.NewError(ObjectMissing{})
+		}
+		if isObjectLockConfigMissing(e) {
+			return nil, nil, nil
+		}
+		return nil, nil, probe.NewError(e)
+	}
+	if mode != nil && *mode == "" {
+		return nil, nil, nil
+	}
+	return mode, retainUntilDate, nil
+}
+
+// signedRequest - sends a request with a subresource which is not
+// implemented by minio-go. The URL is presigned to resolve the bucket
+// lookup and region, the request is then signed along with its headers
+// and sent with the same transport as the other API calls.
+func (c *s3Client) signedRequest(method, bucket, object string, reqParams url.Values, header http.Header, body []byte) (io.ReadCloser, *probe.Error) {
+	presignedURL, e := c.api.Presign(method, bucket, object, time.Minute, reqParams)
+	if e != nil {
+		return nil, probe.NewError(e)
+	}
+	// Only the resource of the presigned URL is kept.
+	query := presignedURL.Query()
+	var region string
+	if credential := strings.Split(query.Get("X-Amz-Credential"), "/"); len(credential) > 2 {
+		region = credential[2]
+	}
+	for _, k := range []string{
+		"X-Amz-Algorithm", "X-Amz-Credential", "X-Amz-Date", "X-Amz-Expires",
+		"X-Amz-SignedHeaders", "X-Amz-Signature", "X-Amz-Security-Token",
+		"AWSAccessKeyId", "Expires", "Signature",
+	} {
+		query.Del(k)
+	}
+	presignedURL.RawQuery = s3utils.QueryEncode(query)
+
+	req, e := http.NewRequest(method, presignedURL.String(), bytes.NewReader(body))
+	if e != nil {
+		return nil, probe.NewError(e)
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	if body != nil {
+		md5Sum := md5.Sum(body)
+		req.Header.Set("Content-Md5", base64.StdEncoding.EncodeToString(md5Sum[:]))
+		req.ContentLength = int64(len(body))
+	}
+
+	value, e := c.creds.Get()
+	if e != nil {
+		return nil, probe.NewError(e)
+	}
+	if value.SignerType.IsV2() {
+		req = s3signer.SignV2(*req, value.AccessKeyID, value.SecretAccessKey, c.virtualStyle)
+	} else {
+		sha256Sum := sha256.Sum256(body)
+		req.Header.Set("X-Amz-Content-Sha256", hex.EncodeToString(sha256Sum[:]))
+		req = s3signer.SignV4(*req, value.AccessKeyID, value.SecretAccessKey, value.SessionToken, region)
+	}
+	resp, e := c.httpClient.Do(req)
+	if e != nil {
+		return nil, probe.NewError(e)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		errResponse := minio.ErrorResponse{}
+		if e = xml.NewDecoder(resp.Body).Decode(&errResponse); e != nil || errResponse.Code == "" {
+			return nil, probe.NewError(errors.New(resp.Status))
+		}
+		if errResponse.Code == "NoSuchKey" {
+			return nil, probe.NewError(ObjectMissing{})
+		}
+		return nil, probe.NewError(errResponse)
+	}
+	return resp.Body, nil
+}
+
+// GetTags - get the tags set on an object.
+func (c *s3Client) GetTags() (map[string]string, *probe.Error) {
+	bucket, object := c.url2BucketAndObject()
+	if object == "" {
+		return nil, probe.NewError(ObjectMissing{})
+	}
+
+	reqParams := url.Values{"tagging": []string{""}}
+	body, err := c.signedRequest(http.MethodGet, bucket, object, reqParams, nil, nil)
+	if err != nil {
+		return nil, err.Trace(bucket, object)
+	}
+	defer body.Close()
+
+	var tagging struct {
+		Tags []struct {
+			Key   string
+			Value string
+		} `xml:"TagSet>Tag"`
+	}
+	if e := xml.NewDecoder(body).Decode(&tagging); e != nil {
+		return nil, probe.NewError(e)
+	}
+	tags := make(map[string]string, len(tagging.Tags))
+	for _, tag := range tagging.Tags {
+		tags[tag.Key] = tag.Value
+	}
+	return tags, nil
+}
+
+// objectLegalHold - legal hold configuration of an object.
+type objectLegalHold struct {
+	XMLName xml.Name        `xml:"http://s3.amazonaws.com/doc/2006-03-01/ LegalHold"`
+	Status  legalHoldStatus `xml:"Status"`
+}
+
+// PutObjectLegalHold - places or lifts the legal hold of an object.
+func (c *s3Client) PutObjectLegalHold(status legalHoldStatus) *probe.Error {
+	bucket, object := c.url2BucketAndObject()
+	if object == "" {
+		return probe.NewError(ObjectMissing{})
+	}
+
+	legalHold, e := xml.Marshal(objectLegalHold{Status: status})
+	if e != nil {
+		return probe.NewError(e)
+	}
+	reqParams := url.Values{"legal-hold": []string{""}}
+	body, err := c.signedRequest(http.MethodPut, bucket, object, reqParams, nil, legalHold)
+	if err != nil {
+		return err.Trace(bucket, object)
+	}
+	return probe.NewError(body.Close())
+}
+
+// GetObjectLegalHold - returns the legal hold status of an object.
+func (c *s3Client) GetObjectLegalHold() (legalHoldStatus, *probe.Error) {
+	bucket, object := c.url2BucketAndObject()
+	if object == "" {
+		return "", probe.NewError(ObjectMissing{})
+	}
+
+	reqParams := url.Values{"legal-hold": []string{""}}
+	body, err := c.signedRequest(http.MethodGet, bucket, object, reqParams, nil, nil)
+	if err != nil {
+		if isObjectLockConfigMissing(err.ToGoError()) {
+			return legalHoldOff, nil
+		}
+		return "", err.Trace(bucket, object)
+	}
+	defer body.Close()
+
+	var legalHold struct {
+		Status legalHoldStatus
+	}
+	if e := xml.NewDecoder(body).Decode(&legalHold); e != nil {
+		return "", probe.NewError(e)
+	}
+	return legalHold.Status, nil
+}
+
 // Get object lock configuration of bucket.
 func (c *s3Client) GetObjectLockConfig() (mode *minio.RetentionMode, validity *uint, unit *minio.ValidityUnit, perr *probe.Error) {
 	bucket, _ := c.url2BucketAndObject()
@@ -20,11 +20,17 @@ package cmd
 import (
 	"bytes"
 	"context"
+	"encoding/xml"
+	"fmt"
 	"io"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"strconv"
+	"strings"
+	"time"
 
+	"github.com/minio/mc/pkg/probe"
 	minio "github.com/minio/minio-go/v6"
 	. "gopkg.in/check.v1"
 )
@@ -147,11 +153,9 @@ func (h objectHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			w.WriteHeader(http.StatusNotFound)
 			return
 		}
-		w.Header().Set("Content-Length", strconv.Itoa(len(h.data)))
-		w.Header().Set("Last-Modified", UTCNow().Format(http.TimeFormat))
+		// Range requests are served as well.
 		w.Header().Set("ETag", "9af2f8218b150c351ad802c6f3d66abe")
-		w.WriteHeader(http.StatusOK)
-		io.Copy(w, bytes.NewReader(h.data))
+		http.ServeContent(w, r, "", UTCNow(), bytes.NewReader(h.data))
 	}
 }
 
@@ -227,7 +231,7 @@ func (s *TestSuite) TestObjectOperations(c *C) {
 	c.Assert(err, IsNil)
 	c.Assert(n, Equals, int64(len(object.data)))
 
-	reader, err = s3c.Get(nil)
+	reader, err = s3c.Get(0, -1, nil)
 	c.Assert(err, IsNil)
 	var buffer bytes.Buffer
 	{
@@ -235,6 +239,26 @@ func (s *TestSuite) TestObjectOperations(c *C) {
 		c.Assert(err, IsNil)
 		c.Assert(buffer.Bytes(), DeepEquals, object.data)
 	}
+
+	// Ranges of the object.
+	for _, testCase := range []struct {
+		offset, length int64
+		data           string
+	}{
+		{7, -1, "World"},
+		{0, 5, "Hello"},
+		{5, 2, ", "},
+		{-5, -1, "World"},
+		{3, 0, ""},
+		{20, -1, ""},
+		{20, 5, ""},
+	} {
+		reader, err = s3c.Get(testCase.offset, testCase.length, nil)
+		c.Assert(err, IsNil)
+		data, e := ioutil.ReadAll(reader)
+		c.Assert(e, IsNil)
+		c.Assert(string(data), Equals, testCase.data)
+	}
 }
 
 var testSelectCompressionTypeCases = []struct {
@@ -261,3 +285,391 @@ func (s *TestSuite) TestSelectCompressionType(c *C) {
 		c.Assert(cType, DeepEquals, test.compressionType)
 	}
 }
+
+// listHandler is an http.Handler that lists the keys of a bucket with
+// the ListObjectsV2 API, pageSize keys per page, one if not set, to
+// exercise the paging.
+type listHandler struct {
+	keys     []string
+	pageSize int
+}
+
+func (h listHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if _, ok := query["location"]; ok {
+		response := []byte("<LocationConstraint xmlns=\"http://doc.s3.amazonaws.com/2006-03-01\"></LocationConstraint>")
+		w.Header().Set("Content-Length", strconv.Itoa(len(response)))
+		w.Write(response)
+		return
+	}
+	if r.URL.Path != "/bucket/" || query.Get("list-type") != "2" {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	prefix, delimiter := query.Get("prefix"), query.Get("delimiter")
+	after := query.Get("start-after")
+	if token := query.Get("continuation-token"); token != "" {
+		after = token
+	}
+	var response bytes.Buffer
+	response.WriteString("<ListBucketResult xmlns=\"http://s3.amazonaws.com/doc/2006-03-01/\"><Name>bucket</Name>")
+	count := 0
+	for _, key := range h.keys {
+		if !strings.HasPrefix(key, prefix) || key <= after {
+			continue
+		}
+		if i := strings.Index(key[len(prefix):], delimiter); delimiter != "" && i >= 0 {
+			commonPrefix := key[:len(prefix)+i+len(delimiter)]
+			if commonPrefix <= after {
+				continue
+			}
+			// Continue after all the keys of the common prefix.
+			fmt.Fprintf(&response, "<CommonPrefixes><Prefix>%s</Prefix></CommonPrefixes>", commonPrefix)
+			after = commonPrefix + "\U0010FFFF"
+		} else {
+			fmt.Fprintf(&response, "<Contents><Key>%s</Key><Size>%d</Size><ETag>\"etag\"</ETag><LastModified>2019-11-05T18:24:21.097Z</LastModified><StorageClass>STANDARD</StorageClass></Contents>", key, len(key))
+			after = key
+		}
+		if count++; count < h.pageSize {
+			continue
+		}
+		fmt.Fprintf(&response, "<IsTruncated>true</IsTruncated><NextContinuationToken>%s</NextContinuationToken></ListBucketResult>", after)
+		w.Write(response.Bytes())
+		return
+	}
+	response.WriteString("<IsTruncated>false</IsTruncated></ListBucketResult>")
+	w.Write(response.Bytes())
+}
+
+// Test parallel listing returns the same objects in the same order.
+func (s *TestSuite) TestListParallel(c *C) {
+	keys := []string{
+		"a-b", "a/", "a/1", "a/b/1", "a/b/2", "a/c/1", "b", "c/1", "c/d/e/f/1", "c/d/e/g", "c/e", "d/1",
+	}
+	server := httptest.NewServer(listHandler{keys: keys})
+	defer server.Close()
+
+	conf := new(Config)
+	conf.HostURL = server.URL + "/bucket/"
+	conf.AccessKey = "WLGDGYAQYIGI833EV05A"
+	conf.SecretKey = "BYvgJM101sHngl2uzjXS/OBF/aMxAN06JrJ3qJlF"
+	conf.Signature = "S3v4"
+	s3c, err := s3New(conf)
+	c.Assert(err, IsNil)
+
+	defer func() { globalParallelList = 0 }()
+	testCases := []struct {
+		parallel   int
+		startAfter string
+		expected   []string
+	}{
+		{0, "", keys},
+		{2, "", keys},
+		{16, "", keys},
+		{16, "a/b/1", keys[4:]},
+		{16, "a/c", keys[5:]},
+		{3, "c/d/e/f/1", keys[9:]},
+		{16, "a-b", keys[1:]},
+		{16, "b", keys[7:]},
+		{16, "c/e", keys[11:]},
+		{16, "d/1", nil},
+	}
+	for i, testCase := range testCases {
+		globalParallelList = testCase.parallel
+		var listed []string
+		for content := range s3c.ListFrom(testCase.startAfter, true, false, false, DirNone) {
+			c.Assert(content.Err, IsNil)
+			listed = append(listed, strings.TrimPrefix(content.URL.Path, "/bucket/"))
+		}
+		c.Assert(listed, DeepEquals, testCase.expected, Commentf("Test %d", i+1))
+	}
+
+	// Prefixes are expanded into their levels and their prefixes.
+	shards, e := s3c.(*s3Client).discoverListShards("bucket", "", 16)
+	c.Assert(e, IsNil)
+	c.Assert(shards, DeepEquals, []listShard{
+		{key: "", level: true}, {key: "a/", level: true}, {key: "a/b/", level: true}, {key: "a/c/", level: true},
+		{key: "c/", level: true}, {key: "c/d/e/"}, {key: "d/", level: true},
+	})
+}
+
+// Test parallel listing of a large flat listing, a level with too
+// many objects is not expanded.
+func (s *TestSuite) TestListParallelFlat(c *C) {
+	var keys []string
+	for i := 0; i < 5000; i++ {
+		keys = append(keys, fmt.Sprintf("a%05d", i))
+	}
+	keys = append(keys, "m/1", "m/2")
+	for i := 0; i < 3000; i++ {
+		keys = append(keys, fmt.Sprintf("n%05d", i))
+	}
+	keys = append(keys, "z/1")
+	server := httptest.NewServer(listHandler{keys: keys, pageSize: 1000})
+	defer server.Close()
+
+	conf := new(Config)
+	conf.HostURL = server.URL + "/bucket/"
+	conf.AccessKey = "WLGDGYAQYIGI833EV05A"
+	conf.SecretKey = "BYvgJM101sHngl2uzjXS/OBF/aMxAN06JrJ3qJlF"
+	conf.Signature = "S3v4"
+	clnt, err := s3New(conf)
+	c.Assert(err, IsNil)
+
+	s3c := clnt.(*s3Client)
+	shards, e := s3c.discoverListShards("bucket", "", 16)
+	c.Assert(e, IsNil)
+	c.Assert(shards, DeepEquals, []listShard{{key: ""}})
+	shards, e = s3c.discoverListShards("bucket", "n", 16)
+	c.Assert(e, IsNil)
+	c.Assert(shards, DeepEquals, []listShard{{key: "n"}})
+
+	defer func() { globalParallelList = 0 }()
+	globalParallelList = 16
+	for _, startAfter := range []string{"", "a02500", "m/1", "n02999"} {
+		var expected []string
+		for _, key := range keys {
+			if key > startAfter {
+				expected = append(expected, key)
+			}
+		}
+		var listed []string
+		for content := range clnt.ListFrom(startAfter, true, false, false, DirNone) {
+			c.Assert(content.Err, IsNil)
+			listed = append(listed, strings.TrimPrefix(content.URL.Path, "/bucket/"))
+		}
+		c.Assert(listed, DeepEquals, expected, Commentf("start after %q", startAfter))
+	}
+}
+
+// legalHoldHandler is an http.Handler that keeps the legal hold
+// status of an object.
+type legalHoldHandler struct {
+	resource string
+	status   *string
+}
+
+func (h legalHoldHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if _, ok := r.URL.Query()["location"]; ok {
+		response := []byte("<LocationConstraint xmlns=\"http://doc.s3.amazonaws.com/2006-03-01\"></LocationConstraint>")
+		w.Header().Set("Content-Length", strconv.Itoa(len(response)))
+		w.Write(response)
+		return
+	}
+	if _, ok := r.URL.Query()["legal-hold"]; !ok || r.URL.Path != h.resource {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte("<Error><Code>NoSuchKey</Code></Error>"))
+		return
+	}
+	switch r.Method {
+	case "PUT":
+		if r.Header.Get("Content-Md5") == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		var legalHold objectLegalHold
+		if e := xml.NewDecoder(r.Body).Decode(&legalHold); e != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		*h.status = string(legalHold.Status)
+	case "GET":
+		fmt.Fprintf(w, "<LegalHold><Status>%s</Status></LegalHold>", *h.status)
+	}
+}
+
+// Test placing, getting and lifting legal holds.
+func (s *TestSuite) TestObjectLegalHold(c *C) {
+	status := "OFF"
+	server := httptest.NewServer(legalHoldHandler{resource: "/bucket/object", status: &status})
+	defer server.Close()
+
+	conf := new(Config)
+	conf.HostURL = server.URL + "/bucket/object"
+	conf.AccessKey = "WLGDGYAQYIGI833EV05A"
+	conf.SecretKey = "BYvgJM101sHngl2uzjXS/OBF/aMxAN06JrJ3qJlF"
+	conf.Signature = "S3v4"
+	s3c, err := s3New(conf)
+	c.Assert(err, IsNil)
+
+	c.Assert(s3c.PutObjectLegalHold(legalHoldOn), IsNil)
+	c.Assert(status, Equals, "ON")
+	legalHold, err := s3c.GetObjectLegalHold()
+	c.Assert(err, IsNil)
+	c.Assert(legalHold, Equals, legalHoldOn)
+
+	c.Assert(s3c.PutObjectLegalHold(legalHoldOff), IsNil)
+	legalHold, err = s3c.GetObjectLegalHold()
+	c.Assert(err, IsNil)
+	c.Assert(legalHold, Equals, legalHoldOff)
+
+	conf.HostURL = server.URL + "/bucket/missing"
+	s3c, err = s3New(conf)
+	c.Assert(err, IsNil)
+	_, err = s3c.GetObjectLegalHold()
+	c.Assert(err, NotNil)
+	c.Assert(err.ToGoError(), FitsTypeOf, ObjectMissing{})
+}
+
+// retentionHandler is an http.Handler that keeps the retention
+// of an object.
+type retentionHandler struct {
+	resource  string
+	retention *string
+	bypass    *bool
+}
+
+func (h retentionHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if _, ok := r.URL.Query()["location"]; ok {
+		response := []byte("<LocationConstraint xmlns=\"http://doc.s3.amazonaws.com/2006-03-01\"></LocationConstraint>")
+		w.Header().Set("Content-Length", strconv.Itoa(len(response)))
+		w.Write(response)
+		return
+	}
+	if _, ok := r.URL.Query()["retention"]; !ok || r.URL.Path != h.resource {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte("<Error><Code>NoSuchKey</Code></Error>"))
+		return
+	}
+	switch r.Method {
+	case "PUT":
+		body, e := ioutil.ReadAll(r.Body)
+		if e != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		*h.retention = string(body)
+		// The bypass header is only honoured when signed.
+		*h.bypass = strings.EqualFold(r.Header.Get("X-Amz-Bypass-Governance-Retention"), "true") &&
+			strings.Contains(r.Header.Get("Authorization"), "x-amz-bypass-governance-retention")
+	case "GET":
+		if *h.retention == "<Retention/>" {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte("<Error><Code>NoSuchObjectLockConfiguration</Code></Error>"))
+			return
+		}
+		w.Write([]byte(*h.retention))
+	}
+}
+
+// Test setting, getting and clearing object retention.
+func (s *TestSuite) TestObjectRetention(c *C) {
+	var retention string
+	var bypass bool
+	server := httptest.NewServer(retentionHandler{resource: "/bucket/object", retention: &retention, bypass: &bypass})
+	defer server.Close()
+
+	conf := new(Config)
+	conf.HostURL = server.URL + "/bucket/object"
+	conf.AccessKey = "WLGDGYAQYIGI833EV05A"
+	conf.SecretKey = "BYvgJM101sHngl2uzjXS/OBF/aMxAN06JrJ3qJlF"
+	conf.Signature = "S3v4"
+	s3c, err := s3New(conf)
+	c.Assert(err, IsNil)
+
+	mode := minio.Governance
+	retainUntil := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	c.Assert(s3c.PutObjectRetention("", &mode, &retainUntil, false), IsNil)
+	c.Assert(bypass, Equals, false)
+	gotMode, gotRetainUntil, err := s3c.GetObjectRetention("")
+	c.Assert(err, IsNil)
+	c.Assert(*gotMode, Equals, minio.Governance)
+	c.Assert(gotRetainUntil.Equal(retainUntil), Equals, true)
+
+	c.Assert(s3c.PutObjectRetention("", nil, nil, true), IsNil)
+	c.Assert(bypass, Equals, true)
+	gotMode, gotRetainUntil, err = s3c.GetObjectRetention("")
+	c.Assert(err, IsNil)
+	c.Assert(gotMode, IsNil)
+	c.Assert(gotRetainUntil, IsNil)
+}
+
+// lockedObjectsHandler is an http.Handler that denies removing
+// objects under retention unless governance retention is bypassed.
+type lockedObjectsHandler struct {
+	bucket string
+}
+
+func (h lockedObjectsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	const lockedMessage = "Object is WORM protected and cannot be overwritten"
+	switch {
+	case r.Method == "GET" && r.URL.Path == "/"+h.bucket+"/":
+		response := []byte("<LocationConstraint xmlns=\"http://doc.s3.amazonaws.com/2006-03-01\"></LocationConstraint>")
+		w.Header().Set("Content-Length", strconv.Itoa(len(response)))
+		w.Write(response)
+	case r.Method == "POST":
+		var deleteRequest struct {
+			Objects []struct {
+				Key string
+			} `xml:"Object"`
+		}
+		if e := xml.NewDecoder(r.Body).Decode(&deleteRequest); e != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		response := "<DeleteResult>"
+		for _, object := range deleteRequest.Objects {
+			response += fmt.Sprintf("<Error><Key>%s</Key><Code>AccessDenied</Code><Message>%s</Message></Error>", object.Key, lockedMessage)
+		}
+		response += "</DeleteResult>"
+		w.Write([]byte(response))
+	case r.Method == "DELETE":
+		if !strings.EqualFold(r.Header.Get("X-Amz-Bypass-Governance-Retention"), "true") {
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprintf(w, "<Error><Code>AccessDenied</Code><Message>%s</Message></Error>", lockedMessage)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+// Test the errors reported as denied by object lock.
+func (s *TestSuite) TestIsObjectLockError(c *C) {
+	testCases := []struct {
+		err      minio.ErrorResponse
+		expected bool
+	}{
+		{minio.ErrorResponse{Code: "ObjectLocked"}, true},
+		{minio.ErrorResponse{Code: "AccessDenied", Message: "Object is WORM protected and cannot be overwritten"}, true},
+		{minio.ErrorResponse{Code: "AccessDenied", Message: "Access Denied because object protected by object lock."}, true},
+		{minio.ErrorResponse{Code: "AccessDenied", Message: "Access Denied."}, false},
+		{minio.ErrorResponse{Code: "InvalidRequest", Message: "Bucket is missing ObjectLockConfiguration"}, false},
+		{minio.ErrorResponse{Code: "InternalError", Message: "retention service unavailable"}, false},
+	}
+	for _, testCase := range testCases {
+		c.Assert(isObjectLockError(testCase.err), Equals, testCase.expected)
+	}
+}
+
+// Test removing objects protected by retention.
+func (s *TestSuite) TestRemoveLocked(c *C) {
+	server := httptest.NewServer(lockedObjectsHandler{bucket: "bucket"})
+	defer server.Close()
+
+	conf := new(Config)
+	conf.HostURL = server.URL + "/bucket"
+	conf.AccessKey = "WLGDGYAQYIGI833EV05A"
+	conf.SecretKey = "BYvgJM101sHngl2uzjXS/OBF/aMxAN06JrJ3qJlF"
+	conf.Signature = "S3v4"
+	s3c, err := s3New(conf)
+	c.Assert(err, IsNil)
+
+	remove := func(isBypass bool) (errs []*probe.Error) {
+		contentCh := make(chan *clientContent, 1)
+		contentCh <- &clientContent{URL: *newClientURL(server.URL + "/bucket/object")}
+		close(contentCh)
+		for err := range s3c.Remove(false, false, isBypass, contentCh) {
+			errs = append(errs, err)
+		}
+		return errs
+	}
+
+	errs := remove(false)
+	c.Assert(len(errs), Equals, 1)
+	c.Assert(errs[0].ToGoError(), DeepEquals, ObjectLocked{Object: "bucket/object"})
+
+	c.Assert(remove(true), HasLen, 0)
+}
@@ -39,6 +39,16 @@ const (
 	DirLast
 )
 
+// legalHoldStatus - status of the legal hold of an object.
+type legalHoldStatus string
+
+const (
+	// legalHoldOn - the object is under legal hold.
+	legalHoldOn legalHoldStatus = "ON"
+	// legalHoldOff - the object is not under legal hold.
+	legalHoldOff legalHoldStatus = "OFF"
+)
+
 // Default number of multipart workers for a Put operation.
 const defaultMultipartThreadsNum = 4
 
@@ -47,6 +57,7 @@ type Client interface {
 	// Common operations
 	Stat(isIncomplete, isFetchMeta, isPreserve bool, sse encrypt.ServerSide) (content *clientContent, err *probe.Error)
 	List(isRecursive, isIncomplete, isFetchMeta bool, showDir DirOpt) <-chan *clientContent
+	ListFrom(startAfter string, isRecursive, isIncomplete, isFetchMeta bool, showDir DirOpt) <-chan *clientContent
 
 	// Bucket operations
 	MakeBucket(region string, ignoreExisting, withLock bool) *probe.Error
@@ -64,11 +75,19 @@ type Client interface {
 	// Runs select expression on object storage on specific files.
 	Select(expression string, sse encrypt.ServerSide, opts SelectObjectOpts) (io.ReadCloser, *probe.Error)
 
-	// I/O operations with metadata.
-	Get(sse encrypt.ServerSide) (reader io.ReadCloser, err *probe.Error)
+	// I/O operations with metadata, Get reads length bytes from offset
+	// or till the end if length is negative, a negative offset reads
+	// the last -offset bytes.
+	Get(offset, length int64, sse encrypt.ServerSide) (reader io.ReadCloser, err *probe.Error)
 	Put(ctx context.Context, reader io.Reader, size int64, metadata map[string]string, progress io.Reader, sse encrypt.ServerSide) (n int64, err *probe.Error)
 	// Object Locking related API
-	PutObjectRetention(mode *minio.RetentionMode, retainUntilDate *time.Time) *probe.Error
+	PutObjectRetention(versionID string, mode *minio.RetentionMode, retainUntilDate *time.Time, bypassGovernance bool) *probe.Error
+	GetObjectRetention(versionID string) (mode *minio.RetentionMode, retainUntilDate *time.Time, err *probe.Error)
+	PutObjectLegalHold(status legalHoldStatus) *probe.Error
+	GetObjectLegalHold() (legalHoldStatus, *probe.Error)
+
+	// Object tagging operations.
+	GetTags() (map[string]string, *probe.Error)
 
 	// I/O operations with expiration
 	ShareDownload(expires time.Duration) (string, *probe.Error)
@@ -78,7 +97,7 @@ type Client interface {
 	Watch(params watchParams) (*watchObject, *probe.Error)
 
 	// Delete operations
-	Remove(isIncomplete, isRemoveBucket bool, contentCh <-chan *clientContent) (errorCh <-chan *probe.Error)
+	Remove(isIncomplete, isRemoveBucket, isBypass bool, contentCh <-chan *clientContent) (errorCh <-chan *probe.Error)
 
 	// GetURL returns back internal url
 	GetURL() clientURL
@@ -26,12 +26,14 @@ import (
 	"path/filepath"
 	"regexp"
 	"strings"
+	"sync"
 	"time"
 
 	"golang.org/x/net/http/httpguts"
 	"gopkg.in/h2non/filetype.v1"
 
 	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/hookreader"
 	"github.com/minio/mc/pkg/probe"
 	minio "github.com/minio/minio-go/v6"
 	"github.com/minio/minio-go/v6/pkg/encrypt"
@@ -162,13 +164,31 @@ func getSourceStreamFromURL(urlStr string, encKeyDB map[string][]prefixSSEPair)
 	return reader, err
 }
 
+// getSourceStreamRangeFromURL gets a reader of length bytes from offset
+// of URL, see Client.Get for negative offset and length.
+func getSourceStreamRangeFromURL(urlStr string, offset, length int64, encKeyDB map[string][]prefixSSEPair) (reader io.ReadCloser, err *probe.Error) {
+	alias, urlStrFull, _, err := expandAlias(urlStr)
+	if err != nil {
+		return nil, err.Trace(urlStr)
+	}
+	sourceClnt, err := newClientFromAlias(alias, urlStrFull)
+	if err != nil {
+		return nil, err.Trace(alias, urlStrFull)
+	}
+	reader, err = sourceClnt.Get(offset, length, getSSE(urlStr, encKeyDB[alias]))
+	if err != nil {
+		return nil, err.Trace(alias, urlStrFull)
+	}
+	return reader, nil
+}
+
 // getSourceStream gets a reader from URL.
 func getSourceStream(alias string, urlStr string, fetchStat bool, sse encrypt.ServerSide) (reader io.ReadCloser, metadata map[string]string, err *probe.Error) {
 	sourceClnt, err := newClientFromAlias(alias, urlStr)
 	if err != nil {
 		return nil, nil, err.Trace(alias, urlStr)
 	}
-	reader, err = sourceClnt.Get(sse)
+	reader, err = sourceClnt.Get(0, -1, sse)
 	if err != nil {
 		return nil, nil, err.Trace(alias, urlStr)
 	}
@@ -213,6 +233,60 @@ func getSourceStream(alias string, urlStr string, fetchStat bool, sse encrypt.Se
 	return reader, metadata, nil
 }
 
+// forEachObject - calls fn with a client of the target, or of each
+// object with the target prefix when recursive, skipping the objects
+// filtered out by their age. Errors of fn are printed with errMsg.
+func forEachObject(urlStr string, isRecursive bool, olderThan, newerThan, errMsg string, fn func(clnt Client, content *clientContent) *probe.Error) error {
+	clnt, err := newClient(urlStr)
+	fatalIf(err.Trace(urlStr), "Unable to initialize target `"+urlStr+"`.")
+	alias, _, _ := mustExpandAlias(urlStr)
+
+	var contentCh <-chan *clientContent
+	if isRecursive {
+		contentCh = clnt.List(isRecursive, false, false, DirNone)
+	} else {
+		statCh := make(chan *clientContent, 1)
+		content, err := clnt.Stat(false, false, false, nil)
+		if err != nil {
+			content = &clientContent{Err: err}
+		}
+		statCh <- content
+		close(statCh)
+		contentCh = statCh
+	}
+
+	var cErr error
+	for content := range contentCh {
+		if content.Err != nil {
+			errorIf(content.Err.Trace(urlStr), "Unable to list `"+urlStr+"`.")
+			cErr = exitStatus(globalErrorExitStatus)
+			continue
+		}
+		if content.Type.IsDir() {
+			continue
+		}
+		// Skip objects older than --older-than parameter, if specified
+		if olderThan != "" && isOlder(content.Time, olderThan) {
+			continue
+		}
+		// Skip objects newer than --newer-than parameter, if specified
+		if newerThan != "" && isNewer(content.Time, newerThan) {
+			continue
+		}
+
+		objectURL := content.URL.String()
+		objectClnt, err := newClientFromAlias(alias, objectURL)
+		if err == nil {
+			err = fn(objectClnt, content)
+		}
+		if err != nil {
+			errorIf(err.Trace(objectURL), errMsg+" `"+objectURL+"`.")
+			cErr = exitStatus(globalErrorExitStatus)
+		}
+	}
+	return cErr
+}
+
 // putTargetRetention sets retention headers if any
 func putTargetRetention(ctx context.Context, alias string, urlStr string, metadata map[string]string) *probe.Error {
 	targetClnt, err := newClientFromAlias(alias, urlStr)
@@ -234,7 +308,7 @@ func putTargetRetention(ctx context.Context, alias string, urlStr string, metada
 			retainUntilDate = t.UTC()
 		}
 	}
-	if err := targetClnt.PutObjectRetention(&lockMode, &retainUntilDate); err != nil {
+	if err := targetClnt.PutObjectRetention("", &lockMode, &retainUntilDate, false); err != nil {
 		return err.Trace(alias, urlStr)
 	}
 	return nil
@@ -254,16 +328,20 @@ func putTargetStream(ctx context.Context, alias string, urlStr string, reader io
 }
 
 // putTargetStreamWithURL writes to URL from reader. If length=-1, read until EOF.
-func putTargetStreamWithURL(urlStr string, reader io.Reader, size int64, sse encrypt.ServerSide) (int64, *probe.Error) {
+// The content type guessed from URL is added to metadata, which may be nil.
+func putTargetStreamWithURL(urlStr string, reader io.Reader, size int64, metadata map[string]string, sse encrypt.ServerSide) (int64, *probe.Error) {
 	alias, urlStrFull, _, err := expandAlias(urlStr)
 	if err != nil {
 		return 0, err.Trace(alias, urlStr)
 	}
 	contentType := guessURLContentType(urlStr)
-	metadata := map[string]string{
+	putMetadata := map[string]string{
 		"Content-Type": contentType,
 	}
-	return putTargetStream(context.Background(), alias, urlStrFull, reader, size, metadata, nil, sse)
+	for k, v := range metadata {
+		putMetadata[k] = v
+	}
+	return putTargetStream(context.Background(), alias, urlStrFull, reader, size, putMetadata, nil, sse)
 }
 
 // copySourceToTargetURL copies to targetURL from source.
@@ -336,8 +414,9 @@ func uploadSourceToTargetURL(ctx context.Context, urls URLs, progress io.Reader,
 	var err *probe.Error
 	var metadata = map[string]string{}
 
-	// Optimize for server side copy if the host is same.
-	if sourceAlias == targetAlias {
+	// Optimize for server side copy if the host is same, the source
+	// is streamed when it is compressed or decompressed.
+	if sourceAlias == targetAlias && !isUploadTransformed(urls) {
 		for k, v := range urls.SourceContent.UserMetadata {
 			metadata[k] = v
 		}
@@ -386,6 +465,19 @@ func uploadSourceToTargetURL(ctx context.Context, urls URLs, progress io.Reader,
 		for k, v := range urls.TargetContent.UserMetadata {
 			metadata[k] = v
 		}
+		if isUploadTransformed(urls) {
+			// The size of a compressed or decompressed stream is unknown,
+			// the progress is accounted on the source instead.
+			source := struct {
+				io.Reader
+				io.Closer
+			}{hookreader.NewHook(reader, progress), reader}
+			if reader, err = newUploadReader(source, sourceURL.Path, metadata); err != nil {
+				return urls.WithError(err.Trace(sourceURL.String()))
+			}
+			defer reader.Close()
+			length, progress = -1, nil
+		}
 		_, err = putTargetStream(ctx, targetAlias, targetURL.String(), reader, length, filterMetadata(metadata),
 			progress, tgtSSE)
 	}
@@ -396,6 +488,120 @@ func uploadSourceToTargetURL(ctx context.Context, urls URLs, progress io.Reader,
 	return urls.WithError(nil)
 }
 
+// fanOutWriter - duplicates writes to several writers. A failing writer
+// is left out and the others keep receiving the data, an error is only
+// returned once all writers failed.
+type fanOutWriter struct {
+	writers []io.Writer
+	err     error
+}
+
+func newFanOutWriter(writers ...io.Writer) *fanOutWriter {
+	return &fanOutWriter{writers: writers}
+}
+
+func (f *fanOutWriter) Write(p []byte) (int, error) {
+	writers := f.writers[:0]
+	for _, w := range f.writers {
+		if _, e := w.Write(p); e != nil {
+			f.err = e
+			continue
+		}
+		writers = append(writers, w)
+	}
+	f.writers = writers
+	if len(f.writers) == 0 {
+		return 0, f.err
+	}
+	return len(p), nil
+}
+
+// uploadSourceToTargetURLs - copies one source to several targets, the
+// source is read only once for all targets needing a stream copy. The
+// status of each target is returned in the same order as urls, a failure
+// on one target does not interrupt the others.
+func uploadSourceToTargetURLs(ctx context.Context, urls []URLs, progress io.Reader, encKeyDB map[string][]prefixSSEPair) []URLs {
+	results := make([]URLs, len(urls))
+	var streams []int
+	for i, u := range urls {
+		// Server side copies and retention updates do not read the
+		// source, compressed or decompressed uploads read it on their own.
+		if u.SourceAlias == u.TargetAlias || u.SourceContent.Retention || isUploadTransformed(u) {
+			results[i] = uploadSourceToTargetURL(ctx, u, progress, encKeyDB)
+			continue
+		}
+		streams = append(streams, i)
+	}
+	switch len(streams) {
+	case 0:
+		return results
+	case 1:
+		results[streams[0]] = uploadSourceToTargetURL(ctx, urls[streams[0]], progress, encKeyDB)
+		return results
+	}
+
+	sourceAlias := urls[streams[0]].SourceAlias
+	sourceURL := urls[streams[0]].SourceContent.URL
+	length := urls[streams[0]].SourceContent.Size
+	sourcePath := filepath.ToSlash(filepath.Join(sourceAlias, sourceURL.Path))
+	srcSSE := getSSE(sourcePath, encKeyDB[sourceAlias])
+
+	reader, metadata, err := getSourceStream(sourceAlias, sourceURL.String(), true, srcSSE)
+	if err != nil {
+		for _, i := range streams {
+			results[i] = urls[i].WithError(err.Trace(sourceURL.String()))
+		}
+		return results
+	}
+	defer reader.Close()
+
+	var wg sync.WaitGroup
+	writers := make([]io.Writer, len(streams))
+	pipeWriters := make([]*io.PipeWriter, len(streams))
+	for j, i := range streams {
+		pipeReader, pipeWriter := io.Pipe()
+		writers[j], pipeWriters[j] = pipeWriter, pipeWriter
+
+		u := urls[i]
+		targetPath := filepath.ToSlash(filepath.Join(u.TargetAlias, u.TargetContent.URL.Path))
+		tgtSSE := getSSE(targetPath, encKeyDB[u.TargetAlias])
+		targetMetadata := make(map[string]string)
+		for k, v := range metadata {
+			targetMetadata[k] = v
+		}
+		// Get metadata and userMetadata from target content as well
+		for k, v := range u.TargetContent.Metadata {
+			targetMetadata[k] = v
+		}
+		for k, v := range u.TargetContent.UserMetadata {
+			targetMetadata[k] = v
+		}
+
+		wg.Add(1)
+		go func(i int, u URLs) {
+			defer wg.Done()
+			_, err := putTargetStream(ctx, u.TargetAlias, u.TargetContent.URL.String(), pipeReader, length,
+				filterMetadata(targetMetadata), progress, tgtSSE)
+			if err != nil {
+				results[i] = u.WithError(err.Trace(sourceURL.String()))
+				// Leave this target out, the others go on.
+				pipeReader.CloseWithError(err.ToGoError())
+				return
+			}
+			results[i] = u.WithError(nil)
+			pipeReader.Close()
+		}(i, u)
+	}
+
+	_, e := io.Copy(newFanOutWriter(writers...), reader)
+	for _, pipeWriter := range pipeWriters {
+		// Targets still reading see the end of the source, or its error.
+		pipeWriter.CloseWithError(e)
+	}
+	wg.Wait()
+	return results
+}
+
 // newClientFromAlias gives a new client interface for matching
 // alias entry in the mc config file. If no matching host config entry
 // is found, fs client is returned.
@@ -17,7 +17,9 @@
 package cmd
 
 import (
+	"bytes"
 	"errors"
+	"io"
 	"reflect"
 	"testing"
 )
@@ -68,3 +70,40 @@ func TestGetDecodedKey(t *testing.T) {
 		}
 	}
 }
+
+// failingWriter fails once more than limit bytes were written.
+type failingWriter struct {
+	limit   int
+	written int
+}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	if w.written+len(p) > w.limit {
+		return 0, io.ErrShortWrite
+	}
+	w.written += len(p)
+	return len(p), nil
+}
+
+func TestFanOutWriter(t *testing.T) {
+	var first, second bytes.Buffer
+	failing := &failingWriter{limit: 4}
+	w := newFanOutWriter(&first, failing, &second)
+
+	for _, chunk := range []string{"abc", "def", "ghi"} {
+		if _, e := w.Write([]byte(chunk)); e != nil {
+			t.Fatalf("Unexpected error: %v", e)
+		}
+	}
+	if first.String() != "abcdefghi" || second.String() != "abcdefghi" {
+		t.Fatalf("Expected all data on healthy writers, got %q and %q", first.String(), second.String())
+	}
+	if failing.written != 3 {
+		t.Fatalf("Expected the failing writer to be left out after its failure, got %d bytes", failing.written)
+	}
+
+	w = newFanOutWriter(&failingWriter{})
+	if _, e := w.Write([]byte("abc")); e != io.ErrShortWrite {
+		t.Fatalf("Expected an error once all writers failed, got %v", e)
+	}
+}
@@ -0,0 +1,281 @@
+/*
+ * MinIO Client (C) 2019 MinIO, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/probe"
+	minio "github.com/minio/minio-go/v6"
+)
+
+// compressionZSTD - zstd compressed objects, which are not supported by S3 Select.
+const compressionZSTD minio.SelectCompressionType = "ZSTD"
+
+// mcCompressionKey - metadata recording the codec of objects compressed by mc.
+const mcCompressionKey = "X-Amz-Meta-Mc-Compression"
+
+// Metadata recording the size and modification time of the source of
+// an object compressed or decompressed by mirror, the object is compared
+// with its source through them since their sizes differ.
+const (
+	mcSourceSizeKey  = "X-Amz-Meta-Mc-Source-Size"
+	mcSourceMtimeKey = "X-Amz-Meta-Mc-Source-Mtime"
+)
+
+// Target metadata requesting the compression or the decompression of
+// an upload, they are consumed by uploadSourceToTargetURL.
+const (
+	compressMetadataKey   = "mc-compress"
+	decompressMetadataKey = "mc-decompress"
+)
+
+// Number of bytes at the start of a stream holding the magic bytes
+// of its compression.
+const compressionMagicSize = 10
+
+// Magic bytes at the start of compressed streams.
+var compressionMagics = []struct {
+	magic           []byte
+	compressionType minio.SelectCompressionType
+}{
+	{[]byte{0x1f, 0x8b}, minio.SelectCompressionGZIP},
+	{[]byte{0x28, 0xb5, 0x2f, 0xfd}, compressionZSTD},
+}
+
+// Magic bytes of the first block and of the end of a bzip2 stream,
+// one of which follows its "BZh" header and block size.
+var bzip2BlockMagics = [][]byte{
+	{0x31, 0x41, 0x59, 0x26, 0x53, 0x59},
+	{0x17, 0x72, 0x45, 0x38, 0x50, 0x90},
+}
+
+// compressionTypeFromMetadata - returns the compression of an object
+// from its extension, Content-Encoding or Content-Type.
+func compressionTypeFromMetadata(object string, metadata map[string]string) minio.SelectCompressionType {
+	if cType := selectCompressionType(SelectObjectOpts{}, object); cType != minio.SelectCompressionNONE {
+		return cType
+	}
+	switch strings.ToLower(filepath.Ext(object)) {
+	case ".zst", ".zstd":
+		return compressionZSTD
+	}
+	for _, header := range []string{"Content-Encoding", mcCompressionKey, "Content-Type"} {
+		value := strings.ToLower(metadata[header])
+		switch {
+		case strings.Contains(value, "gzip"):
+			return minio.SelectCompressionGZIP
+		case strings.Contains(value, "bzip"):
+			return minio.SelectCompressionBZIP
+		case strings.Contains(value, "zstd"):
+			return compressionZSTD
+		}
+	}
+	return minio.SelectCompressionNONE
+}
+
+// compressionTypeFromMagic - returns the compression of a stream from
+// the magic bytes at its start.
+func compressionTypeFromMagic(header []byte) minio.SelectCompressionType {
+	for _, m := range compressionMagics {
+		if bytes.HasPrefix(header, m.magic) {
+			return m.compressionType
+		}
+	}
+	// Text starting with "BZh" is not mistaken for bzip2.
+	if len(header) >= compressionMagicSize && bytes.HasPrefix(header, []byte("BZh")) &&
+		header[3] >= '1' && header[3] <= '9' {
+		for _, magic := range bzip2BlockMagics {
+			if bytes.Equal(header[4:compressionMagicSize], magic) {
+				return minio.SelectCompressionBZIP
+			}
+		}
+	}
+	return minio.SelectCompressionNONE
+}
+
+// zstdReadCloser - closes the zstd decoder along with the stream.
+type zstdReadCloser struct {
+	*zstd.Decoder
+	io.Closer
+}
+
+func (z zstdReadCloser) Close() error {
+	z.Decoder.Close()
+	return z.Closer.Close()
+}
+
+// newDecompressReader - returns a reader decompressing r, the compression
+// is detected from the name and metadata of the object or else from the
+// magic bytes at the start of r. Uncompressed streams are returned as is.
+func newDecompressReader(r io.ReadCloser, object string, metadata map[string]string) (io.ReadCloser, *probe.Error) {
+	br := bufio.NewReader(r)
+	cType := compressionTypeFromMetadata(object, metadata)
+	if cType == minio.SelectCompressionNONE {
+		// Short streams have no magic bytes, a read error is
+		// returned by the next read.
+		header, _ := br.Peek(compressionMagicSize)
+		cType = compressionTypeFromMagic(header)
+	}
+
+	switch cType {
+	case minio.SelectCompressionGZIP:
+		gr, e := gzip.NewReader(br)
+		if e != nil {
+			return nil, probe.NewError(e)
+		}
+		return struct {
+			io.Reader
+			io.Closer
+		}{gr, r}, nil
+	case minio.SelectCompressionBZIP:
+		return struct {
+			io.Reader
+			io.Closer
+		}{bzip2.NewReader(br), r}, nil
+	case compressionZSTD:
+		zr, e := zstd.NewReader(br)
+		if e != nil {
+			return nil, probe.NewError(e)
+		}
+		return zstdReadCloser{zr, r}, nil
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{br, r}, nil
+}
+
+// isCompressedContentType - returns true if objects of ctype are
+// decompressed without --decompress.
+func isCompressedContentType(ctype string) bool {
+	return strings.Contains(ctype, "gzip") || strings.Contains(ctype, "bzip")
+}
+
+// newContentTypeDecompressReader - returns a reader decompressing r when
+// ctype is a 'gzip' or 'bzip2' Content-Type, r is closed on error.
+func newContentTypeDecompressReader(r io.ReadCloser, ctype string) (io.ReadCloser, *probe.Error) {
+	switch {
+	case strings.Contains(ctype, "gzip"):
+		gr, e := gzip.NewReader(r)
+		if e != nil {
+			r.Close()
+			return nil, probe.NewError(e)
+		}
+		return struct {
+			io.Reader
+			io.Closer
+		}{gr, r}, nil
+	case strings.Contains(ctype, "bzip"):
+		return struct {
+			io.Reader
+			io.Closer
+		}{bzip2.NewReader(r), r}, nil
+	}
+	return r, nil
+}
+
+// checkCompressSyntax - validates the --compress and --decompress flags.
+func checkCompressSyntax(ctx *cli.Context) {
+	switch ctx.String("compress") {
+	case "", "gzip", "zstd":
+	default:
+		fatalIf(errInvalidArgument().Trace(ctx.String("compress")), "Invalid compression `"+ctx.String("compress")+"`, valid values are gzip and zstd.")
+	}
+	if ctx.String("compress") != "" && ctx.Bool("decompress") {
+		fatalIf(errInvalidArgument(), "--compress cannot be used with --decompress.")
+	}
+}
+
+// compressReadCloser - closes the compressed stream along with its source.
+type compressReadCloser struct {
+	*io.PipeReader
+	source io.Closer
+}
+
+func (c compressReadCloser) Close() error {
+	c.PipeReader.Close()
+	return c.source.Close()
+}
+
+// newCompressReader - returns a reader of r compressed with codec, the
+// codec is recorded in metadata when it is not nil.
+func newCompressReader(r io.Reader, codec string, metadata map[string]string) *io.PipeReader {
+	if metadata != nil {
+		metadata["Content-Encoding"] = codec
+		metadata[mcCompressionKey] = codec
+	}
+	pipeReader, pipeWriter := io.Pipe()
+	go func() {
+		var w io.WriteCloser
+		var e error
+		switch codec {
+		case "zstd":
+			w, e = zstd.NewWriter(pipeWriter)
+		default:
+			w = gzip.NewWriter(pipeWriter)
+		}
+		if e == nil {
+			if _, e = io.Copy(w, r); e == nil {
+				e = w.Close()
+			}
+		}
+		// A closed reader stops the compression as well.
+		pipeWriter.CloseWithError(e)
+	}()
+	return pipeReader
+}
+
+// newUploadReader - compresses or decompresses the source stream of an
+// upload as requested by the metadata of its target, the metadata of
+// the target is updated with the codec.
+func newUploadReader(reader io.ReadCloser, object string, metadata map[string]string) (io.ReadCloser, *probe.Error) {
+	codec, decompress := metadata[compressMetadataKey], metadata[decompressMetadataKey] != ""
+	delete(metadata, compressMetadataKey)
+	delete(metadata, decompressMetadataKey)
+	switch {
+	case codec != "":
+		return compressReadCloser{newCompressReader(reader, codec, metadata), reader}, nil
+	case decompress:
+		r, err := newDecompressReader(reader, object, metadata)
+		if err != nil {
+			return nil, err.Trace(object)
+		}
+		// The uploaded content is not compressed anymore.
+		delete(metadata, "Content-Encoding")
+		delete(metadata, mcCompressionKey)
+		return r, nil
+	}
+	return reader, nil
+}
+
+// isUploadTransformed - returns true if the source of urls is compressed
+// or decompressed while it is uploaded.
+func isUploadTransformed(urls URLs) bool {
+	if urls.TargetContent == nil {
+		return false
+	}
+	return urls.TargetContent.Metadata[compressMetadataKey] != "" || urls.TargetContent.Metadata[decompressMetadataKey] != ""
+}
@@ -0,0 +1,204 @@
+/*
+ * MinIO Client (C) 2019 MinIO, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+	minio "github.com/minio/minio-go/v6"
+)
+
+func TestCompressionTypeFromMetadata(t *testing.T) {
+	testCases := []struct {
+		object          string
+		metadata        map[string]string
+		compressionType minio.SelectCompressionType
+	}{
+		{"logs/a.log.gz", nil, minio.SelectCompressionGZIP},
+		{"logs/a.log.bz2", nil, minio.SelectCompressionBZIP},
+		{"logs/a.log.zst", nil, compressionZSTD},
+		{"logs/a.log", map[string]string{"Content-Encoding": "gzip"}, minio.SelectCompressionGZIP},
+		{"logs/a.log", map[string]string{"Content-Encoding": "zstd"}, compressionZSTD},
+		{"logs/a", map[string]string{"Content-Type": "application/x-bzip2"}, minio.SelectCompressionBZIP},
+		{"logs/a.log", map[string]string{"Content-Type": "text/plain"}, minio.SelectCompressionNONE},
+	}
+
+	for i, testCase := range testCases {
+		cType := compressionTypeFromMetadata(testCase.object, testCase.metadata)
+		if cType != testCase.compressionType {
+			t.Fatalf("Test %d: expected %s, found %s", i+1, testCase.compressionType, cType)
+		}
+	}
+}
+
+func TestCompressionTypeFromMagic(t *testing.T) {
+	testCases := []struct {
+		header          []byte
+		compressionType minio.SelectCompressionType
+	}{
+		{[]byte{0x1f, 0x8b, 0x08, 0x00}, minio.SelectCompressionGZIP},
+		{[]byte{0x28, 0xb5, 0x2f, 0xfd, 0x24}, compressionZSTD},
+		{[]byte("BZh91AY&SY\x6b\x5f"), minio.SelectCompressionBZIP},
+		// Empty bzip2 stream.
+		{[]byte{0x42, 0x5a, 0x68, 0x39, 0x17, 0x72, 0x45, 0x38, 0x50, 0x90}, minio.SelectCompressionBZIP},
+		{[]byte("BZh is not a bzip2 header"), minio.SelectCompressionNONE},
+		{[]byte("BZh01AY&SY"), minio.SelectCompressionNONE},
+		{[]byte("BZh9"), minio.SelectCompressionNONE},
+		{[]byte("hello"), minio.SelectCompressionNONE},
+		{nil, minio.SelectCompressionNONE},
+	}
+
+	for i, testCase := range testCases {
+		cType := compressionTypeFromMagic(testCase.header)
+		if cType != testCase.compressionType {
+			t.Fatalf("Test %d: expected %s, found %s", i+1, testCase.compressionType, cType)
+		}
+	}
+}
+
+func TestDecompressReader(t *testing.T) {
+	data := []byte("hello\nworld\n")
+
+	var gzipped bytes.Buffer
+	gw := gzip.NewWriter(&gzipped)
+	gw.Write(data)
+	gw.Close()
+
+	zw, e := zstd.NewWriter(nil)
+	if e != nil {
+		t.Fatal(e)
+	}
+	zstded := zw.EncodeAll(data, nil)
+
+	bzipped := []byte{
+		0x42, 0x5a, 0x68, 0x39, 0x31, 0x41, 0x59, 0x26, 0x53, 0x59, 0x6b, 0x5f, 0xb1, 0xdd, 0x00, 0x00,
+		0x02, 0x41, 0x80, 0x00, 0x10, 0x06, 0x44, 0x90, 0x80, 0x20, 0x00, 0x31, 0x0c, 0x08, 0x21, 0xa3,
+		0x69, 0x08, 0x07, 0x23, 0xae, 0x87, 0x8b, 0xb9, 0x22, 0x9c, 0x28, 0x48, 0x35, 0xaf, 0xd8, 0xee,
+		0x80,
+	}
+
+	testCases := []struct {
+		object string
+		input  []byte
+	}{
+		{"a.gz", gzipped.Bytes()},
+		// Detected from the magic bytes.
+		{"a", gzipped.Bytes()},
+		{"a.zst", zstded},
+		{"a", zstded},
+		{"a", bzipped},
+		{"a", data},
+		{"a", nil},
+	}
+
+	for i, testCase := range testCases {
+		reader, err := newDecompressReader(ioutil.NopCloser(bytes.NewReader(testCase.input)), testCase.object, nil)
+		if err != nil {
+			t.Fatalf("Test %d: %v", i+1, err)
+		}
+		output, e := ioutil.ReadAll(reader)
+		reader.Close()
+		if e != nil {
+			t.Fatalf("Test %d: %v", i+1, e)
+		}
+		expected := data
+		if testCase.input == nil {
+			expected = nil
+		}
+		if !bytes.Equal(output, expected) {
+			t.Fatalf("Test %d: expected `%s`, found `%s`", i+1, expected, output)
+		}
+	}
+}
+
+func TestContentTypeDecompressReader(t *testing.T) {
+	data := []byte("hello\nworld\n")
+
+	var gzipped bytes.Buffer
+	gw := gzip.NewWriter(&gzipped)
+	gw.Write(data)
+	gw.Close()
+
+	testCases := []struct {
+		ctype    string
+		input    []byte
+		expected []byte
+	}{
+		{"application/gzip", gzipped.Bytes(), data},
+		{"application/x-gzip", gzipped.Bytes(), data},
+		// Other objects are returned as is.
+		{"application/octet-stream", gzipped.Bytes(), gzipped.Bytes()},
+		{"text/plain", data, data},
+	}
+
+	for i, testCase := range testCases {
+		reader, err := newContentTypeDecompressReader(ioutil.NopCloser(bytes.NewReader(testCase.input)), testCase.ctype)
+		if err != nil {
+			t.Fatalf("Test %d: %v", i+1, err)
+		}
+		output, e := ioutil.ReadAll(reader)
+		reader.Close()
+		if e != nil {
+			t.Fatalf("Test %d: %v", i+1, e)
+		}
+		if !bytes.Equal(output, testCase.expected) {
+			t.Fatalf("Test %d: expected `%s`, found `%s`", i+1, testCase.expected, output)
+		}
+	}
+}
+
+func TestUploadReader(t *testing.T) {
+	data := bytes.Repeat([]byte("hello world\n"), 1000)
+
+	for _, codec := range []string{"gzip", "zstd"} {
+		metadata := map[string]string{compressMetadataKey: codec}
+		reader, err := newUploadReader(ioutil.NopCloser(bytes.NewReader(data)), "a.log", metadata)
+		if err != nil {
+			t.Fatalf("%s: %v", codec, err)
+		}
+		compressed, e := ioutil.ReadAll(reader)
+		reader.Close()
+		if e != nil {
+			t.Fatalf("%s: %v", codec, e)
+		}
+		if metadata["Content-Encoding"] != codec || metadata[mcCompressionKey] != codec || metadata[compressMetadataKey] != "" {
+			t.Fatalf("%s: unexpected metadata %v", codec, metadata)
+		}
+
+		// The codec recorded in the metadata is removed on decompression.
+		metadata[decompressMetadataKey] = "true"
+		reader, err = newUploadReader(ioutil.NopCloser(bytes.NewReader(compressed)), "a.log", metadata)
+		if err != nil {
+			t.Fatalf("%s: %v", codec, err)
+		}
+		output, e := ioutil.ReadAll(reader)
+		reader.Close()
+		if e != nil {
+			t.Fatalf("%s: %v", codec, e)
+		}
+		if !bytes.Equal(output, data) {
+			t.Fatalf("%s: decompressed data differs", codec)
+		}
+		if len(metadata) != 0 {
+			t.Fatalf("%s: unexpected metadata %v", codec, metadata)
+		}
+	}
+}
@@ -69,6 +69,34 @@ var (
 			Name:  "preserve, a",
 			Usage: "preserve filesystem attributes (mode, ownership, timestamps)",
 		},
+		cli.StringFlag{
+			Name:  "compress",
+			Usage: "compress objects with 'gzip' or 'zstd' before uploading them",
+		},
+		cli.BoolFlag{
+			Name:  "decompress",
+			Usage: "decompress 'gzip', 'bzip2' and 'zstd' compressed objects while copying them",
+		},
+		cli.StringSliceFlag{
+			Name:  "exclude",
+			Usage: "exclude object(s) that match specified object name pattern",
+		},
+		cli.StringSliceFlag{
+			Name:  "include",
+			Usage: "include only object(s) that match specified object name pattern",
+		},
+		cli.StringSliceFlag{
+			Name:  "exclude-from",
+			Usage: "exclude object(s) that match any pattern listed in the specified file",
+		},
+		cli.StringSliceFlag{
+			Name:  "include-from",
+			Usage: "include only object(s) that match any pattern listed in the specified file",
+		},
+		cli.StringSliceFlag{
+			Name:  "target",
+			Usage: "copy to an additional target in the same pass, reading each source only once",
+		},
 	}
 )
 
@@ -141,6 +169,23 @@ EXAMPLES:
 	  
   15. Copy a text file to an object storage and preserve the file system attribute as metadata.
       {{.Prompt}} {{.HelpName}} -a myobject.txt play/mybucket
+
+  16. Copy a local folder recursively to MinIO cloud storage, skipping all *.tmp files and the
+      patterns listed in a file. Rules in a '.mcignore' file at the root of the folder are honored as well.
+      {{.Prompt}} {{.HelpName}} --recursive --exclude "*.tmp" --exclude-from ~/build-excludes.txt build/ play/artifacts/
+
+  17. Copy only the *.jpg objects of a bucket recursively to a local folder.
+      {{.Prompt}} {{.HelpName}} --recursive --include "*.jpg" play/photos/ ~/photos/
+
+  18. Copy a folder recursively to two sites at once, each object is read once and sent to both sites.
+      {{.Prompt}} {{.HelpName}} --recursive --target site2/archive/ backup/2015/ site1/archive/
+
+  19. Copy a folder of logs recursively to MinIO cloud storage, compressing each file with zstd.
+      The codec is recorded in the Content-Encoding of the objects.
+      {{.Prompt}} {{.HelpName}} --recursive --compress zstd /var/log/app/ play/logs/app/
+
+  20. Copy compressed objects recursively to a local folder, restoring their original content.
+      {{.Prompt}} {{.HelpName}} --recursive --decompress play/logs/app/ /tmp/app-logs/
 `,
 }
 
@@ -181,13 +226,8 @@ type ProgressReader interface {
 	Progress
 }
 
-// doCopy - Copy a singe file from source to destination
-func doCopy(ctx context.Context, cpURLs URLs, pg ProgressReader, encKeyDB map[string][]prefixSSEPair) URLs {
-	if cpURLs.Error != nil {
-		cpURLs.Error = cpURLs.Error.Trace()
-		return cpURLs
-	}
-
+// printCopyMessage - reports the object about to be copied.
+func printCopyMessage(cpURLs URLs, pg ProgressReader) {
 	sourceAlias := cpURLs.SourceAlias
 	sourceURL := cpURLs.SourceContent.URL
 	targetAlias := cpURLs.TargetAlias
@@ -207,9 +247,33 @@ func doCopy(ctx context.Context, cpURLs URLs, pg ProgressReader, encKeyDB map[st
 			TotalSize:  cpURLs.TotalSize,
 		})
 	}
+}
+
+// doCopy - Copy a singe file from source to destination
+func doCopy(ctx context.Context, cpURLs URLs, pg ProgressReader, encKeyDB map[string][]prefixSSEPair) URLs {
+	if cpURLs.Error != nil {
+		cpURLs.Error = cpURLs.Error.Trace()
+		return cpURLs
+	}
+
+	printCopyMessage(cpURLs, pg)
 	return uploadSourceToTargetURL(ctx, cpURLs, pg, encKeyDB)
 }
 
+// doCopyToTargets - Copy a single file to several targets, reading it
+// only once. The status of all targets but the last one is sent on
+// statusCh, the last one is returned.
+func doCopyToTargets(ctx context.Context, cpURLs []URLs, pg ProgressReader, encKeyDB map[string][]prefixSSEPair, statusCh chan<- URLs) URLs {
+	for _, u := range cpURLs {
+		printCopyMessage(u, pg)
+	}
+	results := uploadSourceToTargetURLs(ctx, cpURLs, pg, encKeyDB)
+	for _, result := range results[:len(results)-1] {
+		statusCh <- result
+	}
+	return results[len(results)-1]
+}
+
 // doCopyFake - Perform a fake copy to update the progress bar appropriately.
 func doCopyFake(cpURLs URLs, pg Progress) URLs {
 	if progressReader, ok := pg.(*progressBar); ok {
@@ -231,6 +295,9 @@ func doPrepareCopyURLs(session *sessionV8, trapCh <-chan bool, cancelCopy contex
 	// Access recursive flag inside the session header.
 	isRecursive := session.Header.CommandBoolFlags["recursive"]
 
+	// Objects are accounted once per target.
+	numTargets := int64(1 + len(session.Header.CommandStringSliceFlags["target"]))
+
 	olderThan := session.Header.CommandStringFlags["older-than"]
 	newerThan := session.Header.CommandStringFlags["newer-than"]
 	encryptKeys := session.Header.CommandStringFlags["encrypt-key"]
@@ -238,6 +305,11 @@ func doPrepareCopyURLs(session *sessionV8, trapCh <-chan bool, cancelCopy contex
 	encKeyDB, err := parseAndValidateEncryptionKeys(encryptKeys, encrypt)
 	fatalIf(err, "Unable to parse encryption keys.")
 
+	filter := urlFilter{
+		includeOptions: session.Header.CommandStringSliceFlags["include"],
+		excludeOptions: session.Header.CommandStringSliceFlags["exclude"],
+	}
+
 	// Create a session data file to store the processed URLs.
 	dataFP := session.NewDataWriter()
 
@@ -245,7 +317,7 @@ func doPrepareCopyURLs(session *sessionV8, trapCh <-chan bool, cancelCopy contex
 	if !globalQuiet && !globalJSON { // set up progress bar
 		scanBar = scanBarFactory()
 	}
-	URLsCh := prepareCopyURLs(sourceURLs, targetURL, isRecursive, encKeyDB)
+	URLsCh := prepareCopyURLs(sourceURLs, targetURL, isRecursive, filter, encKeyDB)
 	done := false
 	for !done {
 		select {
@@ -288,8 +360,8 @@ func doPrepareCopyURLs(session *sessionV8, trapCh <-chan bool, cancelCopy contex
 				scanBar(cpURLs.SourceContent.URL.String())
 			}
 
-			totalBytes += cpURLs.SourceContent.Size
-			totalObjects++
+			totalBytes += cpURLs.SourceContent.Size * numTargets
+			totalObjects += numTargets
 		case <-trapCh:
 			cancelCopy()
 			// Print in new line and adjust to top so that we don't print over the ongoing scan bar
@@ -333,6 +405,10 @@ func doCopySession(session *sessionV8, encKeyDB map[string][]prefixSSEPair) erro
 	var quitCh = make(chan struct{})
 	var statusCh = make(chan URLs)
 
+	// Additional targets, copied to along with the last argument.
+	targetURL := session.Header.CommandArgs[len(session.Header.CommandArgs)-1]
+	extraTargetURLs := session.Header.CommandStringSliceFlags["target"]
+
 	parallel, queueCh := newParallelManager(statusCh)
 
 	go func() {
@@ -385,6 +461,14 @@ func doCopySession(session *sessionV8, encKeyDB map[string][]prefixSSEPair) erro
 					cpURLs.TargetContent.Metadata["X-Amz-Storage-Class"] = session.Header.CommandStringFlags["storage-class"]
 				}
 
+				// Check and handle compression if passed in command line args
+				if codec := session.Header.CommandStringFlags["compress"]; codec != "" {
+					cpURLs.TargetContent.Metadata[compressMetadataKey] = codec
+				}
+				if session.Header.CommandBoolFlags["decompress"] {
+					cpURLs.TargetContent.Metadata[decompressMetadataKey] = "true"
+				}
+
 				// Check and handle metadata if passed in command line args
 				if len(session.Header.UserMetaData) != 0 {
 					for metaDataKey, metaDataVal := range session.Header.UserMetaData {
@@ -404,6 +488,40 @@ func doCopySession(session *sessionV8, encKeyDB map[string][]prefixSSEPair) erro
 						cpURLs.TargetContent.Metadata["mc-attrs"] = attrValue
 					}
 				}
+				if len(extraTargetURLs) > 0 {
+					cpGroup := []URLs{cpURLs}
+					for _, extraTargetURL := range extraTargetURLs {
+						cpGroup = append(cpGroup, makeCopyContentForTarget(cpURLs, targetURL, extraTargetURL))
+					}
+					// Verify if previously copied, notify progress bar.
+					// Targets which failed while the others succeeded are
+					// copied again.
+					if isCopied(cpURLs.SourceContent.URL.String()) {
+						var retryGroup []URLs
+						for _, u := range cpGroup {
+							if session.isFailedCopy(u) {
+								retryGroup = append(retryGroup, u)
+							} else {
+								doCopyFake(u, pg)
+							}
+						}
+						if len(retryGroup) == 0 {
+							queueCh <- func() URLs {
+								return cpURLs
+							}
+						} else {
+							queueCh <- func() URLs {
+								return doCopyToTargets(ctx, retryGroup, pg, encKeyDB, statusCh)
+							}
+						}
+					} else {
+						queueCh <- func() URLs {
+							return doCopyToTargets(ctx, cpGroup, pg, encKeyDB, statusCh)
+						}
+					}
+					continue
+				}
+
 				// Verify if previously copied, notify progress bar.
 				if isCopied(cpURLs.SourceContent.URL.String()) {
 					queueCh <- func() URLs {
@@ -437,7 +555,10 @@ loop:
 				break loop
 			}
 			if cpURLs.Error == nil {
-				session.Header.LastCopied = cpURLs.SourceContent.URL.String()
+				// A retried copy is already behind the last copied source.
+				if !session.removeFailedCopy(cpURLs) {
+					session.Header.LastCopied = cpURLs.SourceContent.URL.String()
+				}
 				session.Save()
 			} else {
 
@@ -449,6 +570,15 @@ loop:
 				if !globalQuiet && !globalJSON {
 					console.Eraseline()
 				}
+				if len(extraTargetURLs) > 0 {
+					errorIf(cpURLs.Error.Trace(cpURLs.SourceContent.URL.String(), cpURLs.TargetContent.URL.String()),
+						fmt.Sprintf("Failed to copy `%s` to `%s`.", cpURLs.SourceContent.URL.String(), cpURLs.TargetContent.URL.String()))
+					// A failing target does not stop the others, record it
+					// so that resuming the session copies it again.
+					session.addFailedCopy(cpURLs)
+					session.Save()
+					continue loop
+				}
 				errorIf(cpURLs.Error.Trace(cpURLs.SourceContent.URL.String()),
 					fmt.Sprintf("Failed to copy `%s`.", cpURLs.SourceContent.URL.String()))
 				if isErrIgnored(cpURLs.Error) {
@@ -502,6 +632,10 @@ func mainCopy(ctx *cli.Context) error {
 		fatalIf(err, "Unable to parse attribute %v", ctx.String("attr"))
 	}
 
+	// Parse include and exclude patterns.
+	filter, err := newURLFilter(ctx)
+	fatalIf(err, "Unable to parse include and exclude patterns.")
+
 	// check 'copy' cli arguments.
 	checkCopySyntax(ctx, encKeyDB)
 
@@ -523,7 +657,7 @@ func mainCopy(ctx *cli.Context) error {
 	}
 	sse := ctx.String("encrypt")
 
-	sessionID := getHash("cp", ctx.Args())
+	sessionID := getHash("cp", append(ctx.Args(), ctx.StringSlice("target")...))
 	if ctx.Bool("continue") && isSessionExists(sessionID) {
 		resumeSession(sessionID)
 		return nil
@@ -537,7 +671,12 @@ func mainCopy(ctx *cli.Context) error {
 	session.Header.CommandStringFlags["storage-class"] = storageClass
 	session.Header.CommandStringFlags["encrypt-key"] = sseKeys
 	session.Header.CommandStringFlags["encrypt"] = sse
+	session.Header.CommandStringFlags["compress"] = ctx.String("compress")
+	session.Header.CommandBoolFlags["decompress"] = ctx.Bool("decompress")
 	session.Header.CommandBoolFlags["session"] = ctx.Bool("continue")
+	session.Header.CommandStringSliceFlags["include"] = filter.includeOptions
+	session.Header.CommandStringSliceFlags["exclude"] = filter.excludeOptions
+	session.Header.CommandStringSliceFlags["target"] = ctx.StringSlice("target")
 
 	if ctx.Bool("preserve") {
 		session.Header.CommandBoolFlags["preserve"] = ctx.Bool("preserve")
@@ -553,7 +692,7 @@ func mainCopy(ctx *cli.Context) error {
 	// extract URLs.
 	session.Header.CommandArgs = ctx.Args()
 	e = doCopySession(session, encKeyDB)
-	session.Delete()
+	session.closeOrDelete()
 
 	return e
 }
@@ -17,8 +17,13 @@
 package cmd
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
+
+	"github.com/minio/mc/pkg/probe"
 )
 
 func TestParseMetaData(t *testing.T) {
@@ -61,3 +66,85 @@ func TestParseMetaData(t *testing.T) {
 		}
 	}
 }
+
+func TestCopySessionFailedTarget(t *testing.T) {
+	dir, e := ioutil.TempDir("", "cp-")
+	if e != nil {
+		t.Fatal(e)
+	}
+	defer os.RemoveAll(dir)
+
+	defer func(configDir string) { setMcConfigDir(configDir) }(mcCustomConfigDir)
+	setMcConfigDir(filepath.Join(dir, "config"))
+	// Local paths have no alias to expand.
+	defer func(load func() (*configV9, *probe.Error)) { loadMcConfig = load }(loadMcConfig)
+	loadMcConfig = func() (*configV9, *probe.Error) { return newMcConfig(), nil }
+	defer func(quiet bool) { globalQuiet = quiet }(globalQuiet)
+	globalQuiet = true
+	if err := createSessionDir(); err != nil {
+		t.Fatal(err)
+	}
+
+	source := filepath.Join(dir, "source")
+	if e = os.Mkdir(source, 0700); e != nil {
+		t.Fatal(e)
+	}
+	for _, name := range []string{"a", "b"} {
+		if e = ioutil.WriteFile(filepath.Join(source, name), []byte(name), 0600); e != nil {
+			t.Fatal(e)
+		}
+	}
+	// The extra target is a regular file, copies below it fail.
+	failing := filepath.Join(dir, "failing")
+	if e = ioutil.WriteFile(failing, nil, 0600); e != nil {
+		t.Fatal(e)
+	}
+
+	args := []string{source + "/", filepath.Join(dir, "target") + "/"}
+	session := newSessionV8(getHash("cp", append(args, failing+"/")))
+	session.Header.CommandType = "cp"
+	session.Header.CommandArgs = args
+	session.Header.CommandBoolFlags["recursive"] = true
+	session.Header.CommandStringSliceFlags["target"] = []string{failing + "/"}
+
+	if e = doCopySession(session, nil); e == nil {
+		t.Fatalf("expected the failing target to be reported")
+	}
+	if err := session.closeOrDelete(); err != nil {
+		t.Fatal(err)
+	}
+	if !isSessionExists(session.SessionID) {
+		t.Fatalf("expected the session to be kept to copy the failing target again")
+	}
+	for _, name := range []string{"a", "b"} {
+		if _, e = os.Stat(filepath.Join(dir, "target", name)); e != nil {
+			t.Fatalf("expected `%s` to be copied to the other target: %v", name, e)
+		}
+	}
+
+	// Resuming copies to the target once it is fixed.
+	if e = os.Remove(failing); e != nil {
+		t.Fatal(e)
+	}
+	session, err := loadSessionV8(session.SessionID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(session.Header.FailedCopies) != 2 {
+		t.Fatalf("expected 2 failed copies, got %v", session.Header.FailedCopies)
+	}
+	if e = doCopySession(session, nil); e != nil {
+		t.Fatal(e)
+	}
+	if err = session.closeOrDelete(); err != nil {
+		t.Fatal(err)
+	}
+	if isSessionExists(session.SessionID) {
+		t.Fatalf("expected the session to be removed once all copies succeeded")
+	}
+	for _, name := range []string{"a", "b"} {
+		if _, e = os.Stat(filepath.Join(failing, name)); e != nil {
+			t.Fatalf("expected `%s` to be copied to the fixed target: %v", name, e)
+		}
+	}
+}
@@ -39,6 +39,8 @@ func checkCopySyntax(ctx *cli.Context, encKeyDB map[string][]prefixSSEPair) {
 	tgtURL := URLs[len(URLs)-1]
 	isRecursive := ctx.Bool("recursive")
 
+	checkCompressSyntax(ctx)
+
 	// Verify if source(s) exists.
 	for _, srcURL := range srcURLs {
 		_, _, err := url2Stat(srcURL, false, false, encKeyDB)
@@ -48,10 +50,12 @@ func checkCopySyntax(ctx *cli.Context, encKeyDB map[string][]prefixSSEPair) {
 	}
 
 	// Check if bucket name is passed for URL type arguments.
-	url := newClientURL(tgtURL)
-	if url.Host != "" {
-		if url.Path == string(url.Separator) {
-			fatalIf(errInvalidArgument().Trace(), fmt.Sprintf("Target `%s` does not contain bucket name.", tgtURL))
+	for _, tgtURL := range append([]string{tgtURL}, ctx.StringSlice("target")...) {
+		url := newClientURL(tgtURL)
+		if url.Host != "" {
+			if url.Path == string(url.Separator) {
+				fatalIf(errInvalidArgument().Trace(), fmt.Sprintf("Target `%s` does not contain bucket name.", tgtURL))
+			}
 		}
 	}
 
@@ -61,6 +65,15 @@ func checkCopySyntax(ctx *cli.Context, encKeyDB map[string][]prefixSSEPair) {
 		fatalIf(errInvalidArgument().Trace(), "Unable to guess the type of copy operation.")
 	}
 
+	// Additional targets are copied to the same paths as the target,
+	// they need to be of the same kind.
+	for _, extraTgtURL := range ctx.StringSlice("target") {
+		extraCopyURLsType, err := guessCopyURLType(srcURLs, extraTgtURL, isRecursive, encKeyDB)
+		if err != nil || extraCopyURLsType != copyURLsType {
+			fatalIf(errInvalidArgument().Trace(extraTgtURL), "Target `"+extraTgtURL+"` should be a folder if and only if `"+tgtURL+"` is.")
+		}
+	}
+
 	switch copyURLsType {
 	case copyURLsTypeA: // File -> File.
 		checkCopySyntaxTypeA(srcURLs, tgtURL, encKeyDB)
@@ -149,7 +149,7 @@ func makeCopyContentTypeB(sourceAlias string, sourceContent *clientContent, targ
 
 // SINGLE SOURCE - Type C: copy(d1..., d2) -> []copy(d1/f, d1/d2/f) -> []A
 // prepareCopyRecursiveURLTypeC - prepares target and source clientURLs for copying.
-func prepareCopyURLsTypeC(sourceURL, targetURL string, isRecursive bool, encKeyDB map[string][]prefixSSEPair) <-chan URLs {
+func prepareCopyURLsTypeC(sourceURL, targetURL string, isRecursive bool, filter urlFilter, encKeyDB map[string][]prefixSSEPair) <-chan URLs {
 	// Extract alias before fiddling with the clientURL.
 	sourceAlias, _, _ := mustExpandAlias(sourceURL)
 	// Find alias and expanded clientURL.
@@ -164,6 +164,13 @@ func prepareCopyURLsTypeC(sourceURL, targetURL string, isRecursive bool, encKeyD
 			return
 		}
 
+		// Honor the ignore file at the root of the source folder.
+		filter, err := filter.withIgnoreFile(sourceURL, encKeyDB)
+		if err != nil {
+			copyURLsCh <- URLs{Error: err.Trace(sourceURL)}
+			return
+		}
+
 		isIncomplete := false
 		for sourceContent := range sourceClient.List(isRecursive, isIncomplete, false, DirNone) {
 			if sourceContent.Err != nil {
@@ -177,6 +184,11 @@ func prepareCopyURLsTypeC(sourceURL, targetURL string, isRecursive bool, encKeyD
 				continue
 			}
 
+			// Skip the source object if it is excluded by the filter provided.
+			if filter.isExcluded(copySourceSuffix(sourceClient.GetURL(), sourceContent)) {
+				continue
+			}
+
 			// All OK.. We can proceed. Type B: source is a file, target is a folder and exists.
 			copyURLsCh <- makeCopyContentTypeC(sourceAlias, sourceClient.GetURL(), sourceContent, targetAlias, targetURL, encKeyDB)
 		}
@@ -197,14 +209,55 @@ func makeCopyContentTypeC(sourceAlias string, sourceURL clientURL, sourceContent
 	return makeCopyContentTypeA(sourceAlias, sourceContent, targetAlias, newTargetURL, encKeyDB)
 }
 
+// copySourceSuffix - returns the path of sourceContent relative to the
+// source root, used to match include and exclude patterns.
+func copySourceSuffix(sourceURL clientURL, sourceContent *clientContent) string {
+	suffix := strings.TrimPrefix(sourceContent.URL.Path, sourceURL.Path)
+	suffix = strings.TrimPrefix(suffix, string(sourceURL.Separator))
+	if suffix == "" {
+		// Source is a single file.
+		suffix = filepath.Base(sourceContent.URL.Path)
+	}
+	return suffix
+}
+
+// makeCopyContentForTarget - CopyURLs content for copying the source of
+// cpURLs, prepared for targetURL, to the same path in another target.
+func makeCopyContentForTarget(cpURLs URLs, targetURL, newTargetURL string) URLs {
+	_, targetURL, _ = mustExpandAlias(targetURL)
+	newTargetAlias, newTargetURL, _ := mustExpandAlias(newTargetURL)
+	if suffix := strings.TrimPrefix(cpURLs.TargetContent.URL.String(), targetURL); suffix != "" {
+		newTargetURL = urlJoinPath(newTargetURL, suffix)
+	}
+	targetContent := clientContent{
+		URL:          *newClientURL(newTargetURL),
+		Metadata:     make(map[string]string),
+		UserMetadata: make(map[string]string),
+	}
+	for k, v := range cpURLs.TargetContent.Metadata {
+		targetContent.Metadata[k] = v
+	}
+	for k, v := range cpURLs.TargetContent.UserMetadata {
+		targetContent.UserMetadata[k] = v
+	}
+	return URLs{
+		SourceAlias:   cpURLs.SourceAlias,
+		SourceContent: cpURLs.SourceContent,
+		TargetAlias:   newTargetAlias,
+		TargetContent: &targetContent,
+		TotalCount:    cpURLs.TotalCount,
+		TotalSize:     cpURLs.TotalSize,
+	}
+}
+
 // MULTI-SOURCE - Type D: copy([](f|d...), d) -> []B
 // prepareCopyURLsTypeE - prepares target and source clientURLs for copying.
-func prepareCopyURLsTypeD(sourceURLs []string, targetURL string, isRecursive bool, encKeyDB map[string][]prefixSSEPair) <-chan URLs {
+func prepareCopyURLsTypeD(sourceURLs []string, targetURL string, isRecursive bool, filter urlFilter, encKeyDB map[string][]prefixSSEPair) <-chan URLs {
 	copyURLsCh := make(chan URLs)
 	go func(sourceURLs []string, targetURL string, copyURLsCh chan URLs) {
 		defer close(copyURLsCh)
 		for _, sourceURL := range sourceURLs {
-			for cpURLs := range prepareCopyURLsTypeC(sourceURL, targetURL, isRecursive, encKeyDB) {
+			for cpURLs := range prepareCopyURLsTypeC(sourceURL, targetURL, isRecursive, filter, encKeyDB) {
 				copyURLsCh <- cpURLs
 			}
 		}
@@ -213,7 +266,7 @@ func prepareCopyURLsTypeD(sourceURLs []string, targetURL string, isRecursive boo
 }
 
 // prepareCopyURLs - prepares target and source clientURLs for copying.
-func prepareCopyURLs(sourceURLs []string, targetURL string, isRecursive bool, encKeyDB map[string][]prefixSSEPair) <-chan URLs {
+func prepareCopyURLs(sourceURLs []string, targetURL string, isRecursive bool, filter urlFilter, encKeyDB map[string][]prefixSSEPair) <-chan URLs {
 	copyURLsCh := make(chan URLs)
 	go func(sourceURLs []string, targetURL string, copyURLsCh chan URLs, encKeyDB map[string][]prefixSSEPair) {
 		defer close(copyURLsCh)
@@ -222,15 +275,21 @@ func prepareCopyURLs(sourceURLs []string, targetURL string, isRecursive bool, en
 
 		switch cpType {
 		case copyURLsTypeA:
-			copyURLsCh <- prepareCopyURLsTypeA(sourceURLs[0], targetURL, encKeyDB)
+			cURLs := prepareCopyURLsTypeA(sourceURLs[0], targetURL, encKeyDB)
+			if cURLs.Error != nil || !filter.isExcluded(filepath.Base(cURLs.SourceContent.URL.Path)) {
+				copyURLsCh <- cURLs
+			}
 		case copyURLsTypeB:
-			copyURLsCh <- prepareCopyURLsTypeB(sourceURLs[0], targetURL, encKeyDB)
+			cURLs := prepareCopyURLsTypeB(sourceURLs[0], targetURL, encKeyDB)
+			if cURLs.Error != nil || !filter.isExcluded(filepath.Base(cURLs.SourceContent.URL.Path)) {
+				copyURLsCh <- cURLs
+			}
 		case copyURLsTypeC:
-			for cURLs := range prepareCopyURLsTypeC(sourceURLs[0], targetURL, isRecursive, encKeyDB) {
+			for cURLs := range prepareCopyURLsTypeC(sourceURLs[0], targetURL, isRecursive, filter, encKeyDB) {
 				copyURLsCh <- cURLs
 			}
 		case copyURLsTypeD:
-			for cURLs := range prepareCopyURLsTypeD(sourceURLs, targetURL, isRecursive, encKeyDB) {
+			for cURLs := range prepareCopyURLsTypeD(sourceURLs, targetURL, isRecursive, filter, encKeyDB) {
 				copyURLsCh <- cURLs
 			}
 		default:
@@ -17,8 +17,12 @@
 package cmd
 
 import (
+	"encoding/csv"
 	"fmt"
+	"os"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/fatih/color"
 	"github.com/minio/cli"
@@ -29,7 +33,13 @@ import (
 
 // diff specific flags.
 var (
-	diffFlags = []cli.Flag{}
+	diffFlags = []cli.Flag{
+		cli.StringFlag{
+			Name:  "format",
+			Usage: "print a detailed report, one of 'json', 'csv' or 'summary'",
+		},
+		parallelListFlag,
+	}
 )
 
 // Compute differences in object name, size, and date between two buckets.
@@ -51,6 +61,9 @@ FLAGS:
 DESCRIPTION:
   Diff only calculates differences in object name, size and time. It *DOES NOT* compare objects' contents.
 
+  With '--format', objects are compared with their metadata as well and a detailed report is printed,
+  the exit status is 0 when both folders are similar, 2 when differences are found and 1 on errors.
+
 LEGEND:
   < - object is only in source.
   > - object is only in destination.
@@ -62,6 +75,16 @@ EXAMPLES:
 
   2. Compare two folders on a local filesystem.
      {{.Prompt}} {{.HelpName}} ~/Photos /Media/Backup/Photos
+
+  3. Print the sizes, ETags, modification times, storage classes and differing metadata keys of all
+     differences between two buckets as CSV.
+     {{.Prompt}} {{.HelpName}} --format csv play/photos s3/backup-photos > differences.csv
+
+  4. Fail a CI job when two buckets differ, printing the number of differences by type.
+     {{.Prompt}} {{.HelpName}} --format summary play/releases s3/releases-mirror
+
+  5. Compare two large buckets, listing up to 16 prefixes of each bucket concurrently.
+     {{.Prompt}} {{.HelpName}} --parallel-list 16 play/archive s3/archive
 `,
 }
 
@@ -74,6 +97,10 @@ type diffMessage struct {
 	Error         *probe.Error `json:"error,omitempty"`
 	firstContent  *clientContent
 	secondContent *clientContent
+
+	// keys of the metadata differing between both objects, only
+	// set when objects are compared with their metadata.
+	metadataKeys []string
 }
 
 // String colorized diff message
@@ -103,14 +130,111 @@ func (d diffMessage) JSON() string {
 	d.Status = "success"
 	diffJSONBytes, e := json.MarshalIndent(d, "", " ")
 	fatalIf(probe.NewError(e),
-		"Unable to marshal diff message `"+d.FirstURL+"`, `"+d.SecondURL+"` and `"+string(d.Diff)+"`.")
+		"Unable to marshal diff message `"+d.FirstURL+"`, `"+d.SecondURL+"` and `"+d.Diff.String()+"`.")
 	return string(diffJSONBytes)
 }
 
+// diffObject - one side of a difference in a detailed report.
+type diffObject struct {
+	URL          string    `json:"url"`
+	Size         int64     `json:"size"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified time.Time `json:"lastModified"`
+	StorageClass string    `json:"storageClass,omitempty"`
+}
+
+// diffReportMessage - a difference in a detailed report.
+type diffReportMessage struct {
+	Status       string      `json:"status"`
+	Diff         string      `json:"diff"`
+	First        *diffObject `json:"first,omitempty"`
+	Second       *diffObject `json:"second,omitempty"`
+	MetadataKeys []string    `json:"metadataKeys,omitempty"`
+}
+
+// newDiffObject - returns the details of content, nil if absent.
+func newDiffObject(content *clientContent) *diffObject {
+	if content == nil {
+		return nil
+	}
+	return &diffObject{
+		URL:          content.URL.String(),
+		Size:         content.Size,
+		ETag:         content.ETag,
+		LastModified: content.Time,
+		StorageClass: content.StorageClass,
+	}
+}
+
+// report - returns the details of the difference.
+func (d diffMessage) report() diffReportMessage {
+	return diffReportMessage{
+		Status:       "success",
+		Diff:         d.Diff.String(),
+		First:        newDiffObject(d.firstContent),
+		Second:       newDiffObject(d.secondContent),
+		MetadataKeys: d.metadataKeys,
+	}
+}
+
+// diffCSVHeader - columns of the CSV report.
+var diffCSVHeader = []string{
+	"diff",
+	"first", "firstSize", "firstETag", "firstLastModified", "firstStorageClass",
+	"second", "secondSize", "secondETag", "secondLastModified", "secondStorageClass",
+	"metadataKeys",
+}
+
+// csvRecord - returns the difference as a row of the CSV report.
+func (r diffReportMessage) csvRecord() []string {
+	record := []string{r.Diff}
+	for _, object := range []*diffObject{r.First, r.Second} {
+		if object == nil {
+			record = append(record, "", "", "", "", "")
+			continue
+		}
+		record = append(record, object.URL, strconv.FormatInt(object.Size, 10), object.ETag,
+			object.LastModified.UTC().Format(time.RFC3339), object.StorageClass)
+	}
+	return append(record, strings.Join(r.MetadataKeys, ";"))
+}
+
+// diffSummaryMessage - totals of differences by type.
+type diffSummaryMessage struct {
+	Status string         `json:"status"`
+	Totals map[string]int `json:"totals"`
+	Total  int            `json:"total"`
+}
+
+// String - prints the totals by type in a stable order.
+func (s diffSummaryMessage) String() string {
+	var lines []string
+	for _, d := range []differType{differInFirst, differInSecond, differInType, differInSize, differInMetadata} {
+		if count := s.Totals[d.String()]; count > 0 {
+			lines = append(lines, fmt.Sprintf("%-16s %d", d.String()+":", count))
+		}
+	}
+	lines = append(lines, console.Colorize("DiffMessage", fmt.Sprintf("%-16s %d", "total:", s.Total)))
+	return strings.Join(lines, "\n")
+}
+
+// JSON - jsonified totals.
+func (s diffSummaryMessage) JSON() string {
+	s.Status = "success"
+	summaryJSONBytes, e := json.Marshal(s)
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+	return string(summaryJSONBytes)
+}
+
 func checkDiffSyntax(ctx *cli.Context, encKeyDB map[string][]prefixSSEPair) {
 	if len(ctx.Args()) != 2 {
 		cli.ShowCommandHelpAndExit(ctx, "diff", 1) // last argument is exit code
 	}
+	switch ctx.String("format") {
+	case "", "json", "csv", "summary":
+	default:
+		fatalIf(errInvalidArgument().Trace(ctx.String("format")), "Format should be one of `json`, `csv` or `summary`.")
+	}
 	for _, arg := range ctx.Args() {
 		if strings.TrimSpace(arg) == "" {
 			fatalIf(errInvalidArgument().Trace(ctx.Args()...), "Unable to validate empty argument.")
@@ -146,7 +270,7 @@ func checkDiffSyntax(ctx *cli.Context, encKeyDB map[string][]prefixSSEPair) {
 }
 
 // doDiffMain runs the diff.
-func doDiffMain(firstURL, secondURL string) error {
+func doDiffMain(firstURL, secondURL, format string) error {
 	// Source and targets are always directories
 	sourceSeparator := string(newClientURL(firstURL).Separator)
 	if !strings.HasSuffix(firstURL, sourceSeparator) {
@@ -173,6 +297,10 @@ func doDiffMain(firstURL, secondURL string) error {
 			fmt.Sprintf("Failed to diff '%s' and '%s'", firstURL, secondURL))
 	}
 
+	if format != "" {
+		return doDiffReport(firstClient, secondClient, firstURL, secondURL, format)
+	}
+
 	// Diff first and second urls.
 	for diffMsg := range objectDifference(firstClient, secondClient, firstURL, secondURL, false) {
 		if diffMsg.Error != nil {
@@ -186,6 +314,57 @@ func doDiffMain(firstURL, secondURL string) error {
 	return nil
 }
 
+// doDiffReport prints a detailed report of the differences in format,
+// the exit status tells whether differences were found.
+func doDiffReport(firstClient, secondClient Client, firstURL, secondURL, format string) error {
+	var csvWriter *csv.Writer
+	if format == "csv" {
+		csvWriter = csv.NewWriter(os.Stdout)
+		defer csvWriter.Flush()
+		fatalIf(probe.NewError(csvWriter.Write(diffCSVHeader)), "Unable to write CSV header.")
+	}
+
+	summary := diffSummaryMessage{Totals: make(map[string]int)}
+	var errorDetected bool
+	isMetadata := true
+	for diffMsg := range objectDifference(firstClient, secondClient, firstURL, secondURL, isMetadata) {
+		if diffMsg.Error != nil {
+			errorIf(diffMsg.Error, "Unable to calculate objects difference.")
+			errorDetected = true
+			continue
+		}
+		summary.Totals[diffMsg.Diff.String()]++
+		summary.Total++
+
+		switch format {
+		case "json":
+			reportJSONBytes, e := json.Marshal(diffMsg.report())
+			fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+			console.Println(string(reportJSONBytes))
+		case "csv":
+			fatalIf(probe.NewError(csvWriter.Write(diffMsg.report().csvRecord())), "Unable to write CSV record.")
+		}
+	}
+
+	switch format {
+	case "json":
+		console.Println(summary.JSON())
+	case "summary":
+		printMsg(summary)
+	}
+
+	if errorDetected {
+		return exitStatus(globalErrorExitStatus)
+	}
+	if summary.Total > 0 {
+		return exitStatus(diffFoundExitStatus)
+	}
+	return nil
+}
+
+// Exit status of a detailed diff report when differences are found.
+const diffFoundExitStatus = 2
+
 // mainDiff main for 'diff'.
 func mainDiff(ctx *cli.Context) error {
 	// Parse encryption keys per command.
@@ -194,6 +373,7 @@ func mainDiff(ctx *cli.Context) error {
 
 	// check 'diff' cli arguments.
 	checkDiffSyntax(ctx, encKeyDB)
+	setParallelListFromContext(ctx)
 
 	// Additional command specific theme customization.
 	console.SetColor("DiffMessage", color.New(color.FgGreen, color.Bold))
@@ -202,10 +382,11 @@ func mainDiff(ctx *cli.Context) error {
 	console.SetColor("DiffType", color.New(color.FgMagenta))
 	console.SetColor("DiffSize", color.New(color.FgYellow, color.Bold))
 	console.SetColor("DiffTime", color.New(color.FgYellow, color.Bold))
+	console.SetColor("DiffMetadata", color.New(color.FgYellow, color.Bold))
 
 	URLs := ctx.Args()
 	firstURL := URLs.Get(0)
 	secondURL := URLs.Get(1)
 
-	return doDiffMain(firstURL, secondURL)
+	return doDiffMain(firstURL, secondURL, ctx.String("format"))
 }
@@ -17,6 +17,7 @@
 package cmd
 
 import (
+	"sort"
 	"strings"
 	"time"
 	"unicode/utf8"
@@ -33,7 +34,7 @@ type differType int
 
 const (
 	differInNone     differType = iota // does not differ
-	differInETag                       // differs in ETag
+	_                                  // unused, keeps the values of the JSON output
 	differInSize                       // differs in size
 	differInMetadata                   // differs in metadata
 	differInType                       // differs in type, exfile/directory
@@ -96,6 +97,33 @@ func metadataEqual(m1, m2 map[string]string) bool {
 	return true
 }
 
+// metadataDifference - returns the sorted keys of the metadata and user
+// metadata differing between two objects.
+func metadataDifference(srcCtnt, tgtCtnt *clientContent) []string {
+	keys := make(map[string]struct{})
+	for _, m := range []struct{ m1, m2 map[string]string }{
+		{srcCtnt.Metadata, tgtCtnt.Metadata},
+		{srcCtnt.UserMetadata, tgtCtnt.UserMetadata},
+		{tgtCtnt.Metadata, srcCtnt.Metadata},
+		{tgtCtnt.UserMetadata, srcCtnt.UserMetadata},
+	} {
+		for k, v := range m.m1 {
+			if k == multiMasterETagKey || k == multiMasterSTagKey {
+				continue
+			}
+			if w, ok := m.m2[k]; !ok || w != v {
+				keys[k] = struct{}{}
+			}
+		}
+	}
+	var diffKeys []string
+	for k := range keys {
+		diffKeys = append(diffKeys, k)
+	}
+	sort.Strings(diffKeys)
+	return diffKeys
+}
+
 func objectDifference(sourceClnt, targetClnt Client, sourceURL, targetURL string, isMetadata bool) (diffCh chan diffMessage) {
 	return difference(sourceClnt, targetClnt, sourceURL, targetURL, isMetadata, true, false, DirNone)
 }
@@ -104,6 +132,37 @@ func dirDifference(sourceClnt, targetClnt Client, sourceURL, targetURL string) (
 	return difference(sourceClnt, targetClnt, sourceURL, targetURL, false, false, true, DirFirst)
 }
 
+// contentDifference - compares a source and a target object found at
+// the same path, differInNone is returned when they are similar.
+func contentDifference(srcCtnt, tgtCtnt *clientContent, isMetadata bool) differType {
+	srcType, tgtType := srcCtnt.Type, tgtCtnt.Type
+	if srcType.IsRegular() && !tgtType.IsRegular() ||
+		!srcType.IsRegular() && tgtType.IsRegular() {
+		return differInType
+	}
+	if eTagMatch(srcCtnt, tgtCtnt) {
+		// If ETag matches, only thing that can differ is metadata.
+		if isMetadata &&
+			!metadataEqual(srcCtnt.UserMetadata, tgtCtnt.UserMetadata) &&
+			!metadataEqual(srcCtnt.Metadata, tgtCtnt.Metadata) {
+			// Regular files user requesting additional metadata to same file.
+			return differInMetadata
+		}
+		return differInNone
+	}
+	if (srcType.IsRegular() && tgtType.IsRegular()) && srcCtnt.Size != tgtCtnt.Size {
+		// Regular files differing in size.
+		return differInSize
+	}
+	if isMetadata &&
+		!metadataEqual(srcCtnt.UserMetadata, tgtCtnt.UserMetadata) &&
+		!metadataEqual(srcCtnt.Metadata, tgtCtnt.Metadata) {
+		// Regular files user requesting additional metadata to same file.
+		return differInMetadata
+	}
+	return differInNone
+}
+
 func differenceInternal(sourceClnt, targetClnt Client, sourceURL, targetURL string, isMetadata bool, isRecursive, returnSimilar bool, dirOpt DirOpt, diffCh chan<- diffMessage) *probe.Error {
 	// Set default values for listing.
 	isIncomplete := false // we will not compare any incomplete objects.
@@ -191,10 +250,8 @@ func differenceInternal(sourceClnt, targetClnt Client, sourceURL, targetURL stri
 			continue
 		}
 		if normalizedExpected == normalizedCurrent {
-			srcType, tgtType := srcCtnt.Type, tgtCtnt.Type
-			srcSize, tgtSize := srcCtnt.Size, tgtCtnt.Size
-			if srcType.IsRegular() && !tgtType.IsRegular() ||
-				!srcType.IsRegular() && tgtType.IsRegular() {
+			diff := contentDifference(srcCtnt, tgtCtnt, isMetadata)
+			if diff == differInType {
 				// Type differs. Source is never a directory.
 				diffCh <- diffMessage{
 					FirstURL:      srcCtnt.URL.String(),
@@ -205,52 +262,18 @@ func differenceInternal(sourceClnt, targetClnt Client, sourceURL, targetURL stri
 				}
 				continue
 			}
-			if eTagMatch(srcCtnt, tgtCtnt) {
-				// If ETag matches, only thing that can differ is metadata.
-				if isMetadata &&
-					!metadataEqual(srcCtnt.UserMetadata, tgtCtnt.UserMetadata) &&
-					!metadataEqual(srcCtnt.Metadata, tgtCtnt.Metadata) {
-					// Regular files user requesting additional metadata to same file.
-					diffCh <- diffMessage{
-						FirstURL:      srcCtnt.URL.String(),
-						SecondURL:     tgtCtnt.URL.String(),
-						Diff:          differInMetadata,
-						firstContent:  srcCtnt,
-						secondContent: tgtCtnt,
-					}
-				}
-			} else if (srcType.IsRegular() && tgtType.IsRegular()) && srcSize != tgtSize {
-				// Regular files differing in size.
-				diffCh <- diffMessage{
+			if diff != differInNone || returnSimilar {
+				diffMsg := diffMessage{
 					FirstURL:      srcCtnt.URL.String(),
 					SecondURL:     tgtCtnt.URL.String(),
-					Diff:          differInSize,
+					Diff:          diff,
 					firstContent:  srcCtnt,
 					secondContent: tgtCtnt,
 				}
-			} else if isMetadata &&
-				!metadataEqual(srcCtnt.UserMetadata, tgtCtnt.UserMetadata) &&
-				!metadataEqual(srcCtnt.Metadata, tgtCtnt.Metadata) {
-
-				// Regular files user requesting additional metadata to same file.
-				diffCh <- diffMessage{
-					FirstURL:      srcCtnt.URL.String(),
-					SecondURL:     tgtCtnt.URL.String(),
-					Diff:          differInMetadata,
-					firstContent:  srcCtnt,
-					secondContent: tgtCtnt,
-				}
-			}
-
-			// No differ
-			if returnSimilar {
-				diffCh <- diffMessage{
-					FirstURL:      srcCtnt.URL.String(),
-					SecondURL:     tgtCtnt.URL.String(),
-					Diff:          differInNone,
-					firstContent:  srcCtnt,
-					secondContent: tgtCtnt,
+				if isMetadata {
+					diffMsg.metadataKeys = metadataDifference(srcCtnt, tgtCtnt)
 				}
+				diffCh <- diffMsg
 			}
 			srcCtnt, srcOk = <-srcCh
 			tgtCtnt, tgtOk = <-tgtCh
@@ -295,3 +318,162 @@ func difference(sourceClnt, targetClnt Client, sourceURL, targetURL string, isMe
 
 	return diffCh
 }
+
+// multiDiffMessage - differences of one path between the source and
+// several targets, by index of the target. Targets holding the path
+// neither on the source nor on their side, as well as similar objects
+// when they are not requested, are absent.
+type multiDiffMessage struct {
+	FirstURL     string
+	firstContent *clientContent
+	Diffs        map[int]diffMessage
+	Error        *probe.Error
+}
+
+// multiDifference - finds the differences between a source and several
+// targets, listing the source only once. A listing error on a target
+// is reported in its diff and the target is left out from then on, the
+// other targets are compared until the end.
+func multiDifference(sourceClnt Client, targetClnts []Client, sourceURL string, targetURLs []string, isMetadata, returnSimilar bool) (diffCh chan multiDiffMessage) {
+	diffCh = make(chan multiDiffMessage, 10000)
+
+	go func() {
+		defer close(diffCh)
+		multiDifferenceInternal(sourceClnt, targetClnts, sourceURL, targetURLs, isMetadata, returnSimilar, diffCh)
+	}()
+
+	return diffCh
+}
+
+func multiDifferenceInternal(sourceClnt Client, targetClnts []Client, sourceURL string, targetURLs []string, isMetadata, returnSimilar bool, diffCh chan<- multiDiffMessage) {
+	// Set default values for listing.
+	isRecursive := true
+	isIncomplete := false // we will not compare any incomplete objects.
+
+	srcCh := sourceClnt.List(isRecursive, isIncomplete, isMetadata, DirNone)
+	tgtChs := make([]<-chan *clientContent, len(targetClnts))
+	for i, targetClnt := range targetClnts {
+		tgtChs[i] = targetClnt.List(isRecursive, isIncomplete, isMetadata, DirNone)
+	}
+
+	// Current object and its normalized path for each target, a nil
+	// object means the target has no more objects or failed.
+	tgtCtnts := make([]*clientContent, len(targetClnts))
+	tgtKeys := make([]string, len(targetClnts))
+	tgtFailed := make([]bool, len(targetClnts))
+	nextTarget := func(i int) {
+		for {
+			tgtCtnt, ok := <-tgtChs[i]
+			if !ok {
+				tgtCtnts[i] = nil
+				return
+			}
+			if tgtCtnt.Err != nil {
+				diffCh <- multiDiffMessage{Diffs: map[int]diffMessage{
+					i: {Error: tgtCtnt.Err.Trace(sourceURL, targetURLs[i])},
+				}}
+				tgtCtnts[i] = nil
+				tgtFailed[i] = true
+				return
+			}
+			tgtSuffix := strings.TrimPrefix(tgtCtnt.URL.String(), targetURLs[i])
+			if !utf8.ValidString(tgtSuffix) {
+				// Error. Keys must be valid UTF-8.
+				diffCh <- multiDiffMessage{Diffs: map[int]diffMessage{
+					i: {SecondURL: tgtCtnt.URL.String(), Error: errInvalidTarget(tgtCtnt.URL.String()).Trace()},
+				}}
+				continue
+			}
+			tgtCtnts[i] = tgtCtnt
+			tgtKeys[i] = norm.NFC.String(tgtSuffix)
+			return
+		}
+	}
+	for i := range tgtChs {
+		nextTarget(i)
+	}
+
+	for {
+		srcCtnt, srcOk := <-srcCh
+		var srcKey string
+		if srcOk {
+			if srcCtnt.Err != nil {
+				diffCh <- multiDiffMessage{Error: srcCtnt.Err.Trace(sourceURL)}
+				return
+			}
+			srcSuffix := strings.TrimPrefix(srcCtnt.URL.String(), sourceURL)
+			if !utf8.ValidString(srcSuffix) {
+				// Error. Keys must be valid UTF-8.
+				diffCh <- multiDiffMessage{Error: errInvalidSource(srcCtnt.URL.String()).Trace()}
+				continue
+			}
+			srcKey = norm.NFC.String(srcSuffix)
+		}
+
+		// Report the objects only found on targets before the
+		// current source object, in sorted order.
+		for {
+			key, found := "", false
+			for i, tgtCtnt := range tgtCtnts {
+				if tgtCtnt == nil || srcOk && tgtKeys[i] >= srcKey {
+					continue
+				}
+				if !found || tgtKeys[i] < key {
+					key, found = tgtKeys[i], true
+				}
+			}
+			if !found {
+				break
+			}
+			msg := multiDiffMessage{Diffs: make(map[int]diffMessage)}
+			for i, tgtCtnt := range tgtCtnts {
+				if tgtCtnt != nil && tgtKeys[i] == key {
+					msg.Diffs[i] = diffMessage{
+						SecondURL:     tgtCtnt.URL.String(),
+						Diff:          differInSecond,
+						secondContent: tgtCtnt,
+					}
+					nextTarget(i)
+				}
+			}
+			diffCh <- msg
+		}
+
+		if !srcOk {
+			return
+		}
+
+		msg := multiDiffMessage{
+			FirstURL:     srcCtnt.URL.String(),
+			firstContent: srcCtnt,
+			Diffs:        make(map[int]diffMessage),
+		}
+		for i, tgtCtnt := range tgtCtnts {
+			if tgtFailed[i] {
+				continue
+			}
+			if tgtCtnt == nil || tgtKeys[i] != srcKey {
+				msg.Diffs[i] = diffMessage{
+					FirstURL:     srcCtnt.URL.String(),
+					Diff:         differInFirst,
+					firstContent: srcCtnt,
+				}
+				continue
+			}
+			diff := contentDifference(srcCtnt, tgtCtnt, isMetadata)
+			if diff != differInNone || returnSimilar {
+				msg.Diffs[i] = diffMessage{
+					FirstURL:      srcCtnt.URL.String(),
+					SecondURL:     tgtCtnt.URL.String(),
+					Diff:          diff,
+					firstContent:  srcCtnt,
+					secondContent: tgtCtnt,
+				}
+			}
+			nextTarget(i)
+		}
+		if len(msg.Diffs) > 0 {
+			diffCh <- msg
+		}
+	}
+}
@@ -17,6 +17,11 @@
 package cmd
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -46,3 +51,96 @@ func TestExcludeOptions(t *testing.T) {
 		}
 	}
 }
+
+func TestMultiDifference(t *testing.T) {
+	root, e := ioutil.TempDir(os.TempDir(), "multi-diff-")
+	if e != nil {
+		t.Fatal(e)
+	}
+	defer os.RemoveAll(root)
+
+	layout := map[string][]string{
+		"source":  {"a", "b", "dir/c"},
+		"target1": {"a", "d"},
+		"target2": {},
+	}
+	for folder, objects := range layout {
+		if e = os.MkdirAll(filepath.Join(root, folder), 0700); e != nil {
+			t.Fatal(e)
+		}
+		for _, object := range objects {
+			path := filepath.Join(root, folder, object)
+			if e = os.MkdirAll(filepath.Dir(path), 0700); e != nil {
+				t.Fatal(e)
+			}
+			if e = ioutil.WriteFile(path, []byte("data"), 0600); e != nil {
+				t.Fatal(e)
+			}
+		}
+	}
+
+	folderURL := func(folder string) (Client, string) {
+		clnt, err := fsNew(filepath.Join(root, folder))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return clnt, filepath.Join(root, folder) + string(filepath.Separator)
+	}
+	sourceClnt, sourceURL := folderURL("source")
+	target1Clnt, target1URL := folderURL("target1")
+	target2Clnt, target2URL := folderURL("target2")
+
+	// Key and differences by target of each message.
+	type result struct {
+		Key   string
+		Diffs map[int]differType
+	}
+	var results []result
+	for msg := range multiDifference(sourceClnt, []Client{target1Clnt, target2Clnt}, sourceURL,
+		[]string{target1URL, target2URL}, false, false) {
+		if msg.Error != nil {
+			t.Fatal(msg.Error)
+		}
+		r := result{Diffs: make(map[int]differType)}
+		for i, d := range msg.Diffs {
+			if d.Error != nil {
+				t.Fatal(d.Error)
+			}
+			r.Diffs[i] = d.Diff
+			if d.FirstURL != "" {
+				r.Key = strings.TrimPrefix(d.FirstURL, sourceURL)
+			} else {
+				r.Key = strings.TrimPrefix(d.SecondURL, target1URL)
+			}
+		}
+		results = append(results, r)
+	}
+
+	expected := []result{
+		{"a", map[int]differType{1: differInFirst}},
+		{"b", map[int]differType{0: differInFirst, 1: differInFirst}},
+		{"d", map[int]differType{0: differInSecond}},
+		{filepath.Join("dir", "c"), map[int]differType{0: differInFirst, 1: differInFirst}},
+	}
+	if !reflect.DeepEqual(results, expected) {
+		t.Fatalf("Expected %v, got %v", expected, results)
+	}
+}
+
+func TestMetadataDifference(t *testing.T) {
+	src := &clientContent{
+		Metadata:     map[string]string{"Content-Type": "text/plain", multiMasterETagKey: "a"},
+		UserMetadata: map[string]string{"X-Amz-Meta-Owner": "first"},
+	}
+	tgt := &clientContent{
+		Metadata:     map[string]string{"Content-Type": "image/png", "Cache-Control": "no-cache", multiMasterETagKey: "b"},
+		UserMetadata: map[string]string{"X-Amz-Meta-Owner": "first"},
+	}
+	expected := []string{"Cache-Control", "Content-Type"}
+	if keys := metadataDifference(src, tgt); !reflect.DeepEqual(keys, expected) {
+		t.Fatalf("Expected %v, got %v", expected, keys)
+	}
+	if keys := metadataDifference(src, src); len(keys) != 0 {
+		t.Fatalf("Expected no differences, got %v", keys)
+	}
+}
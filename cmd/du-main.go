@@ -17,9 +17,14 @@
 package cmd
 
 import (
+	"encoding/csv"
 	"fmt"
 	"net/url"
+	"os"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	humanize "github.com/dustin/go-humanize"
 	"github.com/fatih/color"
@@ -36,6 +41,15 @@ var (
 			Name:  "depth, d",
 			Usage: "print the total for a folder prefix only if it is N or fewer levels below the command line argument",
 		},
+		cli.StringFlag{
+			Name:  "start-after",
+			Usage: "summarize only objects after KEY, to resume an interrupted summary",
+		},
+		parallelListFlag,
+		cli.StringFlag{
+			Name:  "format",
+			Usage: "break down usage by object count, incomplete uploads, storage class and age, one of 'text', 'json' or 'csv'",
+		},
 	}
 )
 
@@ -55,6 +69,11 @@ USAGE:
 FLAGS:
   {{range .VisibleFlags}}{{.}}
   {{end}}
+DESCRIPTION:
+  With '--format', the usage of each folder prefix is broken down by object count, bytes of incomplete
+  uploads, bytes per storage class and bytes by age: less than 30 days, 30 to 90 days, 90 days to 1 year
+  and more than 1 year.
+
 ENVIRONMENT VARIABLES:
    MC_ENCRYPT_KEY: list of comma delimited prefix=secret values
 
@@ -64,21 +83,114 @@ EXAMPLES:
 
    2. Summarize disk usage of 'louis' prefix in 'jazz-songs' bucket upto two levels.
       {{.Prompt}} {{.HelpName}} --depth=2 s3/jazz-songs/louis/
+
+   3. Summarize disk usage of 'jazz-songs' bucket after the key of the last continuation marker.
+      {{.Prompt}} {{.HelpName}} --json --start-after "louis/armstrong/what-a-wonderful-world.mp3" s3/jazz-songs
+
+   4. Summarize disk usage of 'jazz-songs' bucket upto two levels, listing up to 16 prefixes concurrently.
+      {{.Prompt}} {{.HelpName}} --depth=2 --parallel-list 16 s3/jazz-songs
+
+   5. Break down disk usage of each team prefix of 'projects' bucket by storage class and age as CSV.
+      {{.Prompt}} {{.HelpName}} --depth=2 --format csv s3/projects > usage.csv
 `,
 }
 
+// duAges - bytes of objects by age, relative to the start of du.
+type duAges struct {
+	LessThan30Days    int64 `json:"lessThan30d"`
+	From30To90Days    int64 `json:"30dTo90d"`
+	From90DaysTo1Year int64 `json:"90dTo1y"`
+	MoreThan1Year     int64 `json:"moreThan1y"`
+}
+
+// add - adds size to the bucket of age.
+func (a *duAges) add(age time.Duration, size int64) {
+	const day = 24 * time.Hour
+	switch {
+	case age < 30*day:
+		a.LessThan30Days += size
+	case age < 90*day:
+		a.From30To90Days += size
+	case age < 365*day:
+		a.From90DaysTo1Year += size
+	default:
+		a.MoreThan1Year += size
+	}
+}
+
+// duUsage - disk usage of a folder prefix broken down by storage
+// class and age, incomplete uploads are accounted separately.
+type duUsage struct {
+	Size           int64            `json:"-"`
+	Objects        int64            `json:"objects"`
+	IncompleteSize int64            `json:"incompleteSize"`
+	StorageClasses map[string]int64 `json:"storageClasses"`
+	Ages           duAges           `json:"ages"`
+}
+
+// newDuUsage - returns an empty disk usage.
+func newDuUsage() *duUsage {
+	return &duUsage{StorageClasses: make(map[string]int64)}
+}
+
+// add - accounts an object listed at now.
+func (u *duUsage) add(content *clientContent, now time.Time) {
+	storageClass := content.StorageClass
+	if storageClass == "" {
+		storageClass = s3StorageClassStandard
+	}
+	u.Size += content.Size
+	u.Objects++
+	u.StorageClasses[storageClass] += content.Size
+	u.Ages.add(now.Sub(content.Time), content.Size)
+}
+
+// merge - accounts the objects of a sub-folder prefix, the incomplete
+// uploads are not merged as they are summed up for each prefix.
+func (u *duUsage) merge(v *duUsage) {
+	u.Size += v.Size
+	u.Objects += v.Objects
+	for storageClass, size := range v.StorageClasses {
+		u.StorageClasses[storageClass] += size
+	}
+	u.Ages.LessThan30Days += v.Ages.LessThan30Days
+	u.Ages.From30To90Days += v.Ages.From30To90Days
+	u.Ages.From90DaysTo1Year += v.Ages.From90DaysTo1Year
+	u.Ages.MoreThan1Year += v.Ages.MoreThan1Year
+}
+
 // Structured message depending on the type of console.
 type duMessage struct {
-	Prefix string `json:"prefix"`
-	Size   int64  `json:"size"`
-	Status string `json:"status"`
+	Prefix    string   `json:"prefix"`
+	Size      int64    `json:"size"`
+	Status    string   `json:"status"`
+	Breakdown *duUsage `json:"breakdown,omitempty"`
 }
 
 // Colorized message for console printing.
 func (r duMessage) String() string {
-	humanSize := strings.Join(strings.Fields(humanize.IBytes(uint64(r.Size))), "")
+	humanSize := func(size int64) string {
+		return strings.Join(strings.Fields(humanize.IBytes(uint64(size))), "")
+	}
+	if r.Breakdown == nil {
+		return fmt.Sprintf("%s\t%s", console.Colorize("Size", humanSize(r.Size)),
+			console.Colorize("Prefix", r.Prefix))
+	}
 
-	return fmt.Sprintf("%s\t%s", console.Colorize("Size", humanSize),
+	var storageClasses []string
+	for storageClass, size := range r.Breakdown.StorageClasses {
+		storageClasses = append(storageClasses, storageClass+" "+humanSize(size))
+	}
+	sort.Strings(storageClasses)
+	ages := r.Breakdown.Ages
+	return fmt.Sprintf("%s\t%s\t%s\t%s\t%s\t%s",
+		console.Colorize("Size", humanSize(r.Size)),
+		console.Colorize("Objects", fmt.Sprintf("%d object(s)", r.Breakdown.Objects)),
+		console.Colorize("Incomplete", humanSize(r.Breakdown.IncompleteSize)+" incomplete"),
+		console.Colorize("StorageClass", "["+strings.Join(storageClasses, ", ")+"]"),
+		console.Colorize("Age", fmt.Sprintf("[<30d %s, 30d-90d %s, 90d-1y %s, >1y %s]",
+			humanSize(ages.LessThan30Days), humanSize(ages.From30To90Days),
+			humanSize(ages.From90DaysTo1Year), humanSize(ages.MoreThan1Year))),
 		console.Colorize("Prefix", r.Prefix))
 }
 
@@ -89,7 +201,109 @@ func (r duMessage) JSON() string {
 	return string(msgBytes)
 }
 
-func du(urlStr string, depth int, encKeyDB map[string][]prefixSSEPair) (int64, error) {
+// duCSVHeader - columns of the CSV output.
+var duCSVHeader = []string{
+	"prefix", "size", "objects", "incompleteSize", "storageClasses",
+	"lessThan30d", "30dTo90d", "90dTo1y", "moreThan1y",
+}
+
+// csvRecord - returns the disk usage as a row of the CSV output.
+func (r duMessage) csvRecord() []string {
+	var storageClasses []string
+	for storageClass, size := range r.Breakdown.StorageClasses {
+		storageClasses = append(storageClasses, storageClass+"="+strconv.FormatInt(size, 10))
+	}
+	sort.Strings(storageClasses)
+	ages := r.Breakdown.Ages
+	return []string{
+		r.Prefix, strconv.FormatInt(r.Size, 10), strconv.FormatInt(r.Breakdown.Objects, 10),
+		strconv.FormatInt(r.Breakdown.IncompleteSize, 10), strings.Join(storageClasses, ";"),
+		strconv.FormatInt(ages.LessThan30Days, 10), strconv.FormatInt(ages.From30To90Days, 10),
+		strconv.FormatInt(ages.From90DaysTo1Year, 10), strconv.FormatInt(ages.MoreThan1Year, 10),
+	}
+}
+
+// duOptions - options of a disk usage summary.
+type duOptions struct {
+	startAfter string
+	marker     *listMarker
+	encKeyDB   map[string][]prefixSSEPair
+	// format is empty unless a breakdown is printed.
+	format    string
+	csvWriter *csv.Writer
+	// incomplete holds the bytes of incomplete uploads by prefix.
+	incomplete map[string]int64
+	now        time.Time
+}
+
+// print - prints the disk usage of a folder prefix in the requested format.
+func (opts duOptions) print(prefix string, usage *duUsage) {
+	msg := duMessage{
+		Prefix: prefix,
+		Size:   usage.Size,
+		Status: "success",
+	}
+	if opts.format == "" {
+		printMsg(msg)
+		return
+	}
+
+	breakdown := *usage
+	breakdown.IncompleteSize = opts.incomplete[prefix]
+	msg.Breakdown = &breakdown
+	switch opts.format {
+	case "json":
+		msgBytes, e := json.Marshal(msg)
+		fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+		console.Println(string(msgBytes))
+	case "csv":
+		fatalIf(probe.NewError(opts.csvWriter.Write(msg.csvRecord())), "Unable to write CSV record.")
+	default:
+		printMsg(msg)
+	}
+}
+
+// duIncomplete - sums up the bytes of incomplete uploads for each
+// folder prefix above them, prefixes are keyed as du prints them.
+// Uploads up to startAfter are skipped like listed objects.
+func duIncomplete(urlStr, startAfter string) (map[string]int64, error) {
+	targetAlias, targetURL, _ := mustExpandAlias(urlStr)
+	clnt, pErr := newClientFromAlias(targetAlias, targetURL)
+	if pErr != nil {
+		errorIf(pErr.Trace(urlStr), "Failed to summarize disk usage `"+urlStr+"`.")
+		return nil, exitStatus(globalErrorExitStatus)
+	}
+	incomplete, pErr := sumIncomplete(clnt, startAfter)
+	if pErr != nil {
+		errorIf(pErr.Trace(urlStr), "Failed to find incomplete uploads of `"+urlStr+"` recursively.")
+		return nil, exitStatus(globalErrorExitStatus)
+	}
+	return incomplete, nil
+}
+
+// sumIncomplete - returns the bytes of incomplete uploads listed after
+// startAfter by folder prefix.
+func sumIncomplete(clnt Client, startAfter string) (map[string]int64, *probe.Error) {
+	separator := string(clnt.GetURL().Separator)
+	incomplete := make(map[string]int64)
+	isRecursive := true
+	isIncomplete := true
+	for content := range clnt.List(isRecursive, isIncomplete, false, DirNone) {
+		if content.Err != nil {
+			return nil, content.Err.Trace()
+		}
+		if startAfter != "" && listMarkerKey(clnt, content) <= startAfter {
+			continue
+		}
+		contentPath := content.URL.Path
+		for i := strings.LastIndex(contentPath, separator); i >= 0; i = strings.LastIndex(contentPath[:i], separator) {
+			incomplete[strings.Trim(contentPath[:i+1], separator)] += content.Size
+		}
+	}
+	return incomplete, nil
+}
+
+func du(urlStr string, depth int, opts duOptions) (*duUsage, error) {
 	targetAlias, targetURL, _ := mustExpandAlias(urlStr)
 	if !strings.HasSuffix(targetURL, "/") {
 		targetURL += "/"
@@ -98,17 +312,24 @@ func du(urlStr string, depth int, encKeyDB map[string][]prefixSSEPair) (int64, e
 	clnt, pErr := newClientFromAlias(targetAlias, targetURL)
 	if pErr != nil {
 		errorIf(pErr.Trace(urlStr), "Failed to summarize disk usage `"+urlStr+"`.")
-		return 0, exitStatus(globalErrorExitStatus) // End of journey.
+		return nil, exitStatus(globalErrorExitStatus) // End of journey.
+	}
+
+	// Parallel listings are only recursive, the folder prefixes
+	// are summed up from the keys of the objects instead.
+	if globalParallelList > 1 && clnt.GetURL().Type == objectStorage {
+		return duRecursive(clnt, urlStr, depth, opts)
 	}
 
 	isRecursive := false
 	isIncomplete := false
-	contentCh := clnt.List(isRecursive, isIncomplete, false, DirFirst)
-	size := int64(0)
+	contentCh := clnt.ListFrom(opts.startAfter, isRecursive, isIncomplete, false, DirFirst)
+	usage := newDuUsage()
 	for content := range contentCh {
 		if content.Err != nil {
+			opts.marker.print()
 			errorIf(content.Err.Trace(urlStr), "Failed to find disk usage of `"+urlStr+"` recursively.")
-			return 0, exitStatus(globalErrorExitStatus)
+			return nil, exitStatus(globalErrorExitStatus)
 		}
 
 		if content.URL.String() == targetURL {
@@ -125,13 +346,14 @@ func du(urlStr string, depth int, encKeyDB map[string][]prefixSSEPair) (int64, e
 			if targetAlias != "" {
 				subDirAlias = targetAlias + "/" + content.URL.Path
 			}
-			used, err := du(subDirAlias, depth, encKeyDB)
+			used, err := du(subDirAlias, depth, opts)
 			if err != nil {
-				return 0, err
+				return nil, err
 			}
-			size += used
+			usage.merge(used)
 		} else {
-			size += content.Size
+			usage.add(content, opts.now)
+			opts.marker.update(listMarkerKey(clnt, content))
 		}
 	}
 
@@ -141,14 +363,68 @@ func du(urlStr string, depth int, encKeyDB map[string][]prefixSSEPair) (int64, e
 			panic(err)
 		}
 
-		printMsg(duMessage{
-			Prefix: strings.Trim(u.Path, "/"),
-			Size:   size,
-			Status: "success",
-		})
+		opts.print(strings.Trim(u.Path, "/"), usage)
+	}
+
+	return usage, nil
+}
+
+// duRecursive - summarizes disk usage from a single recursive listing,
+// the folder prefixes are printed in the same order as du prints them.
+func duRecursive(clnt Client, urlStr string, depth int, opts duOptions) (*duUsage, error) {
+	separator := string(clnt.GetURL().Separator)
+	targetPath := clnt.GetURL().Path
+
+	// Usage of the folder prefixes relative to the target, only the
+	// prefixes which are printed are kept.
+	usages := map[string]*duUsage{}
+	usage := newDuUsage()
+	isRecursive := true
+	isIncomplete := false
+	for content := range clnt.ListFrom(opts.startAfter, isRecursive, isIncomplete, false, DirNone) {
+		if content.Err != nil {
+			opts.marker.print()
+			errorIf(content.Err.Trace(urlStr), "Failed to find disk usage of `"+urlStr+"` recursively.")
+			return nil, exitStatus(globalErrorExitStatus)
+		}
+
+		usage.add(content, opts.now)
+		relPath := strings.TrimPrefix(content.URL.Path, targetPath)
+		for i, level := 0, 1; depth < 0 || level < depth; level++ {
+			next := strings.Index(relPath[i:], separator)
+			if next < 0 {
+				break
+			}
+			i += next + len(separator)
+			prefixUsage, ok := usages[relPath[:i]]
+			if !ok {
+				prefixUsage = newDuUsage()
+				usages[relPath[:i]] = prefixUsage
+			}
+			prefixUsage.add(content, opts.now)
+		}
+		opts.marker.update(listMarkerKey(clnt, content))
 	}
 
-	return size, nil
+	// Folder prefixes are printed after their sub-folder prefixes.
+	prefixes := make([]string, 0, len(usages))
+	for prefix := range usages {
+		prefixes = append(prefixes, prefix)
+	}
+	sort.Slice(prefixes, func(i, j int) bool {
+		if strings.HasPrefix(prefixes[i], prefixes[j]) || strings.HasPrefix(prefixes[j], prefixes[i]) {
+			return len(prefixes[i]) > len(prefixes[j])
+		}
+		return prefixes[i] < prefixes[j]
+	})
+	for _, prefix := range prefixes {
+		opts.print(strings.Trim(targetPath+prefix, separator), usages[prefix])
+	}
+
+	if depth != 0 {
+		opts.print(strings.Trim(targetPath, separator), usage)
+	}
+	return usage, nil
 }
 
 // main for du command.
@@ -159,8 +435,14 @@ func mainDu(ctx *cli.Context) error {
 	// Parse encryption keys per command.
 	encKeyDB, err := getEncKeys(ctx)
 	fatalIf(err, "Unable to parse encryption keys.")
+	setParallelListFromContext(ctx)
 
 	// du specific flags.
+	switch ctx.String("format") {
+	case "", "text", "json", "csv":
+	default:
+		fatalIf(errInvalidArgument().Trace(ctx.String("format")), "Format should be one of `text`, `json` or `csv`.")
+	}
 	depth := ctx.Int("depth")
 	if depth == 0 {
 		depth = -1
@@ -168,10 +450,46 @@ func mainDu(ctx *cli.Context) error {
 
 	// Set color.
 	console.SetColor("Remove", color.New(color.FgGreen, color.Bold))
+	console.SetColor("Objects", color.New(color.FgGreen))
+	console.SetColor("Incomplete", color.New(color.FgRed))
+	console.SetColor("StorageClass", color.New(color.FgMagenta))
+	console.SetColor("Age", color.New(color.FgBlue))
+
+	console.SetColor("Marker", color.New(color.FgYellow))
+	opts := duOptions{
+		startAfter: ctx.String("start-after"),
+		marker:     newListMarker(),
+		encKeyDB:   encKeyDB,
+		format:     ctx.String("format"),
+		now:        UTCNow(),
+	}
+	if opts.format == "csv" {
+		opts.csvWriter = csv.NewWriter(os.Stdout)
+		defer opts.csvWriter.Flush()
+		fatalIf(probe.NewError(opts.csvWriter.Write(duCSVHeader)), "Unable to write CSV header.")
+	}
+
+	checkStartAfterSyntax(ctx, ctx.Args())
 
 	var duErr error
 	for _, urlStr := range ctx.Args() {
-		if _, err := du(urlStr, depth, encKeyDB); duErr == nil {
+		clnt, err := newClient(urlStr)
+		fatalIf(err.Trace(urlStr), "Unable to initialize target `"+urlStr+"`.")
+		urlOpts := opts
+		if isListingAllBuckets(clnt) {
+			urlOpts.marker = nil
+		}
+		if opts.format != "" {
+			incomplete, err := duIncomplete(urlStr, opts.startAfter)
+			if err != nil {
+				if duErr == nil {
+					duErr = err
+				}
+				continue
+			}
+			urlOpts.incomplete = incomplete
+		}
+		if _, err := du(urlStr, depth, urlOpts); duErr == nil {
 			duErr = err
 		}
 	}
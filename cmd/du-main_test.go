@@ -0,0 +1,146 @@
+/*
+ * MinIO Client (C) 2019 MinIO, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDuAges(t *testing.T) {
+	const day = 24 * time.Hour
+	testCases := []struct {
+		age      time.Duration
+		expected duAges
+	}{
+		{0, duAges{LessThan30Days: 1}},
+		{30*day - time.Second, duAges{LessThan30Days: 1}},
+		{30 * day, duAges{From30To90Days: 1}},
+		{90*day - time.Second, duAges{From30To90Days: 1}},
+		{90 * day, duAges{From90DaysTo1Year: 1}},
+		{365*day - time.Second, duAges{From90DaysTo1Year: 1}},
+		{365 * day, duAges{MoreThan1Year: 1}},
+		// Objects modified after du started.
+		{-time.Hour, duAges{LessThan30Days: 1}},
+	}
+	for i, testCase := range testCases {
+		var ages duAges
+		ages.add(testCase.age, 1)
+		if ages != testCase.expected {
+			t.Errorf("Test %d: Expected %+v, got %+v", i+1, testCase.expected, ages)
+		}
+	}
+}
+
+func TestDuUsageMerge(t *testing.T) {
+	now := time.Now()
+	usage := newDuUsage()
+	usage.add(&clientContent{Size: 10, Time: now}, now)
+	usage.add(&clientContent{Size: 20, Time: now.Add(-100 * 24 * time.Hour), StorageClass: "GLACIER"}, now)
+	usage.IncompleteSize = 7
+
+	sub := newDuUsage()
+	sub.add(&clientContent{Size: 5, Time: now.Add(-40 * 24 * time.Hour), StorageClass: s3StorageClassStandard}, now)
+	sub.IncompleteSize = 3
+	usage.merge(sub)
+
+	expected := &duUsage{
+		Size:           35,
+		Objects:        3,
+		IncompleteSize: 7,
+		StorageClasses: map[string]int64{s3StorageClassStandard: 15, "GLACIER": 20},
+		Ages:           duAges{LessThan30Days: 10, From30To90Days: 5, From90DaysTo1Year: 20},
+	}
+	if !reflect.DeepEqual(usage, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, usage)
+	}
+}
+
+func TestDuCSVRecord(t *testing.T) {
+	msg := duMessage{
+		Prefix: "bucket/prefix",
+		Size:   35,
+		Breakdown: &duUsage{
+			Objects:        3,
+			IncompleteSize: 7,
+			StorageClasses: map[string]int64{s3StorageClassStandard: 15, "GLACIER": 20},
+			Ages:           duAges{LessThan30Days: 10, From30To90Days: 5, From90DaysTo1Year: 20},
+		},
+	}
+	expected := []string{"bucket/prefix", "35", "3", "7", "GLACIER=20;STANDARD=15", "10", "5", "20", "0"}
+	if record := msg.csvRecord(); !reflect.DeepEqual(record, expected) {
+		t.Errorf("Expected %q, got %q", expected, record)
+	}
+	if len(expected) != len(duCSVHeader) {
+		t.Errorf("Expected %d columns, got %d", len(duCSVHeader), len(expected))
+	}
+}
+
+func TestSumIncomplete(t *testing.T) {
+	dir, e := ioutil.TempDir("", "du-")
+	if e != nil {
+		t.Fatal(e)
+	}
+	defer os.RemoveAll(dir)
+	for name, size := range map[string]int{"a/b/f": 10, "a/g": 5, "c": 3, "a/h": 1} {
+		path := filepath.Join(dir, name) + partSuffix
+		if e = os.MkdirAll(filepath.Dir(path), 0755); e != nil {
+			t.Fatal(e)
+		}
+		if e = ioutil.WriteFile(path, make([]byte, size), 0644); e != nil {
+			t.Fatal(e)
+		}
+	}
+	// Complete objects are not accounted.
+	if e = ioutil.WriteFile(filepath.Join(dir, "a", "object"), make([]byte, 100), 0644); e != nil {
+		t.Fatal(e)
+	}
+
+	base := strings.Trim(filepath.ToSlash(dir), "/")
+	testCases := []struct {
+		startAfter string
+		expected   map[string]int64
+	}{
+		{"", map[string]int64{base + "/a/b": 10, base + "/a": 16, base: 19}},
+		{filepath.Join(dir, "a", "b", "f"), map[string]int64{base + "/a": 6, base: 9}},
+		{filepath.Join(dir, "a", "h"), map[string]int64{base: 3}},
+	}
+	clnt, err := fsNew(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, testCase := range testCases {
+		incomplete, err := sumIncomplete(clnt, testCase.startAfter)
+		if err != nil {
+			t.Fatalf("Test %d: %v", i+1, err)
+		}
+		for prefix, size := range testCase.expected {
+			if incomplete[prefix] != size {
+				t.Errorf("Test %d: Expected %d bytes for %q, got %d", i+1, size, prefix, incomplete[prefix])
+			}
+		}
+		// Prefixes of skipped uploads only are not keyed.
+		if _, ok := incomplete[base+"/a/b"]; ok != (testCase.expected[base+"/a/b"] != 0) {
+			t.Errorf("Test %d: Unexpected prefix %q in %v", i+1, base+"/a/b", incomplete)
+		}
+	}
+}
@@ -17,9 +17,11 @@
 package cmd
 
 import (
+	"flag"
+	"io/ioutil"
+	"strconv"
 	"strings"
 
-	humanize "github.com/dustin/go-humanize"
 	"github.com/fatih/color"
 	"github.com/minio/cli"
 	"github.com/minio/mc/pkg/console"
@@ -33,6 +35,11 @@ var (
 			Name:  "exec",
 			Usage: "spawn an external process for each matching object (see FORMAT)",
 		},
+		cli.IntFlag{
+			Name:  "exec-parallel",
+			Usage: "run up to N external processes of --exec in parallel",
+			Value: 1,
+		},
 		cli.StringFlag{
 			Name:  "ignore",
 			Usage: "exclude objects matching the wildcard pattern",
@@ -73,10 +80,43 @@ var (
 			Name:  "maxdepth",
 			Usage: "limit directory navigation to specified depth",
 		},
+		cli.StringFlag{
+			Name:  "start-after",
+			Usage: "search only objects after KEY, to resume an interrupted search",
+		},
 		cli.BoolFlag{
 			Name:  "watch",
 			Usage: "monitor a specified path for newly created object(s)",
 		},
+		cli.StringFlag{
+			Name:  "metadata",
+			Usage: "match objects with metadata KEY=VALUE, VALUE accepts wildcard pattern",
+		},
+		cli.StringFlag{
+			Name:  "content-type",
+			Usage: "match objects with content type matching wildcard pattern",
+		},
+		cli.StringFlag{
+			Name:  "storage-class",
+			Usage: "match objects stored in the specified storage class",
+		},
+		cli.StringFlag{
+			Name:  "etag",
+			Usage: "match objects with ETag matching wildcard pattern",
+		},
+		cli.StringFlag{
+			Name:  "tags",
+			Usage: "match objects with tag KEY=VALUE, VALUE accepts wildcard pattern",
+		},
+		cli.BoolFlag{
+			Name:  "or",
+			Usage: "match if either the expression before or after matches (see EXPRESSION)",
+		},
+		cli.BoolFlag{
+			Name:  "not",
+			Usage: "match if the following expression does not match (see EXPRESSION)",
+		},
+		parallelListFlag,
 	}
 )
 
@@ -86,6 +126,8 @@ var findCmd = cli.Command{
 	Action: mainFind,
 	Before: setGlobalsFromContext,
 	Flags:  append(findFlags, globalFlags...),
+	// The find expression is parsed along with the flags by mainFind.
+	SkipFlagParsing: true,
 	CustomHelpTemplate: `NAME:
   {{.HelpName}} - {{.Usage}}
 
@@ -105,6 +147,18 @@ UNITS
   --older-than, --newer-than flags accept the string for days, hours and minutes 
   i.e. 1d2h30m states 1 day, 2 hours and 30 minutes.
 
+EXPRESSION
+  --name, --path, --regex, --newer-than, --older-than, --larger, --smaller,
+  --metadata, --content-type, --storage-class, --etag and --tags flags are
+  predicates combined in order of precedence with:
+
+     ( EXPR )       --> Matches if EXPR matches, parentheses must be escaped from the shell.
+     --not EXPR     --> Matches if EXPR does not match, "!" is accepted as well.
+     EXPR EXPR      --> Matches if both expressions match.
+     EXPR --or EXPR --> Matches if either expression matches.
+
+  --ignore is applied to all objects regardless of the expression.
+
 FORMAT
   Support string substitutions with special interpretations for following keywords.
   Keywords supported if target is filesystem or object storage:
@@ -114,10 +168,16 @@ FORMAT
      {dir}  --> Substitutes to dirname of the path.
      {size} --> Substitutes to object size of the path.
      {time} --> Substitutes to object modified time of the path.
+     {alias} --> Substitutes to the alias of the path.
 
   Keywords supported if target is object storage:
 
-     {url} --> Substitutes to a shareable URL of the path.
+     {etag} --> Substitutes to ETag of the path, not available with --watch.
+     {url}  --> Substitutes to a shareable URL of the path.
+
+  Ending --exec with "{} +" runs the command with the paths of many objects
+  appended to each invocation instead of once per object. Failed invocations
+  are summarized at the end and find exits with the status of the first one.
 
 EXAMPLES:
   01. Find all "foo.jpg" in all buckets under "s3" account.
@@ -150,6 +210,21 @@ EXAMPLES:
 
   10. List all objects up to 3 levels sub-directory deep under "s3/bucket".
       {{.Prompt}} {{.HelpName}} s3/bucket --maxdepth 3
+
+  11. Find all PDF documents larger than 1GB not stored in the STANDARD storage class under "s3/docs".
+      {{.Prompt}} {{.HelpName}} s3/docs \( --name "*.pdf" --or --content-type "application/pdf" \) --not --storage-class STANDARD --larger 1GB
+
+  12. Find all objects under "s3/bucket" with metadata "project" set to "apollo" or tagged with "team=ops*".
+      {{.Prompt}} {{.HelpName}} s3/bucket --metadata project=apollo --or --tags "team=ops*"
+
+  13. Find all ".log" objects under "s3/logs" and remove them with 8 batched "mc rm" commands in parallel.
+      {{.Prompt}} {{.HelpName}} s3/logs --name "*.log" --exec-parallel 8 --exec "mc rm {} +"
+
+  14. Resume an interrupted search under "s3/bucket" after the key of the last continuation marker.
+      {{.Prompt}} {{.HelpName}} s3/bucket --name "*.jpg" --json --start-after "photos/2019/12/31.jpg"
+
+  15. Find all ".jpg" objects under "s3/bucket", listing up to 16 prefixes concurrently.
+      {{.Prompt}} {{.HelpName}} s3/bucket --name "*.jpg" --parallel-list 16
 `,
 }
 
@@ -168,6 +243,21 @@ func checkFindSyntax(ctx *cli.Context, encKeyDB map[string][]prefixSSEPair) {
 		}
 	}
 
+	if ctx.Int("exec-parallel") < 1 {
+		fatalIf(errInvalidArgument().Trace(ctx.String("exec-parallel")), "--exec-parallel must be at least 1.")
+	}
+
+	// Events carry no ETag, it would always be substituted by an empty string.
+	if ctx.Bool("watch") {
+		for _, format := range []string{ctx.String("exec"), ctx.String("print")} {
+			if strings.Contains(format, "{etag}") || strings.Contains(format, `{"etag"}`) {
+				fatalIf(errInvalidArgument().Trace(format), "{etag} cannot be used with --watch.")
+			}
+		}
+	}
+
+	checkStartAfterSyntax(ctx, args)
+
 	// Extract input URLs and validate.
 	for _, url := range args {
 		_, _, err := url2Stat(url, false, false, encKeyDB)
@@ -181,29 +271,68 @@ func checkFindSyntax(ctx *cli.Context, encKeyDB map[string][]prefixSSEPair) {
 	}
 }
 
+// parseFindArgs - parses the find expression from the command line, since
+// expression tokens like "(" stop the regular flag parsing the flags of
+// the command are not parsed by cli and are parsed here into a new context.
+func parseFindArgs(ctx *cli.Context) (*cli.Context, findExpr) {
+	set := flag.NewFlagSet(ctx.Command.Name, flag.ContinueOnError)
+	set.SetOutput(ioutil.Discard)
+	for _, f := range ctx.Command.Flags {
+		f.Apply(set)
+	}
+	isBoolFlag := func(name string) bool {
+		f := set.Lookup(name)
+		if f == nil {
+			return false
+		}
+		v, ok := f.Value.(interface{ IsBoolFlag() bool })
+		return ok && v.IsBoolFlag()
+	}
+
+	args := ctx.Args()
+	expr, args, err := parseFindExpr(args, isBoolFlag)
+	fatalIf(err.Trace(args...), "Unable to parse find expression.")
+
+	e := set.Parse(args)
+	fatalIf(probe.NewError(e).Trace(args...), "Unable to parse find arguments.")
+
+	findCtx := cli.NewContext(ctx.App, set, ctx.Parent())
+	findCtx.Command = ctx.Command
+	if findCtx.Bool("help") {
+		cli.ShowCommandHelpAndExit(findCtx, "find", 0)
+	}
+	fatalIf(probe.NewError(setGlobalsFromContext(findCtx)), "Unable to set global flags.")
+	return findCtx, expr
+}
+
+// replaceAlias - substitutes the {alias} keyword, which is the same
+// for all objects found.
+func replaceAlias(format, alias string) string {
+	format = strings.Replace(format, "{alias}", alias, -1)
+	return strings.Replace(format, `{"alias"}`, strconv.Quote(alias), -1)
+}
+
 // Find context is container to hold all parsed input arguments,
 // each parsed input is stored in its native typed form for
 // ease of repurposing.
 type findContext struct {
 	*cli.Context
 	execCmd       string
+	execParallel  int
 	ignorePattern string
-	namePattern   string
-	pathPattern   string
-	regexPattern  string
+	expr          findExpr
 	maxDepth      uint
 	printFmt      string
-	olderThan     string
-	newerThan     string
-	largerSize    uint64
-	smallerSize   uint64
 	watch         bool
+	startAfter    string
+	encKeyDB      map[string][]prefixSSEPair
 
 	// Internal values
 	targetAlias   string
 	targetURL     string
 	targetFullURL string
 	clnt          Client
+	executor      *findExecutor
 }
 
 // mainFind - handler for mc find commands
@@ -211,12 +340,18 @@ func mainFind(ctx *cli.Context) error {
 	// Additional command specific theme customization.
 	console.SetColor("Find", color.New(color.FgGreen, color.Bold))
 	console.SetColor("FindExecErr", color.New(color.FgRed, color.Italic, color.Bold))
+	console.SetColor("Marker", color.New(color.FgYellow))
+
+	// The find expression is separated from the other flags and
+	// arguments, which are parsed again without it.
+	ctx, expr := parseFindArgs(ctx)
 
 	// Parse encryption keys per command.
 	encKeyDB, err := getEncKeys(ctx)
 	fatalIf(err, "Unable to parse encryption keys.")
 
 	checkFindSyntax(ctx, encKeyDB)
+	setParallelListFromContext(ctx)
 
 	args := ctx.Args()
 	if !args.Present() {
@@ -228,30 +363,6 @@ func mainFind(ctx *cli.Context) error {
 	clnt, err := newClient(args[0])
 	fatalIf(err.Trace(args...), "Unable to initialize `"+args[0]+"`.")
 
-	var olderThan, newerThan string
-
-	if ctx.String("older-than") != "" {
-		olderThan = ctx.String("older-than")
-	}
-	if ctx.String("newer-than") != "" {
-		newerThan = ctx.String("newer-than")
-	}
-
-	// Use 'e' to indicate Go error, this is a convention followed in `mc`. For probe.Error we call it
-	// 'err' and regular Go error is called as 'e'.
-	var e error
-	var largerSize, smallerSize uint64
-
-	if ctx.String("larger") != "" {
-		largerSize, e = humanize.ParseBytes(ctx.String("larger"))
-		fatalIf(probe.NewError(e).Trace(ctx.String("larger")), "Unable to parse input bytes.")
-	}
-
-	if ctx.String("smaller") != "" {
-		smallerSize, e = humanize.ParseBytes(ctx.String("smaller"))
-		fatalIf(probe.NewError(e).Trace(ctx.String("smaller")), "Unable to parse input bytes.")
-	}
-
 	targetAlias, _, hostCfg, err := expandAlias(args[0])
 	fatalIf(err.Trace(args[0]), "Unable to expand alias.")
 
@@ -263,17 +374,14 @@ func mainFind(ctx *cli.Context) error {
 	return doFind(&findContext{
 		Context:       ctx,
 		maxDepth:      ctx.Uint("maxdepth"),
-		execCmd:       ctx.String("exec"),
-		printFmt:      ctx.String("print"),
-		namePattern:   ctx.String("name"),
-		pathPattern:   ctx.String("path"),
-		regexPattern:  ctx.String("regex"),
+		execCmd:       replaceAlias(ctx.String("exec"), targetAlias),
+		execParallel:  ctx.Int("exec-parallel"),
+		printFmt:      replaceAlias(ctx.String("print"), targetAlias),
 		ignorePattern: ctx.String("ignore"),
-		olderThan:     olderThan,
-		newerThan:     newerThan,
-		largerSize:    largerSize,
-		smallerSize:   smallerSize,
+		expr:          expr,
 		watch:         ctx.Bool("watch"),
+		startAfter:    ctx.String("start-after"),
+		encKeyDB:      encKeyDB,
 		targetAlias:   targetAlias,
 		targetURL:     args[0],
 		targetFullURL: targetFullURL,
@@ -18,17 +18,23 @@ package cmd
 
 import (
 	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
 	"github.com/dustin/go-humanize"
 	"github.com/minio/mc/pkg/console"
+	"github.com/minio/mc/pkg/ioutils"
 	"github.com/minio/mc/pkg/probe"
 
 	// golang does not support flat keys for path matching, find does
@@ -110,22 +116,206 @@ func getExitStatus(err error) int {
 	return 1
 }
 
-// execFind executes the input command line, additionally formats input
-// for the command line in accordance with subsititution arguments.
-func execFind(command string) {
-	commandArgs := strings.Split(command, " ")
+// Limits of the object keys passed to a single batched invocation.
+const (
+	findExecBatchMaxKeys  = 1000
+	findExecBatchMaxBytes = 128 * 1024
+)
+
+// findExecFailure is a failed invocation of the exec command.
+type findExecFailure struct {
+	Command    string `json:"command"`
+	ExitStatus int    `json:"exitStatus"`
+}
+
+// findExecSummaryMessage lists the failed invocations of the exec command.
+type findExecSummaryMessage struct {
+	Status      string            `json:"status"`
+	Invocations int64             `json:"invocations"`
+	Failures    []findExecFailure `json:"failures"`
+}
+
+// String colorized summary of failed invocations.
+func (f findExecSummaryMessage) String() string {
+	msg := console.Colorize("FindExecErr", fmt.Sprintf("%d of %d invocations failed:", len(f.Failures), f.Invocations))
+	for _, failure := range f.Failures {
+		msg += "\n" + console.Colorize("FindExecErr", fmt.Sprintf("  exit status %d: %s", failure.ExitStatus, failure.Command))
+	}
+	return msg
+}
+
+// JSON jsonified summary of failed invocations.
+func (f findExecSummaryMessage) JSON() string {
+	jsonMessageBytes, e := json.MarshalIndent(f, "", " ")
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+	return string(jsonMessageBytes)
+}
+
+// findExecutor runs the exec command of matching objects, up to
+// parallel invocations at a time. A command ending with "{} +" is
+// run in batch mode, where the keys of many objects are appended
+// to a single invocation.
+type findExecutor struct {
+	command  string
+	batchCmd []string
+
+	batch      []string
+	batchDone  []func()
+	batchBytes int
+
+	commandCh   chan findExecCommand
+	wg          sync.WaitGroup
+	queued      sync.WaitGroup
+	mutex       sync.Mutex
+	interrupted bool
+	invocations int64
+	failures    []findExecFailure
+}
+
+// findExecCommand is a queued invocation, done is called for each of
+// its objects once it completes.
+type findExecCommand struct {
+	args []string
+	done []func()
+}
+
+// newFindExecutor - starts the workers running the exec command.
+func newFindExecutor(command string, parallel int) *findExecutor {
+	f := &findExecutor{
+		command:   command,
+		commandCh: make(chan findExecCommand),
+	}
+	if fields := strings.Split(command, " "); len(fields) > 2 &&
+		fields[len(fields)-2] == "{}" && fields[len(fields)-1] == "+" {
+		f.batchCmd = fields[:len(fields)-2]
+	}
+	for i := 0; i < parallel; i++ {
+		f.wg.Add(1)
+		go func() {
+			defer f.wg.Done()
+			for command := range f.commandCh {
+				f.run(command)
+			}
+		}()
+	}
+	return f
+}
 
+// run executes a single invocation and records its failure if any.
+func (f *findExecutor) run(command findExecCommand) {
+	defer f.queued.Done()
+
+	commandArgs := command.args
 	cmd := exec.Command(commandArgs[0], commandArgs[1:]...)
 	var out bytes.Buffer
 	var stderr bytes.Buffer
 	cmd.Stdout = &out
 	cmd.Stderr = &stderr
-	if err := cmd.Run(); err != nil {
+	err := cmd.Run()
+
+	f.mutex.Lock()
+	f.invocations++
+	interrupted := f.interrupted
+	if err != nil {
 		console.Print(console.Colorize("FindExecErr", stderr.String()))
-		// Return exit status of the command run
-		os.Exit(getExitStatus(err))
+		f.failures = append(f.failures, findExecFailure{
+			Command:    strings.Join(commandArgs, " "),
+			ExitStatus: getExitStatus(err),
+		})
+	} else {
+		console.PrintC(out.String())
+	}
+	f.mutex.Unlock()
+
+	// Commands failing once interrupted were likely killed along with
+	// mc, they run again when the listing is resumed.
+	if err != nil && interrupted {
+		return
+	}
+	for _, done := range command.done {
+		if done != nil {
+			done()
+		}
+	}
+}
+
+// queue sends the invocation to the workers, unless interrupted.
+func (f *findExecutor) queue(command findExecCommand) {
+	f.mutex.Lock()
+	if f.interrupted {
+		f.mutex.Unlock()
+		return
+	}
+	f.queued.Add(1)
+	f.mutex.Unlock()
+	f.commandCh <- command
+}
+
+// interrupt stops queuing invocations and waits for the queued ones.
+func (f *findExecutor) interrupt() {
+	f.mutex.Lock()
+	f.interrupted = true
+	f.mutex.Unlock()
+	f.queued.Wait()
+}
+
+// exec queues the exec command for the object, in batch mode the
+// command is only queued once enough objects are gathered. done, if
+// not nil, is called once the command of the object completed.
+func (f *findExecutor) exec(fileContent contentMessage, done func()) {
+	if f.batchCmd == nil {
+		f.queue(findExecCommand{
+			args: strings.Split(stringsReplace(f.command, fileContent), " "),
+			done: []func(){done},
+		})
+		return
+	}
+	f.batch = append(f.batch, fileContent.Key)
+	f.batchDone = append(f.batchDone, done)
+	f.batchBytes += len(fileContent.Key) + 1
+	if len(f.batch) >= findExecBatchMaxKeys || f.batchBytes >= findExecBatchMaxBytes {
+		f.flush()
+	}
+}
+
+// flush queues the pending batch of objects.
+func (f *findExecutor) flush() {
+	if len(f.batch) == 0 {
+		return
+	}
+	command := findExecCommand{
+		args: append(append([]string{}, f.batchCmd...), f.batch...),
+		done: f.batchDone,
 	}
-	console.PrintC(out.String())
+	f.batch, f.batchDone, f.batchBytes = nil, nil, 0
+	f.queue(command)
+}
+
+// finish waits for all invocations, failed invocations are summarized
+// and the exit status of the first one is returned.
+func (f *findExecutor) finish() error {
+	f.flush()
+	close(f.commandCh)
+	f.wg.Wait()
+
+	// Once interrupted, the listing ends early and the interrupt handler
+	// exits after printing the marker.
+	f.mutex.Lock()
+	interrupted := f.interrupted
+	f.mutex.Unlock()
+	if interrupted {
+		select {}
+	}
+
+	if len(f.failures) == 0 {
+		return nil
+	}
+	printMsg(findExecSummaryMessage{
+		Status:      "error",
+		Invocations: f.invocations,
+		Failures:    f.failures,
+	})
+	return exitStatus(f.failures[0].ExitStatus)
 }
 
 // watchFind - enables listening on the input path, listens for all file/object
@@ -151,7 +341,7 @@ func watchFind(ctx *findContext) {
 		select {
 		case <-trapCh:
 			console.Println()
-			close(watchObj.doneChan)
+			watchObj.Close()
 			return
 		case event, ok := <-watchObj.Events():
 			if !ok {
@@ -164,11 +354,18 @@ func watchFind(ctx *findContext) {
 				continue
 			}
 
-			find(ctx, contentMessage{
-				Key:  getAliasedPath(ctx, event.Path),
-				Time: time,
-				Size: event.Size,
+			find(ctx, &findObject{
+				contentMessage: contentMessage{
+					Key:  getAliasedPath(ctx, event.Path),
+					Time: time,
+					Size: event.Size,
+				},
+				url: event.Path,
 			})
+			// Batches are not kept pending while waiting for events.
+			if ctx.executor != nil {
+				ctx.executor.flush()
+			}
 
 		case err, ok := <-watchObj.Errors():
 			if !ok {
@@ -222,15 +419,17 @@ func getAliasedPath(ctx *findContext, path string) string {
 	return trimSuffixAtMaxDepth(ctx.targetURL, aliasedPath, separator, ctx.maxDepth)
 }
 
-func find(ctx *findContext, fileContent contentMessage) {
+func find(ctx *findContext, obj *findObject) {
 	// Match the incoming content, didn't match return.
-	if !matchFind(ctx, fileContent) {
+	if !matchFind(ctx, obj) {
 		return
 	} // For all matching content
 
+	fileContent := obj.contentMessage
+
 	// proceed to either exec, format the output string.
-	if ctx.execCmd != "" {
-		execFind(stringsReplace(ctx.execCmd, fileContent))
+	if ctx.executor != nil {
+		ctx.executor.exec(fileContent, nil)
 		return
 	}
 	if ctx.printFmt != "" {
@@ -242,15 +441,24 @@ func find(ctx *findContext, fileContent contentMessage) {
 // doFind - find is main function body which interprets and executes
 // all the input parameters.
 func doFind(ctx *findContext) error {
-	// If watch is enabled we will wait on the prefix perpetually
-	// for all I/O events until canceled by user, if watch is not enabled
-	// following defer is a no-op.
-	defer watchFind(ctx)
+	if ctx.execCmd != "" {
+		ctx.executor = newFindExecutor(ctx.execCmd, ctx.execParallel)
+	}
 
 	var prevKeyName string
 
+	// Listings can be resumed from a marker, unless watching.
+	var marker *listMarker
+	if !ctx.watch && !isListingAllBuckets(ctx.clnt) {
+		marker = newListMarker()
+		// Running commands complete before the marker is printed.
+		if ctx.executor != nil {
+			marker.waitOnInterrupt(ctx.executor.interrupt)
+		}
+	}
+
 	// iterate over all content which is within the given directory
-	for content := range ctx.clnt.List(true, false, false, DirNone) {
+	for content := range ctx.clnt.ListFrom(ctx.startAfter, true, false, false, DirNone) {
 		if content.Err != nil {
 			switch content.Err.ToGoError().(type) {
 			// handle this specifically for filesystem related errors.
@@ -267,6 +475,9 @@ func doFind(ctx *findContext) error {
 				errorIf(content.Err.Trace(ctx.clnt.GetURL().String()), "Unable to list folder.")
 				continue
 			}
+			if marker != nil {
+				marker.print()
+			}
 			fatalIf(content.Err.Trace(ctx.clnt.GetURL().String()), "Unable to list folder.")
 			continue
 		}
@@ -274,23 +485,41 @@ func doFind(ctx *findContext) error {
 			continue
 		}
 
+		// The marker advances past the entry once it is handled, which
+		// is once its exec command completed.
+		done := func() {}
+		if marker != nil {
+			done = marker.add(listMarkerKey(ctx.clnt, content))
+		}
+
 		fileKeyName := getAliasedPath(ctx, content.URL.String())
-		fileContent := contentMessage{
-			Key:  fileKeyName,
-			Time: content.Time.Local(),
-			Size: content.Size,
+		if prevKeyName == fileKeyName {
+			done()
+			continue
+		}
+		obj := &findObject{
+			contentMessage: contentMessage{
+				Key:  fileKeyName,
+				Time: content.Time.Local(),
+				Size: content.Size,
+				ETag: content.ETag,
+			},
+			url:          content.URL.String(),
+			storageClass: content.StorageClass,
 		}
 
 		// Match the incoming content, didn't match return.
-		if !matchFind(ctx, fileContent) || prevKeyName == fileKeyName {
+		if !matchFind(ctx, obj) {
+			done()
 			continue
 		} // For all matching content
 
 		prevKeyName = fileKeyName
+		fileContent := obj.contentMessage
 
 		// proceed to either exec, format the output string.
-		if ctx.execCmd != "" {
-			execFind(stringsReplace(ctx.execCmd, fileContent))
+		if ctx.executor != nil {
+			ctx.executor.exec(fileContent, done)
 			continue
 		}
 		if ctx.printFmt != "" {
@@ -298,10 +527,19 @@ func doFind(ctx *findContext) error {
 		}
 
 		printMsg(findMessage{fileContent})
+		done()
 	}
 
-	// Success, notice watch will execute in defer only if enabled and this call
-	// will return after watch is canceled.
+	// If watch is enabled we will wait on the prefix perpetually
+	// for all I/O events until canceled by user, if watch is not enabled
+	// following call is a no-op.
+	watchFind(ctx)
+
+	// Wait for the exec commands after the listing, or after watch
+	// is canceled if enabled.
+	if ctx.executor != nil {
+		return ctx.executor.finish()
+	}
 	return nil
 }
 
@@ -359,6 +597,16 @@ func stringsReplace(args string, fileContent contentMessage) string {
 		str = strings.Replace(str, `{"time"}`, strconv.Quote(fileContent.Time.Format(printDate)), -1)
 	}
 
+	// replace all instances of {etag}
+	if strings.Contains(str, "{etag}") {
+		str = strings.Replace(str, "{etag}", fileContent.ETag, -1)
+	}
+
+	// replace all instances of {"etag"}
+	if strings.Contains(str, `{"etag"}`) {
+		str = strings.Replace(str, `{"etag"}`, strconv.Quote(fileContent.ETag), -1)
+	}
+
 	// replace all instances of {url}
 	if strings.Contains(str, "{url}") {
 		str = strings.Replace(str, "{url}", getShareURL(fileContent.Key), -1)
@@ -372,10 +620,10 @@ func stringsReplace(args string, fileContent contentMessage) string {
 	return str
 }
 
-// matchFind matches whether fileContent matches appropriately with standard
-// "pattern matching" flags requested by the user, such as "name", "path", "regex" ..etc.
-func matchFind(ctx *findContext, fileContent contentMessage) (match bool) {
-	match = true
+// matchFind matches whether the object matches the find expression made of
+// the "pattern matching" flags requested by the user, such as "name", "path",
+// "regex" ..etc. Objects matching the ignore pattern never match.
+func matchFind(ctx *findContext, obj *findObject) bool {
 	prefixPath := ctx.targetURL
 	// Add separator only if targetURL doesn't already have separator.
 	if !strings.HasPrefix(prefixPath, string(ctx.clnt.GetURL().Separator)) {
@@ -383,32 +631,371 @@ func matchFind(ctx *findContext, fileContent contentMessage) (match bool) {
 	}
 	// Trim the prefix such that we will apply file path matching techniques
 	// on path excluding the starting prefix.
-	path := strings.TrimPrefix(fileContent.Key, prefixPath)
-	if match && ctx.ignorePattern != "" {
-		match = !pathMatch(ctx.ignorePattern, path)
+	obj.path = strings.TrimPrefix(obj.Key, prefixPath)
+	if ctx.ignorePattern != "" && pathMatch(ctx.ignorePattern, obj.path) {
+		return false
+	}
+	if ctx.expr == nil {
+		return true
+	}
+	return ctx.expr.match(ctx, obj)
+}
+
+// findObject is an object matched against the find expression, its
+// metadata and tags are only fetched when a predicate needs them.
+type findObject struct {
+	contentMessage
+
+	path         string
+	url          string
+	storageClass string
+
+	metadata map[string]string
+	tags     map[string]string
+}
+
+// getMetadata returns the headers and user metadata of the object,
+// user metadata is keyed with and without its "X-Amz-Meta-" prefix.
+func (obj *findObject) getMetadata(ctx *findContext) map[string]string {
+	if obj.metadata != nil {
+		return obj.metadata
+	}
+	obj.metadata = map[string]string{}
+
+	clnt, err := newClientFromAlias(ctx.targetAlias, obj.url)
+	if err != nil {
+		errorIf(err.Trace(obj.url), "Unable to initialize `"+obj.Key+"`.")
+		return obj.metadata
 	}
-	if match && ctx.namePattern != "" {
-		match = nameMatch(ctx.namePattern, path)
+	content, err := clnt.Stat(false, true, false, getSSE(obj.url, ctx.encKeyDB[ctx.targetAlias]))
+	if err != nil {
+		errorIf(err.Trace(obj.url), "Unable to fetch metadata of `"+obj.Key+"`.")
+		return obj.metadata
 	}
-	if match && ctx.pathPattern != "" {
-		match = pathMatch(ctx.pathPattern, path)
+	for k, v := range content.Metadata {
+		obj.metadata[http.CanonicalHeaderKey(k)] = v
 	}
-	if match && ctx.regexPattern != "" {
-		match = regexMatch(ctx.regexPattern, path)
+	for k, v := range content.UserMetadata {
+		obj.metadata[http.CanonicalHeaderKey(k)] = v
 	}
-	if match && ctx.olderThan != "" {
-		match = !isOlder(fileContent.Time, ctx.olderThan)
+	for k, v := range obj.metadata {
+		if strings.HasPrefix(k, "X-Amz-Meta-") {
+			obj.metadata[http.CanonicalHeaderKey(strings.TrimPrefix(k, "X-Amz-Meta-"))] = v
+		}
+	}
+	return obj.metadata
+}
+
+// getTags returns the tags of the object.
+func (obj *findObject) getTags(ctx *findContext) map[string]string {
+	if obj.tags != nil {
+		return obj.tags
+	}
+	obj.tags = map[string]string{}
+
+	clnt, err := newClientFromAlias(ctx.targetAlias, obj.url)
+	if err != nil {
+		errorIf(err.Trace(obj.url), "Unable to initialize `"+obj.Key+"`.")
+		return obj.tags
+	}
+	tags, err := clnt.GetTags()
+	if err != nil {
+		// Objects without tagging support, like local files, have no tags.
+		if _, ok := err.ToGoError().(APINotImplemented); ok {
+			return obj.tags
+		}
+		errorIf(err.Trace(obj.url), "Unable to fetch tags of `"+obj.Key+"`.")
+		return obj.tags
+	}
+	obj.tags = tags
+	return obj.tags
+}
+
+// findExpr is a boolean expression of find predicates.
+type findExpr interface {
+	match(ctx *findContext, obj *findObject) bool
+}
+
+// findAnd matches when all of its operands match.
+type findAnd []findExpr
+
+func (f findAnd) match(ctx *findContext, obj *findObject) bool {
+	for _, expr := range f {
+		if !expr.match(ctx, obj) {
+			return false
+		}
+	}
+	return true
+}
+
+// findOr matches when any of its operands match.
+type findOr []findExpr
+
+func (f findOr) match(ctx *findContext, obj *findObject) bool {
+	for _, expr := range f {
+		if expr.match(ctx, obj) {
+			return true
+		}
+	}
+	return false
+}
+
+// findNot negates its operand.
+type findNot struct {
+	expr findExpr
+}
+
+func (f findNot) match(ctx *findContext, obj *findObject) bool {
+	return !f.expr.match(ctx, obj)
+}
+
+// List of find flags which are predicates of the find expression.
+var findPredicates = []string{
+	"name", "path", "regex", "newer-than", "older-than", "larger", "smaller",
+	"metadata", "content-type", "storage-class", "etag", "tags",
+}
+
+func isFindPredicate(name string) bool {
+	for _, predicate := range findPredicates {
+		if predicate == name {
+			return true
+		}
+	}
+	return false
+}
+
+// findPredicate is a single find flag along with its value, values
+// of the form "key=pattern" are split into key and pattern.
+type findPredicate struct {
+	flag    string
+	pattern string
+	key     string
+	size    uint64
+}
+
+// newFindPredicate - validates and parses the value of a predicate flag.
+func newFindPredicate(flag, value string) (*findPredicate, *probe.Error) {
+	p := &findPredicate{flag: flag, pattern: value}
+	if value == "" {
+		return nil, probe.NewError(fmt.Errorf("flag `--%s` requires a value", flag))
+	}
+	switch flag {
+	case "regex":
+		if _, e := regexp.Compile(value); e != nil {
+			return nil, probe.NewError(e).Trace(value)
+		}
+	case "newer-than", "older-than":
+		if _, e := ioutils.ParseDurationTime(value); e != nil {
+			return nil, probe.NewError(e).Trace(value)
+		}
+	case "larger", "smaller":
+		size, e := humanize.ParseBytes(value)
+		if e != nil {
+			return nil, probe.NewError(e).Trace(value)
+		}
+		p.size = size
+	case "metadata", "tags":
+		// A key without a pattern matches any value.
+		p.key, p.pattern = value, "*"
+		if i := strings.Index(value, "="); i >= 0 {
+			p.key, p.pattern = value[:i], value[i+1:]
+		}
+		if p.key == "" {
+			return nil, probe.NewError(fmt.Errorf("flag `--%s` requires a value of the form key=pattern", flag))
+		}
+	}
+	return p, nil
+}
+
+func (p *findPredicate) match(ctx *findContext, obj *findObject) bool {
+	switch p.flag {
+	case "name":
+		return nameMatch(p.pattern, obj.path)
+	case "path":
+		return pathMatch(p.pattern, obj.path)
+	case "regex":
+		return regexMatch(p.pattern, obj.path)
+	case "older-than":
+		return !isOlder(obj.Time, p.pattern)
+	case "newer-than":
+		return !isNewer(obj.Time, p.pattern)
+	case "larger":
+		return int64(p.size) < obj.Size
+	case "smaller":
+		return int64(p.size) > obj.Size
+	case "etag":
+		return pathMatch(strings.ToLower(p.pattern), strings.ToLower(obj.ETag))
+	case "storage-class":
+		storageClass := obj.storageClass
+		if storageClass == "" {
+			storageClass = s3StorageClassStandard
+		}
+		return strings.EqualFold(p.pattern, storageClass)
+	case "content-type":
+		// Parameters such as "charset" are not part of the match.
+		contentType := strings.Split(obj.getMetadata(ctx)["Content-Type"], ";")[0]
+		return pathMatch(strings.ToLower(p.pattern), strings.ToLower(strings.TrimSpace(contentType)))
+	case "metadata":
+		value, ok := obj.getMetadata(ctx)[http.CanonicalHeaderKey(p.key)]
+		return ok && pathMatch(p.pattern, value)
+	case "tags":
+		value, ok := obj.getTags(ctx)[p.key]
+		return ok && pathMatch(p.pattern, value)
+	}
+	return false
+}
+
+// parseFindExpr - separates the find expression from the other flags and
+// arguments of the command line. Predicates are combined with "(", ")",
+// "--not" or "!" and "--or", predicates next to each other are combined
+// with an implicit AND which binds tighter than OR. The remaining flags
+// are returned before the remaining arguments so they can be parsed again.
+func parseFindExpr(args []string, isBoolFlag func(name string) bool) (findExpr, []string, *probe.Error) {
+	var tokens []interface{}
+	var flags, positional []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "(" || arg == ")":
+			tokens = append(tokens, arg)
+			continue
+		case arg == "!":
+			tokens = append(tokens, "not")
+			continue
+		case arg == "--":
+			positional = append(positional, args[i+1:]...)
+			i = len(args)
+			continue
+		case arg == "-" || !strings.HasPrefix(arg, "-"):
+			positional = append(positional, arg)
+			continue
+		}
+
+		name := strings.TrimLeft(arg, "-")
+		value, hasValue := "", false
+		if j := strings.Index(name, "="); j >= 0 {
+			name, value, hasValue = name[:j], name[j+1:], true
+		}
+		switch {
+		case name == "or" || name == "not":
+			tokens = append(tokens, name)
+		case isFindPredicate(name):
+			if !hasValue {
+				if i+1 == len(args) {
+					return nil, nil, probe.NewError(fmt.Errorf("flag `--%s` requires a value", name))
+				}
+				i++
+				value = args[i]
+			}
+			predicate, err := newFindPredicate(name, value)
+			if err != nil {
+				return nil, nil, err.Trace(arg)
+			}
+			tokens = append(tokens, predicate)
+		default:
+			flags = append(flags, arg)
+			if !hasValue && !isBoolFlag(name) && i+1 < len(args) {
+				i++
+				flags = append(flags, args[i])
+			}
+		}
 	}
-	if match && ctx.newerThan != "" {
-		match = !isNewer(fileContent.Time, ctx.newerThan)
+
+	parser := &findExprParser{tokens: tokens}
+	expr, err := parser.parseOr()
+	if err != nil {
+		return nil, nil, err
 	}
-	if match && ctx.largerSize > 0 {
-		match = int64(ctx.largerSize) < fileContent.Size
+	if parser.pos < len(tokens) {
+		return nil, nil, probe.NewError(errors.New("unexpected `)` in find expression"))
+	}
+	return expr, append(append(flags, "--"), positional...), nil
+}
+
+// findExprParser is a recursive descent parser of find expressions.
+type findExprParser struct {
+	tokens []interface{}
+	pos    int
+}
+
+func (p *findExprParser) peek() interface{} {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
 	}
-	if match && ctx.smallerSize > 0 {
-		match = int64(ctx.smallerSize) > fileContent.Size
+	return nil
+}
+
+func (p *findExprParser) parseOr() (findExpr, *probe.Error) {
+	if p.peek() == nil {
+		return nil, nil
+	}
+	var exprs findOr
+	for {
+		expr, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		exprs = append(exprs, expr)
+		if p.peek() != "or" {
+			break
+		}
+		p.pos++
+	}
+	if len(exprs) == 1 {
+		return exprs[0], nil
+	}
+	return exprs, nil
+}
+
+func (p *findExprParser) parseAnd() (findExpr, *probe.Error) {
+	var exprs findAnd
+	for token := p.peek(); token != nil && token != "or" && token != ")"; token = p.peek() {
+		expr, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		exprs = append(exprs, expr)
+	}
+	switch len(exprs) {
+	case 0:
+		return nil, probe.NewError(errors.New("missing predicate in find expression"))
+	case 1:
+		return exprs[0], nil
+	}
+	return exprs, nil
+}
+
+func (p *findExprParser) parseUnary() (findExpr, *probe.Error) {
+	token := p.peek()
+	p.pos++
+	switch token {
+	case "not":
+		expr, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return findNot{expr}, nil
+	case "(":
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if expr == nil || p.peek() != ")" {
+			return nil, probe.NewError(errors.New("missing `)` in find expression"))
+		}
+		p.pos++
+		return expr, nil
+	case nil:
+		return nil, probe.NewError(errors.New("missing predicate in find expression"))
+	}
+	predicate, ok := token.(*findPredicate)
+	if !ok {
+		if token == "or" {
+			token = "--or"
+		}
+		return nil, probe.NewError(fmt.Errorf("unexpected `%s` in find expression", token))
 	}
-	return match
+	return predicate, nil
 }
 
 // 7 days in seconds.
@@ -18,9 +18,12 @@ package cmd
 
 import (
 	"os/exec"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/minio/cli"
 )
 
 // Tests match find function with all supported inputs on
@@ -39,43 +42,43 @@ func TestMatchFind(t *testing.T) {
 			clnt: &s3Client{
 				targetURL: &clientURL{},
 			},
-			namePattern: "console",
+			expr: &findPredicate{flag: "name", pattern: "console"},
 		},
 		{
 			clnt: &s3Client{
 				targetURL: &clientURL{},
 			},
-			pathPattern: "*console*",
+			expr: &findPredicate{flag: "path", pattern: "*console*"},
 		},
 		{
 			clnt: &s3Client{
 				targetURL: &clientURL{},
 			},
-			regexPattern: `^(\d+\.){3}\d+$`,
+			expr: &findPredicate{flag: "regex", pattern: `^(\d+\.){3}\d+$`},
 		},
 		{
 			clnt: &s3Client{
 				targetURL: &clientURL{},
 			},
-			olderThan: "1d",
+			expr: &findPredicate{flag: "older-than", pattern: "1d"},
 		},
 		{
 			clnt: &s3Client{
 				targetURL: &clientURL{},
 			},
-			newerThan: "32000d",
+			expr: &findPredicate{flag: "newer-than", pattern: "32000d"},
 		},
 		{
 			clnt: &s3Client{
 				targetURL: &clientURL{},
 			},
-			largerSize: 1024 * 1024,
+			expr: &findPredicate{flag: "larger", size: 1024 * 1024},
 		},
 		{
 			clnt: &s3Client{
 				targetURL: &clientURL{},
 			},
-			smallerSize: 1024,
+			expr: &findPredicate{flag: "smaller", size: 1024},
 		},
 		{
 			clnt: &s3Client{
@@ -166,13 +169,98 @@ func TestMatchFind(t *testing.T) {
 
 	// Runs all the test cases and validate the expected conditions.
 	for i, testCase := range testCases {
-		gotMatch := matchFind(listFindContexts[i], testCase.content)
+		gotMatch := matchFind(listFindContexts[i], &findObject{contentMessage: testCase.content})
 		if testCase.expectedMatch != gotMatch {
 			t.Errorf("Test: %d, expected match %t, got %t", i+1, testCase.expectedMatch, gotMatch)
 		}
 	}
 }
 
+// Tests parsing and matching of find expressions.
+func TestFindExpr(t *testing.T) {
+	isBoolFlag := func(name string) bool {
+		return name == "json" || name == "watch"
+	}
+	objects := map[string]*findObject{
+		"report.pdf": {
+			contentMessage: contentMessage{Key: "report.pdf", Size: 2 << 30},
+			storageClass:   "GLACIER",
+			metadata:       map[string]string{"Content-Type": "application/pdf", "Project": "apollo"},
+			tags:           map[string]string{"team": "ops-eu"},
+		},
+		"small.pdf": {
+			contentMessage: contentMessage{Key: "small.pdf", Size: 1024},
+			metadata:       map[string]string{"Content-Type": "application/pdf; charset=binary"},
+			tags:           map[string]string{},
+		},
+		"photo.jpg": {
+			contentMessage: contentMessage{Key: "photo.jpg", Size: 2 << 30, ETag: "1A79A4D60DE6718E8E5B326E338AE533"},
+			storageClass:   "STANDARD",
+			metadata:       map[string]string{"Content-Type": "image/jpeg", "Project": "gemini"},
+			tags:           map[string]string{"team": "dev"},
+		},
+	}
+
+	testCases := []struct {
+		args         []string
+		expectedArgs []string
+		matches      []string
+		expectedErr  bool
+	}{
+		{[]string{"s3/bucket"}, []string{"--", "s3/bucket"}, []string{"report.pdf", "small.pdf", "photo.jpg"}, false},
+		{[]string{"s3/bucket", "--name", "*.pdf", "--json"}, []string{"--json", "--", "s3/bucket"}, []string{"report.pdf", "small.pdf"}, false},
+		{[]string{"--name=*.pdf", "--larger", "1GB"}, []string{"--"}, []string{"report.pdf"}, false},
+		{[]string{"--name", "*.jpg", "--or", "--larger", "1GB"}, []string{"--"}, []string{"report.pdf", "photo.jpg"}, false},
+		{[]string{"--content-type", "application/pdf", "--not", "--storage-class", "STANDARD", "--larger", "1GB"}, []string{"--"}, []string{"report.pdf"}, false},
+		{[]string{"!", "(", "--name", "*.jpg", "--or", "--smaller", "1MB", ")"}, []string{"--"}, []string{"report.pdf"}, false},
+		{[]string{"--metadata", "project=*ll*", "--exec", "echo {}", "--watch", "s3/bucket"}, []string{"--exec", "echo {}", "--watch", "--", "s3/bucket"}, []string{"report.pdf"}, false},
+		{[]string{"--metadata", "project"}, []string{"--"}, []string{"report.pdf", "photo.jpg"}, false},
+		{[]string{"--tags", "team=ops*", "--or", "--etag", "1a79a4d6*"}, []string{"--"}, []string{"report.pdf", "photo.jpg"}, false},
+		{[]string{"--storage-class", "standard"}, []string{"--"}, []string{"small.pdf", "photo.jpg"}, false},
+		{[]string{"(", "--name", "*.pdf"}, nil, nil, true},
+		{[]string{"--name", "*.pdf", ")"}, nil, nil, true},
+		{[]string{"--name", "*.pdf", "--or"}, nil, nil, true},
+		{[]string{"--not", "--or", "--name", "*.pdf"}, nil, nil, true},
+		{[]string{"()"}, []string{"--", "()"}, []string{"report.pdf", "small.pdf", "photo.jpg"}, false},
+		{[]string{"(", ")"}, nil, nil, true},
+		{[]string{"--larger", "1XB"}, nil, nil, true},
+		{[]string{"--tags", "=value"}, nil, nil, true},
+		{[]string{"--name"}, nil, nil, true},
+	}
+
+	for i, testCase := range testCases {
+		expr, args, err := parseFindExpr(testCase.args, isBoolFlag)
+		if testCase.expectedErr {
+			if err == nil {
+				t.Errorf("Test %d: expected an error", i+1)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Test %d: unexpected error %s", i+1, err)
+			continue
+		}
+		if strings.Join(args, " ") != strings.Join(testCase.expectedArgs, " ") {
+			t.Errorf("Test %d: expected args %q, got %q", i+1, testCase.expectedArgs, args)
+		}
+		ctx := &findContext{
+			clnt: &s3Client{
+				targetURL: &clientURL{},
+			},
+			expr: expr,
+		}
+		var matches []string
+		for _, key := range []string{"report.pdf", "small.pdf", "photo.jpg"} {
+			if matchFind(ctx, objects[key]) {
+				matches = append(matches, key)
+			}
+		}
+		if strings.Join(matches, " ") != strings.Join(testCase.matches, " ") {
+			t.Errorf("Test %d: expected matches %q, got %q", i+1, testCase.matches, matches)
+		}
+	}
+}
+
 // Tests suffix strings trimmed off correctly at maxdepth.
 func TestSuffixTrimmingAtMaxDepth(t *testing.T) {
 	var testCases = []struct {
@@ -408,6 +496,12 @@ func TestStringReplace(t *testing.T) {
 				Time: time.Unix(2147483647, 0).UTC(),
 			},
 		},
+		// Tests string replace {etag} and {"etag"} with quotes.
+		{
+			str:         `{etag} {"etag"}`,
+			expectedStr: `d41d8cd98f00b204e9800998ecf8427e "d41d8cd98f00b204e9800998ecf8427e"`,
+			content:     contentMessage{ETag: "d41d8cd98f00b204e9800998ecf8427e"},
+		},
 	}
 	for i, testCase := range testCases {
 		gotStr := stringsReplace(testCase.str, testCase.content)
@@ -452,3 +546,137 @@ func TestGetExitStatus(t *testing.T) {
 		}
 	}
 }
+
+// Tests detection of the batch mode of the exec command.
+func TestFindExecutorBatchCmd(t *testing.T) {
+	testCases := []struct {
+		command  string
+		batchCmd []string
+	}{
+		{"echo {} +", []string{"echo"}},
+		{"mc rm --force {} +", []string{"mc", "rm", "--force"}},
+		{"echo {}", nil},
+		{"echo {} +x", nil},
+		{"echo + {}", nil},
+		{"{} +", nil},
+	}
+	for i, testCase := range testCases {
+		f := newFindExecutor(testCase.command, 0)
+		if !reflect.DeepEqual(f.batchCmd, testCase.batchCmd) {
+			t.Errorf("Test %d: Expected %q, got %q", i+1, testCase.batchCmd, f.batchCmd)
+		}
+	}
+}
+
+// Tests that batches are queued when they reach the key or byte limit.
+func TestFindExecutorFlush(t *testing.T) {
+	f := newFindExecutor("echo {} +", 0)
+	var batches [][]string
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for command := range f.commandCh {
+			batches = append(batches, command.args)
+		}
+	}()
+
+	for i := 0; i < findExecBatchMaxKeys+1; i++ {
+		f.exec(contentMessage{Key: "k"}, nil)
+	}
+	f.exec(contentMessage{Key: strings.Repeat("b", findExecBatchMaxBytes)}, nil)
+	f.exec(contentMessage{Key: "last"}, nil)
+	if err := f.finish(); err != nil {
+		t.Fatal(err)
+	}
+	<-done
+
+	var sizes []int
+	for _, batch := range batches {
+		if batch[0] != "echo" {
+			t.Errorf("Expected batch to start with the command, got %q", batch[0])
+		}
+		sizes = append(sizes, len(batch)-1)
+	}
+	if expected := []int{findExecBatchMaxKeys, 2, 1}; !reflect.DeepEqual(sizes, expected) {
+		t.Errorf("Expected batches of %v keys, got %v", expected, sizes)
+	}
+}
+
+// Tests that --exec-parallel runs invocations concurrently.
+func TestFindExecutorParallel(t *testing.T) {
+	f := newFindExecutor("sleep {}", 4)
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		f.exec(contentMessage{Key: "1"}, nil)
+	}
+	if err := f.finish(); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed >= 3*time.Second {
+		t.Errorf("Expected invocations to run concurrently, took %v", elapsed)
+	}
+	if f.invocations != 4 {
+		t.Errorf("Expected 4 invocations, got %d", f.invocations)
+	}
+}
+
+// Tests the summary and exit status of failed invocations.
+func TestFindExecutorFailures(t *testing.T) {
+	f := newFindExecutor("ls -d {}", 2)
+	f.exec(contentMessage{Key: "/"}, nil)
+	f.exec(contentMessage{Key: "/nonexistent-find-exec"}, nil)
+	err := f.finish()
+	exitErr, ok := err.(cli.ExitCoder)
+	if !ok {
+		t.Fatalf("Expected an exit status, got %v", err)
+	}
+	if exitErr.ExitCode() != 2 {
+		t.Errorf("Expected exit status 2, got %d", exitErr.ExitCode())
+	}
+	expected := []findExecFailure{{Command: "ls -d /nonexistent-find-exec", ExitStatus: 2}}
+	if !reflect.DeepEqual(f.failures, expected) {
+		t.Errorf("Expected failures %v, got %v", expected, f.failures)
+	}
+	if f.invocations != 2 {
+		t.Errorf("Expected 2 invocations, got %d", f.invocations)
+	}
+
+	f = newFindExecutor("ls -d {}", 2)
+	f.exec(contentMessage{Key: "/"}, nil)
+	if err = f.finish(); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+}
+
+// Tests that the listing marker only advances past objects whose
+// command completed, in listing order.
+func TestFindExecutorMarker(t *testing.T) {
+	marker := &listMarker{}
+	f := newFindExecutor("sleep {}", 2)
+	f.exec(contentMessage{Key: "0.5"}, marker.add("a"))
+	f.exec(contentMessage{Key: "0"}, marker.add("b"))
+	marker.add("c")()
+
+	time.Sleep(200 * time.Millisecond)
+	marker.mutex.Lock()
+	key := marker.key
+	marker.mutex.Unlock()
+	if key != "" {
+		t.Errorf("Expected no marker while the first command runs, got %q", key)
+	}
+
+	f.interrupt()
+	if marker.key != "c" {
+		t.Errorf("Expected marker %q once the commands completed, got %q", "c", marker.key)
+	}
+
+	// Objects found once interrupted are not run.
+	f.exec(contentMessage{Key: "0"}, marker.add("d"))
+	f.interrupt()
+	if marker.key != "c" {
+		t.Errorf("Expected marker %q after the interruption, got %q", "c", marker.key)
+	}
+	if f.invocations != 2 {
+		t.Errorf("Expected 2 invocations, got %d", f.invocations)
+	}
+}
@@ -64,6 +64,12 @@ var ioFlags = []cli.Flag{
 	},
 }
 
+// Flag common across commands which list recursively such as du, find, diff and mirror.
+var parallelListFlag = cli.IntFlag{
+	Name:  "parallel-list",
+	Usage: "list up to N prefixes of a bucket concurrently",
+}
+
 // registerCmd registers a cli command
 func registerCmd(cmd cli.Command) {
 	commands = append(commands, cmd)
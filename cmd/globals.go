@@ -59,6 +59,10 @@ var (
 
 	// CA root certificates, a nil value means system certs pool will be used
 	globalRootCAs *x509.CertPool
+
+	// Number of prefixes listed concurrently by recursive listings
+	// on object storage, set via --parallel-list
+	globalParallelList int
 )
 
 // Set global states. NOTE: It is deliberately kept monolithic to ensure we dont miss out any flags.
@@ -80,6 +84,16 @@ func setGlobals(quiet, debug, json, noColor, insecure bool) {
 	}
 }
 
+// setParallelListFromContext - sets the number of concurrent prefix
+// listings from the --parallel-list flag of the command.
+func setParallelListFromContext(ctx *cli.Context) {
+	parallel := ctx.Int("parallel-list")
+	if parallel < 0 {
+		fatalIf(errInvalidArgument().Trace(ctx.String("parallel-list")), "--parallel-list cannot be negative.")
+	}
+	globalParallelList = parallel
+}
+
 // Set global states. NOTE: It is deliberately kept monolithic to ensure we dont miss out any flags.
 func setGlobalsFromContext(ctx *cli.Context) error {
 	quiet := ctx.IsSet("quiet")
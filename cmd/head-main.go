@@ -18,12 +18,8 @@ package cmd
 
 import (
 	"bufio"
-	"compress/bzip2"
-	"compress/gzip"
 	"io"
-	"io/ioutil"
 	"os"
-	"strings"
 	"syscall"
 
 	"github.com/minio/cli"
@@ -37,6 +33,14 @@ var (
 			Usage: "print the first 'n' lines",
 			Value: 10,
 		},
+		cli.Int64Flag{
+			Name:  "c,bytes",
+			Usage: "print the first 'c' bytes instead of lines",
+		},
+		cli.BoolFlag{
+			Name:  "decompress",
+			Usage: "also detect 'gzip', 'bzip2' and 'zstd' compression from the object name, Content-Encoding or content",
+		},
 	}
 )
 
@@ -60,7 +64,8 @@ ENVIRONMENT VARIABLES:
   MC_ENCRYPT_KEY:  list of comma delimited prefix=secret values
 
 NOTE:
-  '{{.HelpName}}' automatically decompresses 'gzip', 'bzip2' compressed objects.
+  '{{.HelpName}}' automatically decompresses 'gzip', 'bzip2' compressed objects. With '--decompress',
+  the compression is also detected from the object name, its Content-Encoding or its content.
 
 EXAMPLES:
   1. Display only first line from a 'gzip' compressed object on Amazon S3.
@@ -72,35 +77,66 @@ EXAMPLES:
   3. Display only first line from server encrypted object on Amazon S3. In case the encryption key contains non-printable character like tab, pass the
      base64 encoded string as key.
      {{.Prompt}} {{.HelpName}} --encrypt-key "s3/json-data=MzJieXRlc2xvbmdzZWNyZXRrZQltdXN0YmVnaXZlbjE="  s3/json-data/population.json
+
+  4. Display only the first 100 bytes of an object.
+     {{.Prompt}} {{.HelpName}} --bytes 100 s3/csv-data/population.csv
 `,
 }
 
 // headURL displays contents of a URL to stdout.
-func headURL(sourceURL string, encKeyDB map[string][]prefixSSEPair, nlines int64) *probe.Error {
+func headURL(sourceURL string, encKeyDB map[string][]prefixSSEPair, nlines, nbytes int64, decompress bool) *probe.Error {
 	var reader io.ReadCloser
-	switch sourceURL {
-	case "-":
+	var err *probe.Error
+	switch {
+	case sourceURL == "-":
 		reader = os.Stdin
-	default:
-		var err *probe.Error
+		if decompress {
+			if reader, err = newDecompressReader(os.Stdin, "", nil); err != nil {
+				return err.Trace(sourceURL)
+			}
+		}
+	case decompress:
 		var metadata map[string]string
 		if reader, metadata, err = getSourceStreamMetadataFromURL(sourceURL, encKeyDB); err != nil {
 			return err.Trace(sourceURL)
 		}
-		ctype := metadata["Content-Type"]
-		if strings.Contains(ctype, "gzip") {
-			var e error
-			reader, e = gzip.NewReader(reader)
-			if e != nil {
-				return probe.NewError(e)
+		decompressReader, err := newDecompressReader(reader, sourceURL, metadata)
+		if err != nil {
+			reader.Close()
+			return err.Trace(sourceURL)
+		}
+		reader = decompressReader
+	case nbytes >= 0:
+		_, content, err := url2Stat(sourceURL, true, false, encKeyDB)
+		if err != nil {
+			return err.Trace(sourceURL)
+		}
+		ctype := content.Metadata["Content-Type"]
+		if isCompressedContentType(ctype) {
+			if reader, err = getSourceStreamFromURL(sourceURL, encKeyDB); err != nil {
+				return err.Trace(sourceURL)
+			}
+			if reader, err = newContentTypeDecompressReader(reader, ctype); err != nil {
+				return err.Trace(sourceURL)
 			}
-			defer reader.Close()
-		} else if strings.Contains(ctype, "bzip") {
-			defer reader.Close()
-			reader = ioutil.NopCloser(bzip2.NewReader(reader))
-		} else {
-			defer reader.Close()
+			break
 		}
+		// Only the first bytes are downloaded.
+		if reader, err = getSourceStreamRangeFromURL(sourceURL, 0, nbytes, encKeyDB); err != nil {
+			return err.Trace(sourceURL)
+		}
+	default:
+		var metadata map[string]string
+		if reader, metadata, err = getSourceStreamMetadataFromURL(sourceURL, encKeyDB); err != nil {
+			return err.Trace(sourceURL)
+		}
+		if reader, err = newContentTypeDecompressReader(reader, metadata["Content-Type"]); err != nil {
+			return err.Trace(sourceURL)
+		}
+	}
+	defer reader.Close()
+	if nbytes >= 0 {
+		return catOut(io.LimitReader(reader, nbytes), -1).Trace(sourceURL)
 	}
 	return headOut(reader, nlines).Trace(sourceURL)
 }
@@ -161,15 +197,23 @@ func mainHead(ctx *cli.Context) error {
 		stdinMode = true
 	}
 
+	// Negative number of bytes means lines are printed.
+	nbytes := int64(-1)
+	if ctx.IsSet("bytes") {
+		if nbytes = ctx.Int64("bytes"); nbytes < 0 {
+			fatalIf(errInvalidArgument().Trace(ctx.String("bytes")), "Number of bytes cannot be negative.")
+		}
+	}
+
 	// handle std input data.
 	if stdinMode {
-		fatalIf(headOut(os.Stdin, ctx.Int64("lines")).Trace(), "Unable to read from standard input.")
+		fatalIf(headURL("-", encKeyDB, ctx.Int64("lines"), nbytes, ctx.Bool("decompress")).Trace(), "Unable to read from standard input.")
 		return nil
 	}
 
 	// Convert arguments to URLs: expand alias, fix format.
 	for _, url := range ctx.Args() {
-		fatalIf(headURL(url, encKeyDB, ctx.Int64("lines")).Trace(url), "Unable to read from `"+url+"`.")
+		fatalIf(headURL(url, encKeyDB, ctx.Int64("lines"), nbytes, ctx.Bool("decompress")).Trace(url), "Unable to read from `"+url+"`.")
 	}
 
 	return nil
@@ -0,0 +1,49 @@
+/*
+ * MinIO Client (C) 2019 MinIO, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import "github.com/minio/cli"
+
+var legalHoldClearCmd = cli.Command{
+	Name:   "clear",
+	Usage:  "lift legal hold of objects",
+	Action: mainLegalHoldClear,
+	Before: setGlobalsFromContext,
+	Flags:  append(legalHoldFlags, globalFlags...),
+	CustomHelpTemplate: `NAME:
+  {{.HelpName}} - {{.Usage}}
+
+USAGE:
+  {{.HelpName}} [FLAGS] TARGET
+
+FLAGS:
+  {{range .VisibleFlags}}{{.}}
+  {{end}}
+EXAMPLES:
+  1. Lift legal hold of an object.
+     {{.Prompt}} {{.HelpName}} myminio/mybucket/contracts/2019/acme.pdf
+
+  2. Lift legal hold of all objects with the prefix "contracts/2019/" older than a year.
+     {{.Prompt}} {{.HelpName}} --recursive --older-than 365d myminio/mybucket/contracts/2019/
+`,
+}
+
+// mainLegalHoldClear is the handle for "mc legalhold clear" command.
+func mainLegalHoldClear(ctx *cli.Context) error {
+	checkLegalHoldSyntax(ctx, "clear")
+	return setLegalHold(ctx, legalHoldOff)
+}
@@ -0,0 +1,73 @@
+/*
+ * MinIO Client (C) 2019 MinIO, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"github.com/fatih/color"
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/console"
+	"github.com/minio/mc/pkg/probe"
+)
+
+var legalHoldInfoCmd = cli.Command{
+	Name:   "info",
+	Usage:  "show legal hold status of objects",
+	Action: mainLegalHoldInfo,
+	Before: setGlobalsFromContext,
+	Flags:  append(legalHoldFlags, globalFlags...),
+	CustomHelpTemplate: `NAME:
+  {{.HelpName}} - {{.Usage}}
+
+USAGE:
+  {{.HelpName}} [FLAGS] TARGET
+
+FLAGS:
+  {{range .VisibleFlags}}{{.}}
+  {{end}}
+EXAMPLES:
+  1. Show legal hold status of an object.
+     {{.Prompt}} {{.HelpName}} myminio/mybucket/contracts/2019/acme.pdf
+
+  2. Show legal hold status of all objects with the prefix "contracts/".
+     {{.Prompt}} {{.HelpName}} --recursive myminio/mybucket/contracts/
+`,
+}
+
+// mainLegalHoldInfo is the handle for "mc legalhold info" command.
+func mainLegalHoldInfo(ctx *cli.Context) error {
+	checkLegalHoldSyntax(ctx, "info")
+
+	console.SetColor("LegalHoldOn", color.New(color.FgGreen, color.Bold))
+	console.SetColor("LegalHoldOff", color.New(color.FgYellow))
+
+	return walkLegalHold(ctx, func(clnt Client, urlStr string) *probe.Error {
+		status, err := clnt.GetObjectLegalHold()
+		if err != nil {
+			return err
+		}
+		if status == "" {
+			status = legalHoldOff
+		}
+		printMsg(legalHoldMessage{
+			Status:    "success",
+			URL:       urlStr,
+			LegalHold: status,
+			isInfo:    true,
+		})
+		return nil
+	})
+}
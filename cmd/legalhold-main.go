@@ -0,0 +1,183 @@
+/*
+ * MinIO Client (C) 2019 MinIO, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/fatih/color"
+	"github.com/minio/cli"
+	json "github.com/minio/mc/pkg/colorjson"
+	"github.com/minio/mc/pkg/console"
+	"github.com/minio/mc/pkg/probe"
+)
+
+// Flags common across legal hold sub-commands.
+var legalHoldFlags = []cli.Flag{
+	cli.BoolFlag{
+		Name:  "recursive, r",
+		Usage: "apply to all objects with the prefix",
+	},
+	cli.StringFlag{
+		Name:  "older-than",
+		Usage: "only objects older than L days, M hours and N minutes",
+	},
+	cli.StringFlag{
+		Name:  "newer-than",
+		Usage: "only objects newer than L days, M hours and N minutes",
+	},
+}
+
+var legalHoldCmd = cli.Command{
+	Name:            "legalhold",
+	Usage:           "manage legal hold of objects",
+	HideHelpCommand: true,
+	Action:          mainLegalHold,
+	Before:          setGlobalsFromContext,
+	Flags:           globalFlags,
+	Subcommands: []cli.Command{
+		legalHoldSetCmd,
+		legalHoldClearCmd,
+		legalHoldInfoCmd,
+	},
+}
+
+// mainLegalHold is the handle for "mc legalhold" command.
+func mainLegalHold(ctx *cli.Context) error {
+	cli.ShowCommandHelp(ctx, ctx.Args().First())
+	return nil
+	// Sub-commands like "set", "clear", "info" have their own main.
+}
+
+// legalHoldMessage - legal hold status of an object.
+type legalHoldMessage struct {
+	Status    string          `json:"status"`
+	URL       string          `json:"url"`
+	LegalHold legalHoldStatus `json:"legalHold"`
+	// isInfo is set when the status is printed rather than changed.
+	isInfo bool
+}
+
+// Colorized message for console printing.
+func (m legalHoldMessage) String() string {
+	if m.isInfo {
+		status := console.Colorize("LegalHoldOff", fmt.Sprintf("%-3s", m.LegalHold))
+		if m.LegalHold == legalHoldOn {
+			status = console.Colorize("LegalHoldOn", fmt.Sprintf("%-3s", m.LegalHold))
+		}
+		return fmt.Sprintf("[%s] %s", status, m.URL)
+	}
+	if m.LegalHold == legalHoldOn {
+		return console.Colorize("LegalHoldOn", "Legal hold placed on `"+m.URL+"`.")
+	}
+	return console.Colorize("LegalHoldOff", "Legal hold lifted on `"+m.URL+"`.")
+}
+
+// JSON'ified message for scripting.
+func (m legalHoldMessage) JSON() string {
+	m.Status = "success"
+	msgBytes, e := json.MarshalIndent(m, "", " ")
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+	return string(msgBytes)
+}
+
+// checkLegalHoldSyntax - validate all the passed arguments of a sub-command.
+func checkLegalHoldSyntax(ctx *cli.Context, subCommand string) {
+	if len(ctx.Args()) != 1 {
+		cli.ShowCommandHelpAndExit(ctx, subCommand, 1) // last argument is exit code
+	}
+	if ctx.String("older-than") != "" {
+		isOlder(UTCNow(), ctx.String("older-than"))
+	}
+	if ctx.String("newer-than") != "" {
+		isNewer(UTCNow(), ctx.String("newer-than"))
+	}
+}
+
+// walkLegalHold - calls fn with a client of each object of the target
+// which matches the filters, objects are listed when recursive.
+func walkLegalHold(ctx *cli.Context, fn func(clnt Client, urlStr string) *probe.Error) error {
+	urlStr := ctx.Args().First()
+	olderThan, newerThan := ctx.String("older-than"), ctx.String("newer-than")
+
+	clnt, err := newClient(urlStr)
+	fatalIf(err.Trace(urlStr), "Unable to initialize target `"+urlStr+"`.")
+	alias, _, _ := mustExpandAlias(urlStr)
+
+	var cErr error
+	var contentCh <-chan *clientContent
+	if ctx.Bool("recursive") {
+		contentCh = clnt.List(true, false, false, DirNone)
+	} else {
+		statCh := make(chan *clientContent, 1)
+		content, err := clnt.Stat(false, false, false, nil)
+		if err != nil {
+			content = &clientContent{Err: err}
+		}
+		statCh <- content
+		close(statCh)
+		contentCh = statCh
+	}
+
+	for content := range contentCh {
+		if content.Err != nil {
+			errorIf(content.Err.Trace(urlStr), "Unable to list `"+urlStr+"`.")
+			cErr = exitStatus(globalErrorExitStatus)
+			continue
+		}
+		if content.Type.IsDir() {
+			continue
+		}
+		// Skip objects older than --older-than parameter, if specified
+		if olderThan != "" && isOlder(content.Time, olderThan) {
+			continue
+		}
+		// Skip objects newer than --newer-than parameter, if specified
+		if newerThan != "" && isNewer(content.Time, newerThan) {
+			continue
+		}
+
+		objectURL := content.URL.String()
+		objectClnt, err := newClientFromAlias(alias, objectURL)
+		if err == nil {
+			err = fn(objectClnt, objectURL)
+		}
+		if err != nil {
+			errorIf(err.Trace(objectURL), "Unable to access legal hold of `"+objectURL+"`.")
+			cErr = exitStatus(globalErrorExitStatus)
+		}
+	}
+	return cErr
+}
+
+// setLegalHold - places or lifts the legal hold of the objects of the target.
+func setLegalHold(ctx *cli.Context, status legalHoldStatus) error {
+	console.SetColor("LegalHoldOn", color.New(color.FgGreen, color.Bold))
+	console.SetColor("LegalHoldOff", color.New(color.FgYellow, color.Bold))
+
+	return walkLegalHold(ctx, func(clnt Client, urlStr string) *probe.Error {
+		if err := clnt.PutObjectLegalHold(status); err != nil {
+			return err
+		}
+		printMsg(legalHoldMessage{
+			Status:    "success",
+			URL:       urlStr,
+			LegalHold: status,
+		})
+		return nil
+	})
+}
@@ -0,0 +1,52 @@
+/*
+ * MinIO Client (C) 2019 MinIO, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import "github.com/minio/cli"
+
+var legalHoldSetCmd = cli.Command{
+	Name:   "set",
+	Usage:  "place legal hold on objects",
+	Action: mainLegalHoldSet,
+	Before: setGlobalsFromContext,
+	Flags:  append(legalHoldFlags, globalFlags...),
+	CustomHelpTemplate: `NAME:
+  {{.HelpName}} - {{.Usage}}
+
+USAGE:
+  {{.HelpName}} [FLAGS] TARGET
+
+FLAGS:
+  {{range .VisibleFlags}}{{.}}
+  {{end}}
+EXAMPLES:
+  1. Place legal hold on an object.
+     {{.Prompt}} {{.HelpName}} myminio/mybucket/contracts/2019/acme.pdf
+
+  2. Place legal hold on all objects with the prefix "contracts/2019/".
+     {{.Prompt}} {{.HelpName}} --recursive myminio/mybucket/contracts/2019/
+
+  3. Place legal hold on all objects with the prefix "mail/" modified in the last 30 days.
+     {{.Prompt}} {{.HelpName}} --recursive --newer-than 30d myminio/mybucket/mail/
+`,
+}
+
+// mainLegalHoldSet is the handle for "mc legalhold set" command.
+func mainLegalHoldSet(ctx *cli.Context) error {
+	checkLegalHoldSyntax(ctx, "set")
+	return setLegalHold(ctx, legalHoldOn)
+}
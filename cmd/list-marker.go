@@ -0,0 +1,177 @@
+/*
+ * MinIO Client (C) 2019 MinIO, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"os"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/minio/cli"
+	json "github.com/minio/mc/pkg/colorjson"
+	"github.com/minio/mc/pkg/console"
+	"github.com/minio/mc/pkg/probe"
+)
+
+// Number of entries listed between two continuation markers.
+const listMarkerInterval = 1000
+
+// listMarkerMessage container for the continuation marker of a listing.
+type listMarkerMessage struct {
+	Status string `json:"status"`
+	Type   string `json:"type"`
+	Marker string `json:"marker"`
+}
+
+// String colorized continuation marker message.
+func (m listMarkerMessage) String() string {
+	return console.Colorize("Marker", "Listing stopped, resume with --start-after `"+m.Marker+"`.")
+}
+
+// JSON jsonified continuation marker message.
+func (m listMarkerMessage) JSON() string {
+	m.Status = "success"
+	m.Type = "marker"
+	jsonMessageBytes, e := json.MarshalIndent(m, "", " ")
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+
+	return string(jsonMessageBytes)
+}
+
+// listMarker tracks the key of the last listed entry, so that a long
+// listing can be resumed with --start-after. In JSON mode the marker
+// is printed every listMarkerInterval entries, in all modes it is
+// printed when the listing stops early.
+type listMarker struct {
+	mutex sync.Mutex
+	key   string
+	count int
+
+	// entries handled asynchronously, in listing order.
+	pending []*listMarkerEntry
+	wait    func()
+}
+
+// listMarkerEntry is a listed entry which may not be handled yet.
+type listMarkerEntry struct {
+	key  string
+	done bool
+}
+
+// newListMarker - returns a marker printed on interruption.
+func newListMarker() *listMarker {
+	m := &listMarker{}
+	trapCh := signalTrap(os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-trapCh
+		m.mutex.Lock()
+		wait := m.wait
+		m.mutex.Unlock()
+		if wait != nil {
+			wait()
+		}
+		m.print()
+		os.Exit(globalErrorExitStatus)
+	}()
+	return m
+}
+
+// waitOnInterrupt - sets a function waiting for the entries being
+// handled when the listing is interrupted, before the marker is printed.
+func (m *listMarker) waitOnInterrupt(wait func()) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.wait = wait
+}
+
+// isListingAllBuckets - returns true if clnt lists all the buckets of an
+// alias, such listings cannot be resumed since marker keys are relative
+// to their bucket.
+func isListingAllBuckets(clnt Client) bool {
+	u := clnt.GetURL()
+	return u.Type == objectStorage && strings.Trim(u.Path, string(u.Separator)) == ""
+}
+
+// checkStartAfterSyntax - validates --start-after for the listed URLs.
+func checkStartAfterSyntax(ctx *cli.Context, urls []string) {
+	if ctx.String("start-after") == "" {
+		return
+	}
+	for _, url := range urls {
+		clnt, err := newClient(url)
+		fatalIf(err.Trace(url), "Unable to initialize target `"+url+"`.")
+		if isListingAllBuckets(clnt) {
+			fatalIf(errInvalidArgument().Trace(url), "--start-after cannot be used when listing all buckets of `"+url+"`, list a bucket instead.")
+		}
+	}
+}
+
+// listMarkerKey - returns the key to resume a listing after this content,
+// which is the object key within its bucket or the path of a file.
+func listMarkerKey(clnt Client, content *clientContent) string {
+	if clnt.GetURL().Type != objectStorage {
+		return content.URL.Path
+	}
+	separator := string(clnt.GetURL().Separator)
+	return splitStr(strings.TrimPrefix(content.URL.Path, separator), separator, 2)[1]
+}
+
+// update - records the key of the last listed entry.
+func (m *listMarker) update(key string) {
+	m.add(key)()
+}
+
+// add - records a listed entry handled asynchronously, the returned
+// function is called once it is handled. The marker only advances past
+// an entry once it and all the entries listed before it are handled.
+// A nil marker records nothing.
+func (m *listMarker) add(key string) (done func()) {
+	if m == nil {
+		return func() {}
+	}
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	entry := &listMarkerEntry{key: key}
+	m.pending = append(m.pending, entry)
+	return func() {
+		m.mutex.Lock()
+		defer m.mutex.Unlock()
+		entry.done = true
+		for len(m.pending) > 0 && m.pending[0].done {
+			m.key = m.pending[0].key
+			m.count++
+			if globalJSON && m.count%listMarkerInterval == 0 {
+				printMsg(listMarkerMessage{Marker: m.key})
+			}
+			m.pending[0] = nil
+			m.pending = m.pending[1:]
+		}
+	}
+}
+
+// print - prints the marker of the last listed entry, if any.
+func (m *listMarker) print() {
+	if m == nil {
+		return
+	}
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if m.key != "" {
+		printMsg(listMarkerMessage{Marker: m.key})
+	}
+}
@@ -100,11 +100,6 @@ func lock(urlStr string, mode *minio.RetentionMode, validity *uint, unit *minio.
 		fatalIf(err.Trace(), "Cannot parse the provided url.")
 	}
 
-	s3Client, ok := client.(*s3Client)
-	if !ok {
-		fatalIf(errDummy().Trace(), "The provided url doesn't point to a S3 server.")
-	}
-
 	validityStr := func() *string {
 		if validity == nil {
 			return nil
@@ -119,10 +114,10 @@ func lock(urlStr string, mode *minio.RetentionMode, validity *uint, unit *minio.
 	}
 
 	if clearLock || mode != nil {
-		err = s3Client.SetObjectLockConfig(mode, validity, unit)
+		err = client.SetObjectLockConfig(mode, validity, unit)
 		fatalIf(err, "Cannot enable object lock configuration on the specified bucket.")
 	} else {
-		mode, validity, unit, err = s3Client.GetObjectLockConfig()
+		mode, validity, unit, err = client.GetObjectLockConfig()
 		fatalIf(err, "Cannot get object lock configuration on the specified bucket.")
 	}
 
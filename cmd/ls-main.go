@@ -35,6 +35,26 @@ var (
 			Name:  "incomplete, I",
 			Usage: "list incomplete uploads",
 		},
+		cli.StringFlag{
+			Name:  "sort",
+			Usage: "sort entries by 'name', 'size' or 'time'",
+		},
+		cli.BoolFlag{
+			Name:  "reverse",
+			Usage: "reverse the order of entries",
+		},
+		cli.BoolFlag{
+			Name:  "long, l",
+			Usage: "show storage class, ETag, content type, encryption, retention and legal hold of entries",
+		},
+		cli.StringFlag{
+			Name:  "start-after",
+			Usage: "list only objects after KEY, to resume an interrupted recursive listing",
+		},
+		cli.BoolFlag{
+			Name:  "summarize",
+			Usage: "display the total number of objects and their total size at the end",
+		},
 	}
 )
 
@@ -72,6 +92,17 @@ EXAMPLES:
 
   6. List incomplete (previously failed) uploads of objects on Amazon S3.
      {{.Prompt}} {{.HelpName}} --incomplete s3/mybucket
+
+  7. List the largest objects of mybucket first, along with the totals.
+     {{.Prompt}} {{.HelpName}} --recursive --sort size --reverse --summarize s3/mybucket
+
+  8. List the contents of mybucket in long format, showing storage class, ETag, content type, the
+     indicators "E" for encrypted objects, "G" or "C" for objects under governance or compliance retention,
+     "L" for objects under legal hold and the retain until date.
+     {{.Prompt}} {{.HelpName}} --long s3/mybucket
+
+  9. Resume an interrupted recursive listing of mybucket after the key of the last continuation marker.
+     {{.Prompt}} {{.HelpName}} --recursive --json --start-after "photos/2019/12/31.jpg" s3/mybucket
 `,
 }
 
@@ -86,6 +117,18 @@ func checkListSyntax(ctx *cli.Context) {
 			fatalIf(errInvalidArgument().Trace(args...), "Unable to validate empty argument.")
 		}
 	}
+	switch ctx.String("sort") {
+	case "", "name", "size", "time":
+	default:
+		fatalIf(errInvalidArgument().Trace(ctx.String("sort")), "Unable to sort by `"+ctx.String("sort")+"`, use 'name', 'size' or 'time'.")
+	}
+
+	// Object metadata is not listed after a start key.
+	if ctx.String("start-after") != "" && ctx.Bool("long") {
+		fatalIf(errInvalidArgument(), "--start-after cannot be used with --long.")
+	}
+	checkStartAfterSyntax(ctx, args)
+
 	// extract URLs.
 	URLs := ctx.Args()
 	isIncomplete := ctx.Bool("incomplete")
@@ -110,6 +153,12 @@ func mainList(ctx *cli.Context) error {
 	console.SetColor("Dir", color.New(color.FgCyan, color.Bold))
 	console.SetColor("Size", color.New(color.FgYellow))
 	console.SetColor("Time", color.New(color.FgGreen))
+	console.SetColor("StorageClass", color.New(color.FgBlue))
+	console.SetColor("ETag", color.New(color.FgMagenta))
+	console.SetColor("ContentType", color.New(color.FgCyan))
+	console.SetColor("RetainUntil", color.New(color.FgRed))
+	console.SetColor("Summary", color.New(color.Bold))
+	console.SetColor("Marker", color.New(color.FgYellow))
 
 	// check 'ls' cli arguments.
 	checkListSyntax(ctx)
@@ -117,6 +166,18 @@ func mainList(ctx *cli.Context) error {
 	// Set command flags from context.
 	isRecursive := ctx.Bool("recursive")
 	isIncomplete := ctx.Bool("incomplete")
+	opts := lsOptions{
+		isRecursive:  isRecursive,
+		isIncomplete: isIncomplete,
+		isLong:       ctx.Bool("long"),
+		isReverse:    ctx.Bool("reverse"),
+		sortBy:       ctx.String("sort"),
+		startAfter:   ctx.String("start-after"),
+	}
+	// Recursive listings in listing order can be resumed from a marker.
+	if isRecursive && opts.sortBy == "" && !opts.isReverse {
+		opts.marker = newListMarker()
+	}
 
 	args := ctx.Args()
 	// mimic operating system tool behavior.
@@ -124,6 +185,7 @@ func mainList(ctx *cli.Context) error {
 		args = []string{"."}
 	}
 
+	var summary lsSummaryMessage
 	var cErr error
 	for _, targetURL := range args {
 		clnt, err := newClient(targetURL)
@@ -139,9 +201,15 @@ func mainList(ctx *cli.Context) error {
 			}
 		}
 
-		if e := doList(clnt, isRecursive, isIncomplete); e != nil {
+		targetSummary, e := doList(clnt, opts)
+		if e != nil {
 			cErr = e
 		}
+		summary.Objects += targetSummary.Objects
+		summary.Size += targetSummary.Size
+	}
+	if ctx.Bool("summarize") {
+		printMsg(summary)
 	}
 	return cErr
 }
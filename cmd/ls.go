@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"path/filepath"
 	"runtime"
+	"sort"
 	"strings"
 	"time"
 
@@ -36,12 +37,18 @@ const (
 
 // contentMessage container for content message structure.
 type contentMessage struct {
-	Status   string    `json:"status"`
-	Filetype string    `json:"type"`
-	Time     time.Time `json:"lastModified"`
-	Size     int64     `json:"size"`
-	Key      string    `json:"key"`
-	ETag     string    `json:"etag"`
+	Status        string    `json:"status"`
+	Filetype      string    `json:"type"`
+	Time          time.Time `json:"lastModified"`
+	Size          int64     `json:"size"`
+	Key           string    `json:"key"`
+	ETag          string    `json:"etag"`
+	StorageClass  string    `json:"storageClass,omitempty"`
+	ContentType   string    `json:"contentType,omitempty"`
+	Encrypted     bool      `json:"encrypted,omitempty"`
+	RetentionMode string    `json:"retentionMode,omitempty"`
+	RetainUntil   string    `json:"retainUntilDate,omitempty"`
+	LegalHold     string    `json:"legalHold,omitempty"`
 }
 
 // String colorized string message.
@@ -66,6 +73,82 @@ func (c contentMessage) JSON() string {
 	return string(jsonMessageBytes)
 }
 
+// longContentMessage container for content message printed in long format.
+type longContentMessage struct {
+	contentMessage
+}
+
+// String colorized string message in long format, the indicators
+// column shows "E" for encrypted objects, "G" or "C" for objects
+// under governance or compliance retention and "L" for objects
+// under legal hold, followed by the retain until date.
+func (c longContentMessage) String() string {
+	dash := func(value string) string {
+		if value == "" {
+			return "-"
+		}
+		return value
+	}
+	indicators := []byte("---")
+	if c.Encrypted {
+		indicators[0] = 'E'
+	}
+	if c.RetentionMode != "" {
+		indicators[1] = c.RetentionMode[0]
+	}
+	if c.LegalHold == string(legalHoldOn) {
+		indicators[2] = 'L'
+	}
+	retainUntil := "-"
+	if t, e := time.Parse(time.RFC3339, c.RetainUntil); e == nil {
+		retainUntil = t.Local().Format("2006-01-02")
+	}
+
+	message := console.Colorize("Time", fmt.Sprintf("[%s] ", c.Time.Format(printDate)))
+	message = message + console.Colorize("Size", fmt.Sprintf("%7s ", strings.Join(strings.Fields(humanize.IBytes(uint64(c.Size))), "")))
+	message = message + console.Colorize("StorageClass", fmt.Sprintf("%-8s ", dash(c.StorageClass)))
+	message = message + console.Colorize("ETag", fmt.Sprintf("%-32s ", dash(c.ETag)))
+	message = message + console.Colorize("ContentType", fmt.Sprintf("%-24s ", dash(c.ContentType)))
+	message = message + fmt.Sprintf("%s ", indicators)
+	message = message + console.Colorize("RetainUntil", fmt.Sprintf("%-10s ", retainUntil))
+	if c.Filetype == "folder" {
+		return message + console.Colorize("Dir", c.Key)
+	}
+	return message + console.Colorize("File", c.Key)
+}
+
+// lsSummaryMessage container for the totals of listed objects.
+type lsSummaryMessage struct {
+	Status  string `json:"status"`
+	Objects int64  `json:"totalObjects"`
+	Size    int64  `json:"totalSize"`
+}
+
+// String colorized string message.
+func (s lsSummaryMessage) String() string {
+	return console.Colorize("Summary", fmt.Sprintf("Total: %d object(s), %s", s.Objects,
+		strings.Join(strings.Fields(humanize.IBytes(uint64(s.Size))), "")))
+}
+
+// JSON jsonified summary message.
+func (s lsSummaryMessage) JSON() string {
+	s.Status = "success"
+	jsonMessageBytes, e := json.MarshalIndent(s, "", " ")
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+
+	return string(jsonMessageBytes)
+}
+
+// lookupMetadata returns the value of a metadata key, regardless of its case.
+func lookupMetadata(metadata map[string]string, key string) (string, bool) {
+	for k, v := range metadata {
+		if strings.EqualFold(k, key) {
+			return v, true
+		}
+	}
+	return "", false
+}
+
 // parseContent parse client Content container into printer struct.
 func parseContent(c *clientContent) contentMessage {
 	content := contentMessage{}
@@ -83,6 +166,29 @@ func parseContent(c *clientContent) contentMessage {
 	md5sum := strings.TrimPrefix(c.ETag, "\"")
 	md5sum = strings.TrimSuffix(md5sum, "\"")
 	content.ETag = md5sum
+	content.StorageClass = c.StorageClass
+
+	// Headers are only available when listed with metadata.
+	for _, metadata := range []map[string]string{c.Metadata, c.UserMetadata, c.EncryptionHeaders} {
+		if contentType, ok := lookupMetadata(metadata, "Content-Type"); ok && content.ContentType == "" {
+			content.ContentType = contentType
+		}
+		if _, ok := lookupMetadata(metadata, serverEncryptionKeyPrefix); ok {
+			content.Encrypted = true
+		}
+		if _, ok := lookupMetadata(metadata, serverEncryptionKeyPrefix+"-Customer-Algorithm"); ok {
+			content.Encrypted = true
+		}
+		if mode, ok := lookupMetadata(metadata, AmzObjectLockMode); ok && mode != "" {
+			content.RetentionMode = strings.ToUpper(mode)
+		}
+		if retainUntil, ok := lookupMetadata(metadata, AmzObjectLockRetainUntilDate); ok {
+			content.RetainUntil = retainUntil
+		}
+		if legalHold, ok := lookupMetadata(metadata, AmzObjectLockLegalHold); ok {
+			content.LegalHold = strings.ToUpper(legalHold)
+		}
+	}
 	// Convert OS Type to match console file printing style.
 	content.Key = getKey(c)
 	return content
@@ -104,15 +210,70 @@ func getKey(c *clientContent) string {
 	return c.URL.Path
 }
 
-// doList - list all entities inside a folder.
-func doList(clnt Client, isRecursive, isIncomplete bool) error {
+// lsOptions holds the listing and printing options of ls.
+type lsOptions struct {
+	isRecursive  bool
+	isIncomplete bool
+	isLong       bool
+	isReverse    bool
+	sortBy       string
+	startAfter   string
+	// marker is only set when a continuation marker is emitted.
+	marker *listMarker
+}
+
+// sortContents - sorts contents by name, size or time, entries
+// which are equal are sorted by name.
+func sortContents(contents []contentMessage, sortBy string, isReverse bool) {
+	less := func(i, j int) bool {
+		switch sortBy {
+		case "size":
+			if contents[i].Size != contents[j].Size {
+				return contents[i].Size < contents[j].Size
+			}
+		case "time":
+			if !contents[i].Time.Equal(contents[j].Time) {
+				return contents[i].Time.Before(contents[j].Time)
+			}
+		}
+		return contents[i].Key < contents[j].Key
+	}
+	if sortBy != "" {
+		sort.SliceStable(contents, less)
+	}
+	if isReverse {
+		for i, j := 0, len(contents)-1; i < j; i, j = i+1, j-1 {
+			contents[i], contents[j] = contents[j], contents[i]
+		}
+	}
+}
+
+// printContent - prints the content in the requested format.
+func printContent(content contentMessage, isLong bool) {
+	if isLong {
+		printMsg(longContentMessage{content})
+		return
+	}
+	printMsg(content)
+}
+
+// doList - list all entities inside a folder, contents are buffered
+// only when they need to be sorted.
+func doList(clnt Client, opts lsOptions) (lsSummaryMessage, error) {
 	prefixPath := clnt.GetURL().Path
 	separator := string(clnt.GetURL().Separator)
 	if !strings.HasSuffix(prefixPath, separator) {
 		prefixPath = prefixPath[:strings.LastIndex(prefixPath, separator)+1]
 	}
+	isSorted := opts.sortBy != "" || opts.isReverse
+	if isListingAllBuckets(clnt) {
+		opts.marker = nil
+	}
+
+	var summary lsSummaryMessage
+	var contents []contentMessage
 	var cErr error
-	for content := range clnt.List(isRecursive, isIncomplete, false, DirNone) {
+	for content := range clnt.ListFrom(opts.startAfter, opts.isRecursive, opts.isIncomplete, opts.isLong, DirNone) {
 		if content.Err != nil {
 			switch content.Err.ToGoError().(type) {
 			// handle this specifically for filesystem related errors.
@@ -131,12 +292,16 @@ func doList(clnt Client, isRecursive, isIncomplete bool) error {
 			}
 			errorIf(content.Err.Trace(clnt.GetURL().String()), "Unable to list folder.")
 			cErr = exitStatus(globalErrorExitStatus) // Set the exit status.
+			if opts.marker != nil {
+				opts.marker.print()
+			}
 			continue
 		}
 
 		if content.StorageClass == s3StorageClassGlacier {
 			continue
 		}
+		markerKey := listMarkerKey(clnt, content)
 
 		// Convert any os specific delimiters to "/".
 		contentURL := filepath.ToSlash(content.URL.Path)
@@ -148,8 +313,30 @@ func doList(clnt Client, isRecursive, isIncomplete bool) error {
 		contentURL = strings.TrimPrefix(contentURL, prefixPath)
 		content.URL.Path = contentURL
 		parsedContent := parseContent(content)
+		if opts.isLong && parsedContent.ContentType == "" && !content.Type.IsDir() &&
+			clnt.GetURL().Type == fileSystem {
+			parsedContent.ContentType = guessURLContentType(contentURL)
+		}
+
+		if !content.Type.IsDir() {
+			summary.Objects++
+			summary.Size += content.Size
+		}
+
+		if isSorted {
+			contents = append(contents, parsedContent)
+			continue
+		}
 		// Print colorized or jsonized content info.
-		printMsg(parsedContent)
+		printContent(parsedContent, opts.isLong)
+		if opts.marker != nil {
+			opts.marker.update(markerKey)
+		}
+	}
+
+	sortContents(contents, opts.sortBy, opts.isReverse)
+	for _, content := range contents {
+		printContent(content, opts.isLong)
 	}
-	return cErr
+	return summary, cErr
 }
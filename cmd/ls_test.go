@@ -15,3 +15,107 @@
  */
 
 package cmd
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/minio/mc/pkg/probe"
+)
+
+// Tests sorting of listed contents.
+func TestSortContents(t *testing.T) {
+	contents := []contentMessage{
+		{Key: "b", Size: 10, Time: time.Unix(300, 0)},
+		{Key: "a", Size: 30, Time: time.Unix(200, 0)},
+		{Key: "c", Size: 10, Time: time.Unix(100, 0)},
+	}
+	testCases := []struct {
+		sortBy    string
+		isReverse bool
+		expected  []string
+	}{
+		{"", false, []string{"b", "a", "c"}},
+		{"", true, []string{"c", "a", "b"}},
+		{"name", false, []string{"a", "b", "c"}},
+		{"size", false, []string{"b", "c", "a"}},
+		{"size", true, []string{"a", "c", "b"}},
+		{"time", false, []string{"c", "a", "b"}},
+	}
+	for i, testCase := range testCases {
+		sorted := append([]contentMessage{}, contents...)
+		sortContents(sorted, testCase.sortBy, testCase.isReverse)
+		var keys []string
+		for _, content := range sorted {
+			keys = append(keys, content.Key)
+		}
+		if !reflect.DeepEqual(keys, testCase.expected) {
+			t.Errorf("Test %d: expected %v, got %v", i+1, testCase.expected, keys)
+		}
+	}
+}
+
+// Tests long format fields parsed from the metadata of listed contents.
+func TestParseContentMetadata(t *testing.T) {
+	content := parseContent(&clientContent{
+		URL:          clientURL{Path: "bucket/object"},
+		ETag:         `"d41d8cd98f00b204e9800998ecf8427e"`,
+		StorageClass: "STANDARD_IA",
+		UserMetadata: map[string]string{
+			"content-type":                 "application/pdf",
+			"X-Amz-Server-Side-Encryption": "AES256",
+			"X-Amz-Object-Lock-Mode":       "governance",
+			"X-Amz-Object-Lock-Legal-Hold": "ON",
+		},
+	})
+	expected := contentMessage{
+		Filetype:      "file",
+		Time:          time.Time{}.Local(),
+		Key:           "bucket/object",
+		ETag:          "d41d8cd98f00b204e9800998ecf8427e",
+		StorageClass:  "STANDARD_IA",
+		ContentType:   "application/pdf",
+		Encrypted:     true,
+		RetentionMode: "GOVERNANCE",
+		LegalHold:     "ON",
+	}
+	if !reflect.DeepEqual(content, expected) {
+		t.Errorf("expected %+v, got %+v", expected, content)
+	}
+}
+
+// Tests detection of listings of all the buckets of an alias.
+func TestIsListingAllBuckets(t *testing.T) {
+	defer func(load func() (*configV9, *probe.Error)) { loadMcConfig = load }(loadMcConfig)
+	loadMcConfig = func() (*configV9, *probe.Error) { return newMcConfig(), nil }
+
+	testCases := []struct {
+		url      string
+		expected bool
+	}{
+		{"play", true},
+		{"play/", true},
+		{"play/bucket", false},
+		{"play/bucket/prefix/", false},
+		{"/", false},
+		{".", false},
+	}
+	for i, testCase := range testCases {
+		clnt, err := newClient(testCase.url)
+		if err != nil {
+			t.Fatalf("Test %d: unable to initialize %s: %v", i+1, testCase.url, err)
+		}
+		if got := isListingAllBuckets(clnt); got != testCase.expected {
+			t.Errorf("Test %d: expected %v for %s, got %v", i+1, testCase.expected, testCase.url, got)
+		}
+	}
+}
+
+// Tests that a nil marker records and prints nothing.
+func TestNilListMarker(t *testing.T) {
+	var marker *listMarker
+	marker.update("a")
+	marker.add("b")()
+	marker.print()
+}
@@ -313,8 +313,10 @@ var appCmds = []cli.Command{
 	rbCmd,
 	cpCmd,
 	mirrorCmd,
+	syncCmd,
 	catCmd,
 	headCmd,
+	tailCmd,
 	pipeCmd,
 	shareCmd,
 	findCmd,
@@ -324,11 +326,13 @@ var appCmds = []cli.Command{
 	duCmd,
 	lockCmd,
 	retentionCmd,
+	legalHoldCmd,
 	diffCmd,
 	rmCmd,
 	eventCmd,
 	watchCmd,
 	policyCmd,
+	pingCmd,
 	adminCmd,
 	sessionCmd,
 	configCmd,
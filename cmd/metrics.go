@@ -0,0 +1,180 @@
+/*
+ * MinIO Client (C) 2019 MinIO, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/minio-go/v6"
+)
+
+// mirrorMetrics - metrics of the mirror jobs running in this process,
+// served in the Prometheus text format.
+type mirrorMetrics struct {
+	transferredBytes   int64
+	transferredObjects int64
+	removedObjects     int64
+	queueDepth         int64
+	workers            int64
+	busyWorkers        int64
+	lastTransfer       int64 // unix time of the last successful transfer or removal.
+	lastSync           int64 // unix time of the last complete mirror without failures.
+
+	mutex       sync.Mutex
+	failures    map[string]int64 // by error class.
+	watchEvents map[string]int64 // by event type.
+}
+
+// Metrics of this process, always collected and only served when
+// requested with `--metrics-addr`.
+var globalMirrorMetrics = &mirrorMetrics{
+	failures:    make(map[string]int64),
+	watchEvents: make(map[string]int64),
+}
+
+// transferred - records a successfully mirrored object.
+func (m *mirrorMetrics) transferred(size int64) {
+	atomic.AddInt64(&m.transferredBytes, size)
+	atomic.AddInt64(&m.transferredObjects, 1)
+	atomic.StoreInt64(&m.lastTransfer, UTCNow().Unix())
+}
+
+// removed - records a successfully removed object.
+func (m *mirrorMetrics) removed() {
+	atomic.AddInt64(&m.removedObjects, 1)
+	atomic.StoreInt64(&m.lastTransfer, UTCNow().Unix())
+}
+
+// synced - records a complete mirror without failures.
+func (m *mirrorMetrics) synced() {
+	atomic.StoreInt64(&m.lastSync, UTCNow().Unix())
+}
+
+// failed - records a failure, classified by its cause.
+func (m *mirrorMetrics) failed(err *probe.Error) {
+	m.mutex.Lock()
+	m.failures[errorClass(err)]++
+	m.mutex.Unlock()
+}
+
+// watchEvent - records an event received by a watcher.
+func (m *mirrorMetrics) watchEvent(eventType EventType) {
+	m.mutex.Lock()
+	m.watchEvents[string(eventType)]++
+	m.mutex.Unlock()
+}
+
+// errorClass - returns a short class of the error for metrics, the S3
+// error code, a network error or the name of the error type.
+func errorClass(err *probe.Error) string {
+	cause := err.ToGoError()
+	if code := minio.ToErrorResponse(cause).Code; code != "" {
+		return code
+	}
+	if _, ok := cause.(net.Error); ok {
+		return "NetworkError"
+	}
+	t := reflect.TypeOf(cause)
+	if t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t != nil && t.Name() != "" && strings.ToUpper(t.Name()[:1]) == t.Name()[:1] {
+		return t.Name()
+	}
+	return "Other"
+}
+
+// escapeLabelValue - escapes a label value of the text format.
+func escapeLabelValue(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
+
+// writeMetric - writes a single metric without labels.
+func writeMetric(w io.Writer, name, metricType, help string, value int64) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n%s %d\n", name, help, name, metricType, name, value)
+}
+
+// writeLabeledMetric - writes a metric with one value per label.
+func writeLabeledMetric(w io.Writer, name, metricType, help, label string, values map[string]int64) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, metricType)
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(w, "%s{%s=\"%s\"} %d\n", name, label, escapeLabelValue(k), values[k])
+	}
+}
+
+// ServeHTTP - serves the metrics in the Prometheus text format.
+func (m *mirrorMetrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+
+	writeMetric(w, "mc_mirror_transferred_bytes_total", "counter",
+		"Total number of bytes mirrored to the target.", atomic.LoadInt64(&m.transferredBytes))
+	writeMetric(w, "mc_mirror_transferred_objects_total", "counter",
+		"Total number of objects mirrored to the target.", atomic.LoadInt64(&m.transferredObjects))
+	writeMetric(w, "mc_mirror_removed_objects_total", "counter",
+		"Total number of objects removed on the target.", atomic.LoadInt64(&m.removedObjects))
+	writeMetric(w, "mc_mirror_queue_depth", "gauge",
+		"Number of objects queued and waiting for a worker.", atomic.LoadInt64(&m.queueDepth))
+	writeMetric(w, "mc_mirror_workers", "gauge",
+		"Number of parallel workers.", atomic.LoadInt64(&m.workers))
+	writeMetric(w, "mc_mirror_workers_busy", "gauge",
+		"Number of parallel workers running a task.", atomic.LoadInt64(&m.busyWorkers))
+	writeMetric(w, "mc_mirror_last_transfer_timestamp_seconds", "gauge",
+		"Unix time of the last object successfully mirrored or removed.", atomic.LoadInt64(&m.lastTransfer))
+	writeMetric(w, "mc_mirror_last_sync_timestamp_seconds", "gauge",
+		"Unix time of the last complete mirror without failures.", atomic.LoadInt64(&m.lastSync))
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	writeLabeledMetric(w, "mc_mirror_failures_total", "counter",
+		"Total number of failures by error class.", "class", m.failures)
+	writeLabeledMetric(w, "mc_mirror_watch_events_total", "counter",
+		"Total number of watch events received by type.", "type", m.watchEvents)
+}
+
+// startMetricsServer - serves the metrics on addr at /metrics.
+func startMetricsServer(addr string) *probe.Error {
+	listener, e := net.Listen("tcp", addr)
+	if e != nil {
+		return probe.NewError(e).Trace(addr)
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", globalMirrorMetrics)
+	server := &http.Server{
+		Handler:      mux,
+		ReadTimeout:  10 * time.Second,
+		WriteTimeout: 10 * time.Second,
+	}
+	go func() {
+		errorIf(probe.NewError(server.Serve(listener)), "Unable to serve metrics.")
+	}()
+	return nil
+}
@@ -0,0 +1,97 @@
+/*
+ * MinIO Client (C) 2019 MinIO, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"errors"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/minio-go/v6"
+)
+
+func TestErrorClass(t *testing.T) {
+	testCases := []struct {
+		err   error
+		class string
+	}{
+		{minio.ErrorResponse{Code: "AccessDenied"}, "AccessDenied"},
+		{PathNotFound{Path: "/tmp"}, "PathNotFound"},
+		{&os.PathError{Op: "open", Path: "/tmp", Err: os.ErrNotExist}, "PathError"},
+		{errors.New("unknown"), "Other"},
+	}
+	for i, testCase := range testCases {
+		if class := errorClass(probe.NewError(testCase.err)); class != testCase.class {
+			t.Errorf("Test %d: expected class `%s`, found `%s`", i+1, testCase.class, class)
+		}
+	}
+}
+
+func TestMirrorMetrics(t *testing.T) {
+	m := &mirrorMetrics{
+		failures:    make(map[string]int64),
+		watchEvents: make(map[string]int64),
+	}
+	m.transferred(10)
+	m.transferred(5)
+	m.failed(probe.NewError(minio.ErrorResponse{Code: "SlowDown"}))
+	m.watchEvent(EventCreate)
+
+	rec := httptest.NewRecorder()
+	m.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body := rec.Body.String()
+	for _, expected := range []string{
+		"mc_mirror_transferred_bytes_total 15\n",
+		"mc_mirror_transferred_objects_total 2\n",
+		"# TYPE mc_mirror_failures_total counter\n",
+		"mc_mirror_failures_total{class=\"SlowDown\"} 1\n",
+		"mc_mirror_watch_events_total{type=\"ObjectCreated\"} 1\n",
+	} {
+		if !strings.Contains(body, expected) {
+			t.Errorf("Expected `%s` in metrics, found:\n%s", strings.TrimSpace(expected), body)
+		}
+	}
+}
+
+func TestMirrorReportWatch(t *testing.T) {
+	mj := &mirrorJob{statusCh: make(chan URLs, 4)}
+	lastSync := func() int64 { return atomic.LoadInt64(&globalMirrorMetrics.lastSync) }
+	atomic.StoreInt64(&globalMirrorMetrics.lastSync, 0)
+
+	// Events during the initial mirror do not mean the target is in sync.
+	mj.reportWatch(URLs{})
+	if lastSync() != 0 {
+		t.Fatalf("expected no sync before the initial mirror completed")
+	}
+
+	atomic.StoreInt32(&mj.isSynced, 1)
+	mj.reportWatch(URLs{})
+	if lastSync() == 0 {
+		t.Fatalf("expected a successful watch event to update the last sync")
+	}
+
+	atomic.StoreInt64(&globalMirrorMetrics.lastSync, 0)
+	mj.reportWatch(URLs{Error: probe.NewError(errors.New("failed"))})
+	mj.reportWatch(URLs{})
+	if atomic.LoadInt64(&mj.failures) != 1 || lastSync() != 0 {
+		t.Fatalf("expected the failure to be counted and to stop updating the last sync")
+	}
+}
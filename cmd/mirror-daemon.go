@@ -0,0 +1,500 @@
+/*
+ * MinIO Client (C) 2019 MinIO, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"os/signal"
+	"reflect"
+	"runtime"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	humanize "github.com/dustin/go-humanize"
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/console"
+	"github.com/minio/mc/pkg/probe"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// Version of the mirror jobs configuration format.
+const mirrorDaemonConfigVersion = "1"
+
+// Default delay between two attempts of a failed mirror job.
+const defaultMirrorRetryDelay = time.Minute
+
+// mirrorJobRetry - retry settings of a mirror job.
+type mirrorJobRetry struct {
+	Attempts int    `yaml:"attempts"`
+	Delay    string `yaml:"delay"`
+}
+
+// mirrorJobConfig - a single source and target pair of a mirror
+// jobs configuration file.
+type mirrorJobConfig struct {
+	Name   string `yaml:"name"`
+	Source string `yaml:"source"`
+	Target string `yaml:"target"`
+
+	// Either watch continuously, or mirror again every interval.
+	// A job without any is mirrored once.
+	Watch    bool   `yaml:"watch"`
+	Interval string `yaml:"interval"`
+
+	Remove           bool     `yaml:"remove"`
+	Overwrite        bool     `yaml:"overwrite"`
+	Preserve         bool     `yaml:"preserve"`
+	Exclude          []string `yaml:"exclude"`
+	Include          []string `yaml:"include"`
+	OlderThan        string   `yaml:"older-than"`
+	NewerThan        string   `yaml:"newer-than"`
+	StorageClass     string   `yaml:"storage-class"`
+	Region           string   `yaml:"region"`
+	MaxDelete        string   `yaml:"max-delete"`
+	AllowEmptySource bool     `yaml:"allow-empty-source"`
+	BackupDir        string   `yaml:"backup-dir"`
+
+	Retry mirrorJobRetry `yaml:"retry"`
+	Log   string         `yaml:"log"`
+}
+
+// mirrorDaemonConfig - configuration of all mirror jobs run by a
+// single mc process.
+type mirrorDaemonConfig struct {
+	Version string            `yaml:"version"`
+	Workers int               `yaml:"workers"`
+	Jobs    []mirrorJobConfig `yaml:"jobs"`
+}
+
+// loadMirrorDaemonConfig - reads and validates the jobs configuration.
+func loadMirrorDaemonConfig(filename string) (*mirrorDaemonConfig, *probe.Error) {
+	data, e := ioutil.ReadFile(filename)
+	if e != nil {
+		return nil, probe.NewError(e).Trace(filename)
+	}
+	config := &mirrorDaemonConfig{}
+	if e = yaml.UnmarshalStrict(data, config); e != nil {
+		return nil, probe.NewError(e).Trace(filename)
+	}
+	if err := config.validate(); err != nil {
+		return nil, err.Trace(filename)
+	}
+	if config.Workers == 0 {
+		config.Workers = runtime.NumCPU()
+	}
+	return config, nil
+}
+
+// validate - verifies the configuration before any job is started.
+func (c *mirrorDaemonConfig) validate() *probe.Error {
+	if c.Version != mirrorDaemonConfigVersion {
+		return probe.NewError(fmt.Errorf("unsupported version `%s`, expected `%s`", c.Version, mirrorDaemonConfigVersion))
+	}
+	if c.Workers < 0 {
+		return probe.NewError(errors.New("workers cannot be negative"))
+	}
+	if len(c.Jobs) == 0 {
+		return probe.NewError(errors.New("no jobs defined"))
+	}
+	names := make(map[string]struct{})
+	for _, job := range c.Jobs {
+		if job.Name == "" {
+			return probe.NewError(errors.New("job without a name"))
+		}
+		if _, ok := names[job.Name]; ok {
+			return probe.NewError(fmt.Errorf("duplicate job `%s`", job.Name))
+		}
+		names[job.Name] = struct{}{}
+		if err := job.validate(); err != nil {
+			return err.Trace(job.Name)
+		}
+	}
+	return nil
+}
+
+// validate - verifies the settings of a single job.
+func (j mirrorJobConfig) validate() *probe.Error {
+	if j.Source == "" || j.Target == "" {
+		return probe.NewError(fmt.Errorf("job `%s` requires a source and a target", j.Name))
+	}
+	if j.Watch && j.Interval != "" {
+		return probe.NewError(fmt.Errorf("job `%s` cannot both watch and run on an interval", j.Name))
+	}
+	if j.Interval != "" {
+		if _, e := time.ParseDuration(j.Interval); e != nil {
+			return probe.NewError(fmt.Errorf("job `%s` has an invalid interval: %s", j.Name, e))
+		}
+	}
+	if j.Retry.Attempts < 0 {
+		return probe.NewError(fmt.Errorf("job `%s` cannot have negative retry attempts", j.Name))
+	}
+	if j.Retry.Delay != "" {
+		if _, e := time.ParseDuration(j.Retry.Delay); e != nil {
+			return probe.NewError(fmt.Errorf("job `%s` has an invalid retry delay: %s", j.Name, e))
+		}
+	}
+	if _, err := parseRemoveLimits(j.MaxDelete, j.AllowEmptySource); err != nil {
+		return err.Trace(j.Name, j.MaxDelete)
+	}
+	if !j.Remove && (j.MaxDelete != "" || j.BackupDir != "" || j.AllowEmptySource) {
+		return probe.NewError(fmt.Errorf("job `%s` uses removal settings without `remove`", j.Name))
+	}
+	return nil
+}
+
+// options - mirror options of the job.
+func (j mirrorJobConfig) options() mirrorOptions {
+	limits, _ := parseRemoveLimits(j.MaxDelete, j.AllowEmptySource)
+	region := j.Region
+	if region == "" {
+		region = "us-east-1"
+	}
+	return mirrorOptions{
+		isRemove:     j.Remove,
+		isOverwrite:  j.Overwrite,
+		isWatch:      j.Watch,
+		isPreserve:   j.Preserve,
+		olderThan:    j.OlderThan,
+		newerThan:    j.NewerThan,
+		storageClass: j.StorageClass,
+		region:       region,
+		filter: urlFilter{
+			includeOptions: j.Include,
+			excludeOptions: j.Exclude,
+		},
+		removeLimits: limits,
+		backupDir:    j.BackupDir,
+	}
+}
+
+// retryDelay - delay before retrying the failed job.
+func (j mirrorJobConfig) retryDelay() time.Duration {
+	if j.Retry.Delay == "" {
+		return defaultMirrorRetryDelay
+	}
+	delay, _ := time.ParseDuration(j.Retry.Delay)
+	return delay
+}
+
+// mirrorLogStatus - quiet status writing the mirror messages of a job
+// to its log.
+type mirrorLogStatus struct {
+	Status
+	logger *log.Logger
+}
+
+// PrintMsg writes message to the job log.
+func (s mirrorLogStatus) PrintMsg(msg message) {
+	if globalJSON {
+		s.logger.Println(msg.JSON())
+		return
+	}
+	s.logger.Println(msg.String())
+}
+
+// Finish writes the accounting summary to the job log.
+func (s mirrorLogStatus) Finish() {
+	s.logger.Printf("Transferred %d object(s), %s.", s.GetCounts(), humanize.IBytes(uint64(s.Get())))
+}
+
+// mirrorDaemonJob - a running job of the daemon.
+type mirrorDaemonJob struct {
+	config mirrorJobConfig
+	stopCh chan struct{}
+	doneCh chan struct{}
+}
+
+// mirrorDaemon - runs the mirror jobs of a configuration file in a
+// single process, sharing a bounded pool of workers.
+type mirrorDaemon struct {
+	configFile string
+	encKeyDB   map[string][]prefixSSEPair
+
+	pool *workerPool
+	jobs map[string]*mirrorDaemonJob
+
+	// Set once a job gave up after its retries, accessed atomically.
+	gaveUp int32
+}
+
+// logf - reports a daemon event on the console.
+func (d *mirrorDaemon) logf(format string, args ...interface{}) {
+	console.Infoln(fmt.Sprintf(format, args...))
+}
+
+// runJob - runs a job until it is stopped, honoring its schedule and
+// retry settings.
+func (d *mirrorDaemon) runJob(job *mirrorDaemonJob) {
+	defer close(job.doneCh)
+
+	// Jobs without a log of their own share the console.
+	logger := log.New(os.Stdout, job.config.Name+": ", log.LstdFlags)
+	if job.config.Log != "" {
+		logFile, e := os.OpenFile(job.config.Log, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+		if e != nil {
+			errorIf(probe.NewError(e).Trace(job.config.Log), "Unable to open log of job `"+job.config.Name+"`.")
+		} else {
+			defer logFile.Close()
+			logger = log.New(logFile, "", log.LstdFlags)
+		}
+	}
+
+	var interval time.Duration
+	if job.config.Interval != "" {
+		interval, _ = time.ParseDuration(job.config.Interval)
+	}
+
+	for {
+		for attempt := 0; ; attempt++ {
+			logger.Printf("Mirroring `%s` to `%s`.", job.config.Source, job.config.Target)
+			errorDetected := d.mirrorOnce(job, logger)
+			if !errorDetected {
+				logger.Println("Mirror completed.")
+				break
+			}
+			logger.Println("Mirror failed.")
+			if attempt >= job.config.Retry.Attempts {
+				errorIf(probe.NewError(fmt.Errorf("giving up after %d attempt(s)", attempt+1)).Trace(job.config.Name),
+					"Mirror job `"+job.config.Name+"` failed.")
+				atomic.StoreInt32(&d.gaveUp, 1)
+				break
+			}
+			select {
+			case <-job.stopCh:
+				return
+			case <-time.After(job.config.retryDelay()):
+			}
+		}
+
+		if interval == 0 {
+			// Watch jobs only return once stopped or out of retries,
+			// one time jobs are done.
+			return
+		}
+		select {
+		case <-job.stopCh:
+			return
+		case <-time.After(interval):
+		}
+	}
+}
+
+// mirrorOnce - mirrors the job once, or until stopped for watch jobs.
+func (d *mirrorDaemon) mirrorOnce(job *mirrorDaemonJob, logger *log.Logger) (errorDetected bool) {
+	opts := job.config.options()
+	srcURL, dstURL := job.config.Source, job.config.Target
+
+	srcClt, err := newClient(srcURL)
+	if err != nil {
+		errorIf(err.Trace(srcURL), "Unable to initialize `"+srcURL+"`.")
+		return true
+	}
+	dstClt, err := newClient(dstURL)
+	if err != nil {
+		errorIf(err.Trace(dstURL), "Unable to initialize `"+dstURL+"`.")
+		return true
+	}
+
+	filter, err := opts.filter.withIgnoreFile(srcURL, d.encKeyDB)
+	if err != nil {
+		errorIf(err.Trace(srcURL), "Unable to read `"+mcIgnoreFile+"` from `"+srcURL+"`.")
+		return true
+	}
+
+	mj := newMirrorJob(srcURL, dstURL,
+		opts.isFake,
+		opts.isRemove,
+		opts.isOverwrite,
+		opts.isWatch,
+		opts.isPreserve,
+		false,
+		filter,
+		opts.olderThan,
+		opts.newerThan,
+		opts.storageClass,
+		"",
+		opts.userMetadata,
+		d.encKeyDB)
+	mj.removeLimits = opts.removeLimits
+	mj.backupDir = opts.backupDir
+	mj.stopCh = job.stopCh
+	mj.parallel.pool = d.pool
+	mj.status = mirrorLogStatus{Status: NewQuietStatus(mj.parallel), logger: logger}
+	defer func() {
+		// Release the watchers of the job.
+		go func() {
+			for range mj.watcher.Events() {
+			}
+		}()
+		go func() {
+			for range mj.watcher.Errors() {
+			}
+		}()
+		mj.watcher.Stop()
+	}()
+
+	if _, err = prepareMirrorBucket(srcClt, dstClt, opts.region, opts.isOverwrite); err != nil {
+		errorIf(err.Trace(dstURL), "Unable to create bucket at `"+dstURL+"`.")
+		return true
+	}
+
+	if mj.isWatch {
+		if err = mj.watchURL(srcClt); err != nil {
+			errorIf(err.Trace(srcURL), "Failed to start monitoring.")
+			return true
+		}
+	}
+
+	ctx, cancelMirror := context.WithCancel(context.Background())
+	defer cancelMirror()
+
+	errorDetected = mj.mirror(ctx, cancelMirror)
+	select {
+	case <-job.stopCh:
+		// A watch job stopped on purpose did not fail.
+		return false
+	default:
+	}
+	// A watch job returning on its own lost its watcher.
+	return errorDetected || mj.isWatch
+}
+
+// startJob - starts a new job.
+func (d *mirrorDaemon) startJob(config mirrorJobConfig) {
+	job := &mirrorDaemonJob{
+		config: config,
+		stopCh: make(chan struct{}),
+		doneCh: make(chan struct{}),
+	}
+	d.jobs[config.Name] = job
+	d.logf("Starting mirror job `%s`.", config.Name)
+	go d.runJob(job)
+}
+
+// stopJob - stops a job, aborting the transfers in progress, and
+// waits for it to return.
+func (d *mirrorDaemon) stopJob(name string) {
+	job := d.jobs[name]
+	d.logf("Stopping mirror job `%s`.", name)
+	close(job.stopCh)
+	<-job.doneCh
+	delete(d.jobs, name)
+}
+
+// apply - starts, stops or restarts jobs to match config, jobs whose
+// configuration did not change keep running, or stay done.
+func (d *mirrorDaemon) apply(config *mirrorDaemonConfig) {
+	if d.pool == nil {
+		d.pool = newWorkerPool(config.Workers)
+	} else {
+		// The pool is shared by all jobs, it is resized in place.
+		d.pool.resize(config.Workers)
+	}
+
+	configs := make(map[string]mirrorJobConfig)
+	for _, jobConfig := range config.Jobs {
+		configs[jobConfig.Name] = jobConfig
+	}
+	for name, job := range d.jobs {
+		if jobConfig, ok := configs[name]; !ok || !reflect.DeepEqual(jobConfig, job.config) {
+			d.stopJob(name)
+		}
+	}
+	for _, jobConfig := range config.Jobs {
+		if _, ok := d.jobs[jobConfig.Name]; !ok {
+			d.startJob(jobConfig)
+		}
+	}
+}
+
+// isDone - returns true if all jobs are done.
+func (d *mirrorDaemon) isDone() bool {
+	for _, job := range d.jobs {
+		select {
+		case <-job.doneCh:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// run - runs all jobs until interrupted, the configuration is reloaded
+// on SIGHUP. Returns once all jobs are done or stopped.
+func (d *mirrorDaemon) run(config *mirrorDaemonConfig) {
+	d.apply(config)
+
+	hupCh := make(chan os.Signal, 1)
+	signal.Notify(hupCh, syscall.SIGHUP)
+	defer signal.Stop(hupCh)
+	trapCh := signalTrap(os.Interrupt, syscall.SIGTERM)
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-hupCh:
+			config, err := loadMirrorDaemonConfig(d.configFile)
+			if err != nil {
+				// Keep the running jobs on an invalid configuration.
+				errorIf(err.Trace(d.configFile), "Unable to reload mirror jobs.")
+				continue
+			}
+			d.logf("Reloading mirror jobs from `%s`.", d.configFile)
+			d.apply(config)
+		case <-trapCh:
+			for name := range d.jobs {
+				d.stopJob(name)
+			}
+			return
+		case <-ticker.C:
+			// Jobs which are done are kept, so that a reload does
+			// not run them again, the daemon quits once all are done.
+			if d.isDone() {
+				return
+			}
+		}
+	}
+}
+
+// mainMirrorDaemon - runs the mirror jobs of the configuration file.
+func mainMirrorDaemon(ctx *cli.Context, encKeyDB map[string][]prefixSSEPair) error {
+	if len(ctx.Args()) != 0 {
+		fatalIf(errInvalidArgument().Trace(ctx.Args()...), "SOURCE and TARGET cannot be used with `--config`.")
+	}
+
+	configFile := ctx.String("config")
+	config, err := loadMirrorDaemonConfig(configFile)
+	fatalIf(err.Trace(configFile), "Unable to load mirror jobs.")
+
+	d := &mirrorDaemon{
+		configFile: configFile,
+		encKeyDB:   encKeyDB,
+		jobs:       make(map[string]*mirrorDaemonJob),
+	}
+	d.run(config)
+	if atomic.LoadInt32(&d.gaveUp) != 0 {
+		return exitStatus(globalErrorExitStatus)
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+/*
+ * MinIO Client (C) 2019 MinIO, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/minio/mc/pkg/probe"
+)
+
+func TestLoadMirrorDaemonConfig(t *testing.T) {
+	testCases := []struct {
+		config  string
+		success bool
+	}{
+		{"version: \"1\"\njobs:\n  - name: a\n    source: /src\n    target: play/dst\n    watch: true\n", true},
+		{"version: \"1\"\nworkers: 4\njobs:\n  - name: a\n    source: /src\n    target: play/dst\n    interval: 1h\n    retry:\n      attempts: 3\n      delay: 30s\n", true},
+		// Unsupported version.
+		{"version: \"2\"\njobs:\n  - name: a\n    source: /src\n    target: play/dst\n", false},
+		// No jobs.
+		{"version: \"1\"\n", false},
+		// Unknown setting.
+		{"version: \"1\"\njobs:\n  - name: a\n    source: /src\n    target: play/dst\n    unknown: true\n", false},
+		// Duplicate names.
+		{"version: \"1\"\njobs:\n  - name: a\n    source: /src\n    target: play/dst\n  - name: a\n    source: /src2\n    target: play/dst2\n", false},
+		// Both watch and interval.
+		{"version: \"1\"\njobs:\n  - name: a\n    source: /src\n    target: play/dst\n    watch: true\n    interval: 1h\n", false},
+		// Invalid interval.
+		{"version: \"1\"\njobs:\n  - name: a\n    source: /src\n    target: play/dst\n    interval: daily\n", false},
+		// Removal settings without remove.
+		{"version: \"1\"\njobs:\n  - name: a\n    source: /src\n    target: play/dst\n    max-delete: 10%\n", false},
+	}
+
+	for i, testCase := range testCases {
+		f, e := ioutil.TempFile("", "mc-jobs")
+		if e != nil {
+			t.Fatal(e)
+		}
+		f.WriteString(testCase.config)
+		f.Close()
+
+		config, err := loadMirrorDaemonConfig(f.Name())
+		os.Remove(f.Name())
+		if (err == nil) != testCase.success {
+			t.Fatalf("Test %d: expected success to be %t, found %v", i+1, testCase.success, err)
+		}
+		if err == nil && config.Workers <= 0 {
+			t.Errorf("Test %d: expected a positive number of workers, found %d", i+1, config.Workers)
+		}
+	}
+}
+
+func TestWorkerPoolResize(t *testing.T) {
+	pool := newWorkerPool(1)
+	started := make(chan struct{}, 2)
+	release := make(chan struct{})
+	for i := 0; i < 2; i++ {
+		go pool.run(func() URLs {
+			started <- struct{}{}
+			<-release
+			return URLs{}
+		})
+	}
+
+	<-started
+	select {
+	case <-started:
+		t.Fatalf("expected a single task to run with a pool of one worker")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	pool.resize(2)
+	select {
+	case <-started:
+	case <-time.After(time.Second):
+		t.Fatalf("expected the second task to run once the pool is resized")
+	}
+	close(release)
+}
+
+func TestMirrorDaemonApply(t *testing.T) {
+	done := func(config mirrorJobConfig) *mirrorDaemonJob {
+		job := &mirrorDaemonJob{config: config, stopCh: make(chan struct{}), doneCh: make(chan struct{})}
+		close(job.doneCh)
+		return job
+	}
+	kept := mirrorJobConfig{Name: "kept", Source: "/src", Target: "/dst"}
+	removed := mirrorJobConfig{Name: "removed", Source: "/src2", Target: "/dst2"}
+	keptJob := done(kept)
+
+	d := &mirrorDaemon{
+		pool: newWorkerPool(2),
+		jobs: map[string]*mirrorDaemonJob{"kept": keptJob, "removed": done(removed)},
+	}
+	// Changing the number of workers does not run done jobs again.
+	d.apply(&mirrorDaemonConfig{Version: mirrorDaemonConfigVersion, Workers: 4, Jobs: []mirrorJobConfig{kept}})
+	if d.jobs["kept"] != keptJob {
+		t.Errorf("expected the unchanged job to be kept")
+	}
+	if _, ok := d.jobs["removed"]; ok {
+		t.Errorf("expected the removed job to be stopped")
+	}
+	if d.pool.size != 4 {
+		t.Errorf("expected the pool to be resized to 4, found %d", d.pool.size)
+	}
+}
+
+func TestMirrorDaemonGiveUp(t *testing.T) {
+	dir, e := ioutil.TempDir("", "mirror-daemon-")
+	if e != nil {
+		t.Fatal(e)
+	}
+	defer os.RemoveAll(dir)
+	// Local paths have no alias to expand.
+	defer func(load func() (*configV9, *probe.Error)) { loadMcConfig = load }(loadMcConfig)
+	loadMcConfig = func() (*configV9, *probe.Error) { return newMcConfig(), nil }
+
+	d := &mirrorDaemon{
+		pool: newWorkerPool(1),
+		jobs: make(map[string]*mirrorDaemonJob),
+	}
+	// The target is below a regular file, the job fails on each attempt.
+	if e = ioutil.WriteFile(filepath.Join(dir, "file"), nil, 0600); e != nil {
+		t.Fatal(e)
+	}
+	config := mirrorJobConfig{
+		Name:   "failing",
+		Source: dir,
+		Target: filepath.Join(dir, "file", "target"),
+		Log:    filepath.Join(dir, "failing.log"),
+		Retry:  mirrorJobRetry{Attempts: 1, Delay: "1ms"},
+	}
+	job := &mirrorDaemonJob{config: config, stopCh: make(chan struct{}), doneCh: make(chan struct{})}
+	d.runJob(job)
+	if atomic.LoadInt32(&d.gaveUp) == 0 {
+		t.Fatalf("expected the job to give up after its retries")
+	}
+}
@@ -24,8 +24,10 @@ import (
 	"path"
 	"path/filepath"
 	"runtime"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 
@@ -69,6 +71,14 @@ var (
 			Name:  "preserve, a",
 			Usage: "preserve file(s)/object(s) attributes and bucket policy rules on target bucket(s)",
 		},
+		cli.StringFlag{
+			Name:  "compress",
+			Usage: "compress objects with 'gzip' or 'zstd' before uploading them",
+		},
+		cli.BoolFlag{
+			Name:  "decompress",
+			Usage: "decompress 'gzip', 'bzip2' and 'zstd' compressed objects while mirroring them",
+		},
 		cli.StringFlag{
 			Name:  "multi-master",
 			Usage: `multi-master multi-site setup, "value" is the site tag for the multi-master deployment`,
@@ -77,6 +87,18 @@ var (
 			Name:  "exclude",
 			Usage: "exclude object(s) that match specified object name pattern",
 		},
+		cli.StringSliceFlag{
+			Name:  "include",
+			Usage: "include only object(s) that match specified object name pattern",
+		},
+		cli.StringSliceFlag{
+			Name:  "exclude-from",
+			Usage: "exclude object(s) that match any pattern listed in the specified file",
+		},
+		cli.StringSliceFlag{
+			Name:  "include-from",
+			Usage: "include only object(s) that match any pattern listed in the specified file",
+		},
 		cli.StringFlag{
 			Name:  "older-than",
 			Usage: "filter object(s) older than L days, M hours and N minutes",
@@ -97,6 +119,31 @@ var (
 			Name:  "attr",
 			Usage: "add custom metadata for all objects",
 		},
+		cli.BoolFlag{
+			Name:  "continue, c",
+			Usage: "create or resume mirror session",
+		},
+		cli.StringFlag{
+			Name:  "max-delete",
+			Usage: "abort removal if more than N or N% of object(s) on target would be removed, including removals of '--watch', used with '--remove'",
+		},
+		cli.BoolFlag{
+			Name:  "allow-empty-source",
+			Usage: "allow removal of object(s) on target even if source is empty, used with '--remove'",
+		},
+		cli.StringFlag{
+			Name:  "metrics-addr",
+			Usage: "serve Prometheus metrics at http://ADDRESS/metrics, e.g. ':9199'",
+		},
+		cli.StringFlag{
+			Name:  "config",
+			Usage: "run the mirror jobs defined in a YAML file, reloaded on SIGHUP",
+		},
+		cli.StringFlag{
+			Name:  "backup-dir",
+			Usage: "move extraneous object(s) on target to ALIAS/PREFIX instead of removing them, used with '--remove'",
+		},
+		parallelListFlag,
 	}
 )
 
@@ -111,7 +158,8 @@ var mirrorCmd = cli.Command{
   {{.HelpName}} - {{.Usage}}
 
 USAGE:
-  {{.HelpName}} [FLAGS] SOURCE TARGET
+  {{.HelpName}} [FLAGS] SOURCE TARGET [TARGET...]
+  {{.HelpName}} [FLAGS] --config FILE
 
 FLAGS:
   {{range .VisibleFlags}}{{.}}
@@ -170,6 +218,63 @@ EXAMPLES:
   15. Cross mirror between sites in a multi-master deployment.
       Site-A: {{.Prompt}} {{.HelpName}} --watch --multi-master splunk-smartstore1 siteA siteB
       Site-B: {{.Prompt}} {{.HelpName}} --watch --multi-master splunk-smartstore1 siteB siteA
+
+  16. Mirror only *.log and *.gz objects from a local folder, skipping patterns listed in a file.
+      {{.Prompt}} {{.HelpName}} --include "*.log" --include "*.gz" --exclude-from ~/build-excludes.txt /var/log/ s3/logs
+
+  17. Mirror a local folder honoring the gitignore style rules in its '.mcignore' file.
+      {{.Prompt}} {{.HelpName}} ~/workspace/ s3/workspace
+
+  18. Mirror a bucket to Amazon S3 cloud storage and create or resume a mirror session. An interrupted
+      session continues from the saved plan, use 'mc session resume' or run the same command again.
+      {{.Prompt}} {{.HelpName}} --continue --remove play/photos s3/backup-photos
+
+  19. Mirror a bucket and remove extraneous objects on target, aborting if more than 5% of the objects
+      on target would be removed. Use '--fake' to list the objects that would be removed.
+      {{.Prompt}} {{.HelpName}} --remove --max-delete 5% play/photos s3/backup-photos
+
+  20. Mirror a bucket and move extraneous objects on target to another prefix instead of removing them.
+      {{.Prompt}} {{.HelpName}} --remove --backup-dir s3/trash/photos play/photos s3/backup-photos
+
+  21. Run all the mirror jobs defined in a file in a single process. Each job has its own source, target,
+      filters, schedule ('watch: true' or 'interval: 1h'), retry settings and log, all jobs share a pool
+      of 'workers'. Send SIGHUP to reload the file, only the changed jobs are restarted. The exit status is
+      non-zero if a job gave up after its retries.
+      {{.Prompt}} cat /etc/mc/jobs.yaml
+      version: "1"
+      workers: 8
+      jobs:
+        - name: photos
+          source: /var/lib/photos
+          target: s3/backup-photos
+          watch: true
+          remove: true
+          log: /var/log/mc/photos.log
+        - name: logs
+          source: /var/log/nginx
+          target: play/logs
+          interval: 1h
+          exclude: ["*.tmp"]
+          retry:
+            attempts: 3
+            delay: 5m
+      {{.Prompt}} {{.HelpName}} --config /etc/mc/jobs.yaml
+
+  22. Continuously mirror a local folder and serve Prometheus metrics on port 9199, to alert when
+      'mc_mirror_last_transfer_timestamp_seconds' or 'mc_mirror_failures_total' show a stalled mirror.
+      {{.Prompt}} {{.HelpName}} --watch --metrics-addr :9199 /var/lib/backups play/backups
+
+  23. Mirror a bucket to three sites in one pass. The source is listed once and each changed object is
+      read once and sent to all the sites missing it, a failing site does not stop the others.
+      {{.Prompt}} {{.HelpName}} --overwrite play/photos site1/photos site2/photos site3/photos
+
+  24. Mirror a bucket with millions of objects, listing up to 16 prefixes of each side concurrently.
+      {{.Prompt}} {{.HelpName}} --parallel-list 16 play/archive s3/archive
+
+  25. Mirror a local folder of logs compressed with gzip. Compressed objects record the size and
+      modification time of their source, which are compared instead of their own to find changed logs.
+      Local files cannot record them and are changed when older than their source.
+      {{.Prompt}} {{.HelpName}} --compress gzip /var/log/nginx play/logs/nginx
 `,
 }
 
@@ -204,16 +309,96 @@ type mirrorJob struct {
 	sourceURL string
 	targetURL string
 
+	// all targets when mirroring to several of them at once.
+	targetURLs []string
+
 	isFake, isRemove, isOverwrite, isWatch, isPreserve bool
+	isDecompress                                       bool
 	olderThan, newerThan                               string
 	storageClass                                       string
+	compress                                           string
 	userMetadata                                       map[string]string
 
-	excludeOptions []string
-	encKeyDB       map[string][]prefixSSEPair
+	filter   urlFilter
+	encKeyDB map[string][]prefixSSEPair
+
+	// safeguards for removal of objects on target and the
+	// location where removed objects are moved to, if any.
+	removeLimits removeLimits
+	backupDir    string
+	// removals done since the last listing, checked against removeLimits.
+	removals *removeAccount
 
 	multiMasterEnable bool
 	multiMasterSTag   string
+
+	// resumable session and its already synced entries, if any.
+	session *sessionV8
+	synced  *sessionSyncedList
+
+	// number of failures so far and whether the initial mirror
+	// completed without failures, accessed atomically.
+	failures int64
+	isSynced int32
+}
+
+// mirrorOptions - options of a mirror job, parsed from the command
+// line or restored from a saved session.
+type mirrorOptions struct {
+	isFake, isRemove, isOverwrite, isWatch, isPreserve bool
+	isDecompress                                       bool
+
+	olderThan, newerThan string
+	storageClass, region string
+	compress             string
+	multiMasterSTag      string
+	userMetadata         map[string]string
+	filter               urlFilter
+	removeLimits         removeLimits
+	backupDir            string
+}
+
+// newMirrorOptions - parses mirror options from the command line.
+func newMirrorOptions(ctx *cli.Context) mirrorOptions {
+	// This is kept for backward compatibility, `--force` means
+	// --overwrite.
+	isOverwrite := ctx.Bool("force")
+	if !isOverwrite {
+		isOverwrite = ctx.Bool("overwrite")
+	}
+
+	// Parse metadata.
+	userMetaMap := make(map[string]string)
+	if ctx.String("attr") != "" {
+		var err *probe.Error
+		userMetaMap, err = getMetaDataEntry(ctx.String("attr"))
+		fatalIf(err, "Unable to parse attribute %v", ctx.String("attr"))
+	}
+
+	filter, err := newURLFilter(ctx)
+	fatalIf(err, "Unable to parse include and exclude patterns.")
+
+	limits, err := parseRemoveLimits(ctx.String("max-delete"), ctx.Bool("allow-empty-source"))
+	fatalIf(err.Trace(ctx.String("max-delete")), "Unable to parse `--max-delete`.")
+
+	return mirrorOptions{
+		isFake:          ctx.Bool("fake"),
+		isRemove:        ctx.Bool("remove"),
+		isOverwrite:     isOverwrite,
+		isWatch:         ctx.Bool("watch"),
+		isPreserve:      ctx.Bool("a"),
+		isDecompress:    ctx.Bool("decompress"),
+		compress:        ctx.String("compress"),
+		olderThan:       ctx.String("older-than"),
+		newerThan:       ctx.String("newer-than"),
+		storageClass:    ctx.String("storage-class"),
+		region:          ctx.String("region"),
+		multiMasterSTag: ctx.String("multi-master"),
+		userMetadata:    userMetaMap,
+		filter:          filter,
+		removeLimits:    limits,
+		backupDir:       ctx.String("backup-dir"),
+	}
 }
 
 // mirrorMessage container for file mirror messages
@@ -240,12 +425,53 @@ func (m mirrorMessage) JSON() string {
 	return string(mirrorMessageBytes)
 }
 
+// queue - queues a task for the parallel workers.
+func (mj *mirrorJob) queue(fn func() URLs) {
+	atomic.AddInt64(&globalMirrorMetrics.queueDepth, 1)
+	mj.queueCh <- func() URLs {
+		atomic.AddInt64(&globalMirrorMetrics.queueDepth, -1)
+		return mj.countFailure(fn())
+	}
+}
+
+// countFailure - counts a failed result. Failures are counted where
+// results are produced rather than by the status monitor, so that they
+// are all counted once the tasks producing them are done.
+func (mj *mirrorJob) countFailure(sURLs URLs) URLs {
+	if sURLs.Error != nil && !isErrIgnored(sURLs.Error) {
+		atomic.AddInt64(&mj.failures, 1)
+		globalMirrorMetrics.failed(sURLs.Error)
+	}
+	return sURLs
+}
+
+// report - sends a result to the status monitor.
+func (mj *mirrorJob) report(sURLs URLs) {
+	mj.statusCh <- mj.countFailure(sURLs)
+}
+
+// reportWatch - sends the result of a watch event to the status monitor,
+// a successful event keeps the target in sync once the initial mirror
+// completed, as long as nothing failed.
+func (mj *mirrorJob) reportWatch(sURLs URLs) {
+	mj.report(sURLs)
+	if sURLs.Error == nil && atomic.LoadInt32(&mj.isSynced) == 1 && atomic.LoadInt64(&mj.failures) == 0 {
+		globalMirrorMetrics.synced()
+	}
+}
+
 // doRemove - removes files on target.
 func (mj *mirrorJob) doRemove(sURLs URLs) URLs {
 	if mj.isFake {
 		return sURLs.WithError(nil)
 	}
 
+	if mj.backupDir != "" {
+		if err := mj.doBackup(sURLs); err != nil {
+			return sURLs.WithError(err)
+		}
+	}
+
 	// Construct proper path with alias.
 	targetWithAlias := filepath.Join(sURLs.TargetAlias, sURLs.TargetContent.URL.Path)
 	clnt, pErr := newClient(targetWithAlias)
@@ -257,7 +483,7 @@ func (mj *mirrorJob) doRemove(sURLs URLs) URLs {
 	contentCh <- &clientContent{URL: *newClientURL(sURLs.TargetContent.URL.Path)}
 	close(contentCh)
 	isRemoveBucket := false
-	errorCh := clnt.Remove(false, isRemoveBucket, contentCh)
+	errorCh := clnt.Remove(false, isRemoveBucket, false, contentCh)
 	for pErr := range errorCh {
 		if pErr != nil {
 			switch pErr.ToGoError().(type) {
@@ -272,23 +498,39 @@ func (mj *mirrorJob) doRemove(sURLs URLs) URLs {
 	return sURLs.WithError(nil)
 }
 
-// doMirror - Mirror an object to multiple destination. URLs status contains a copy of sURLs and error if any.
-func (mj *mirrorJob) doMirror(ctx context.Context, cancelMirror context.CancelFunc, sURLs URLs) URLs {
-
-	if sURLs.Error != nil { // Erroneous sURLs passed.
-		return sURLs.WithError(sURLs.Error.Trace())
+// doBackup - copies an object about to be removed on target into the
+// backup folder, preserving its path relative to the target.
+func (mj *mirrorJob) doBackup(sURLs URLs) *probe.Error {
+	targetAlias, expandedTargetURL, _ := mustExpandAlias(mj.targetURL)
+	targetClnt, err := newClientFromAlias(targetAlias, expandedTargetURL)
+	if err != nil {
+		return err.Trace(mj.targetURL)
 	}
-
-	//s For a fake mirror make sure we update respective progress bars
-	// and accounting readers under relevant conditions.
-	if mj.isFake {
-		if sURLs.SourceContent != nil {
-			mj.status.Add(sURLs.SourceContent.Size)
-		}
-		mj.status.Update()
-		return sURLs.WithError(nil)
+	targetPath := targetClnt.GetURL().Path
+	separator := string(targetClnt.GetURL().Separator)
+	if !strings.HasSuffix(targetPath, separator) {
+		targetPath += separator
+	}
+	suffix := strings.TrimPrefix(sURLs.TargetContent.URL.Path, targetPath)
+
+	backupAlias, backupURL, _ := mustExpandAlias(urlJoinPath(mj.backupDir, filepath.ToSlash(suffix)))
+	backupURLs := URLs{
+		SourceAlias:   sURLs.TargetAlias,
+		SourceContent: sURLs.TargetContent,
+		TargetAlias:   backupAlias,
+		TargetContent: &clientContent{URL: *newClientURL(backupURL)},
+	}
+	// Backed up objects are not accounted in the mirror progress.
+	backupURLs = uploadSourceToTargetURL(context.Background(), backupURLs, mj.parallel, mj.encKeyDB)
+	if backupURLs.Error != nil {
+		return backupURLs.Error.Trace(sURLs.TargetContent.URL.String(), mj.backupDir)
 	}
+	return nil
+}
 
+// prepareMirrorTarget - sets the metadata of the target of sURLs and
+// reports the object about to be mirrored.
+func (mj *mirrorJob) prepareMirrorTarget(sURLs URLs) URLs {
 	sourceAlias := sURLs.SourceAlias
 	sourceURL := sURLs.SourceContent.URL
 	targetAlias := sURLs.TargetAlias
@@ -304,6 +546,19 @@ func (mj *mirrorJob) doMirror(ctx context.Context, cancelMirror context.CancelFu
 		sURLs.TargetContent.Metadata["X-Amz-Storage-Class"] = mj.storageClass
 	}
 
+	if mj.compress != "" {
+		sURLs.TargetContent.Metadata[compressMetadataKey] = mj.compress
+	}
+	if mj.isDecompress {
+		sURLs.TargetContent.Metadata[decompressMetadataKey] = "true"
+	}
+	if mj.isTransformed() {
+		// The target differs in size from its source, it is compared
+		// with the size and time of the source recorded along with it.
+		sURLs.TargetContent.Metadata[mcSourceSizeKey] = strconv.FormatInt(length, 10)
+		sURLs.TargetContent.Metadata[mcSourceMtimeKey] = sURLs.SourceContent.Time.UTC().Format(time.RFC3339Nano)
+	}
+
 	// Set multiMasterETagKey for the target.
 	if sURLs.SourceContent.UserMetadata[multiMasterETagKey] != "" {
 		sURLs.TargetContent.Metadata[multiMasterETagKey] = sURLs.SourceContent.UserMetadata[multiMasterETagKey]
@@ -339,6 +594,36 @@ func (mj *mirrorJob) doMirror(ctx context.Context, cancelMirror context.CancelFu
 		TotalCount: sURLs.TotalCount,
 		TotalSize:  sURLs.TotalSize,
 	})
+	return sURLs
+}
+
+// isTransformed - returns true if objects are compressed or
+// decompressed while they are mirrored.
+func (mj *mirrorJob) isTransformed() bool {
+	return mj.compress != "" || mj.isDecompress
+}
+
+// doMirror - Mirror an object to multiple destination. URLs status contains a copy of sURLs and error if any.
+func (mj *mirrorJob) doMirror(ctx context.Context, cancelMirror context.CancelFunc, sURLs URLs) URLs {
+
+	if sURLs.Error != nil { // Erroneous sURLs passed.
+		return sURLs.WithError(sURLs.Error.Trace())
+	}
+
+	//s For a fake mirror make sure we update respective progress bars
+	// and accounting readers under relevant conditions.
+	if mj.isFake {
+		if sURLs.SourceContent != nil {
+			mj.status.Add(sURLs.SourceContent.Size)
+		}
+		mj.status.Update()
+		return sURLs.WithError(nil)
+	}
+
+	sURLs = mj.prepareMirrorTarget(sURLs)
+	if sURLs.Error != nil {
+		return sURLs
+	}
 	return uploadSourceToTargetURL(ctx, sURLs, mj.status, mj.encKeyDB)
 }
 
@@ -353,8 +638,13 @@ func (mj *mirrorJob) monitorMirrorStatus() (errDuringMirror bool) {
 			switch {
 			case sURLs.SourceContent != nil:
 				if !isErrIgnored(sURLs.Error) {
-					errorIf(sURLs.Error.Trace(sURLs.SourceContent.URL.String()),
-						fmt.Sprintf("Failed to copy `%s`.", sURLs.SourceContent.URL.String()))
+					if len(mj.targetURLs) > 0 && sURLs.TargetContent != nil {
+						errorIf(sURLs.Error.Trace(sURLs.SourceContent.URL.String(), sURLs.TargetContent.URL.String()),
+							fmt.Sprintf("Failed to copy `%s` to `%s`.", sURLs.SourceContent.URL.String(), sURLs.TargetContent.URL.String()))
+					} else {
+						errorIf(sURLs.Error.Trace(sURLs.SourceContent.URL.String()),
+							fmt.Sprintf("Failed to copy `%s`.", sURLs.SourceContent.URL.String()))
+					}
 					errDuringMirror = true
 				}
 			case sURLs.TargetContent != nil:
@@ -370,10 +660,20 @@ func (mj *mirrorJob) monitorMirrorStatus() (errDuringMirror bool) {
 				close(mj.stopCh)
 				break
 			}
+		} else if !mj.isFake {
+			if sURLs.SourceContent != nil {
+				globalMirrorMetrics.transferred(sURLs.SourceContent.Size)
+			} else if sURLs.TargetContent != nil {
+				globalMirrorMetrics.removed()
+			}
+			if mj.session != nil {
+				// Record the entry as synced in the session.
+				mj.markSynced(sURLs)
+			}
 		}
 
 		if sURLs.SourceContent != nil {
-		} else if sURLs.TargetContent != nil {
+		} else if sURLs.TargetContent != nil && sURLs.Error == nil {
 			// Construct user facing message and path.
 			targetPath := filepath.ToSlash(filepath.Join(sURLs.TargetAlias, sURLs.TargetContent.URL.Path))
 			size := sURLs.TargetContent.Size
@@ -384,7 +684,8 @@ func (mj *mirrorJob) monitorMirrorStatus() (errDuringMirror bool) {
 	return
 }
 
-// this goroutine will watch for notifications, and add modified objects to the queue
+// this goroutine will watch for notifications, and add modified objects to the queue,
+// transfers run within the worker pool shared with other jobs, if any.
 func (mj *mirrorJob) watchMirror(ctx context.Context, cancelMirror context.CancelFunc) {
 	for {
 		select {
@@ -392,6 +693,7 @@ func (mj *mirrorJob) watchMirror(ctx context.Context, cancelMirror context.Cance
 			if !ok {
 				return
 			}
+			globalMirrorMetrics.watchEvent(event.Type)
 
 			// It will change the expanded alias back to the alias
 			// again, by replacing the sourceUrlFull with the sourceAlias.
@@ -420,8 +722,8 @@ func (mj *mirrorJob) watchMirror(ctx context.Context, cancelMirror context.Cance
 			// build target path, it is the relative of the eventPath with the sourceUrl
 			// joined to the targetURL.
 			sourceSuffix := strings.TrimPrefix(eventPath, sourceURLFull)
-			//Skip the object, if it matches the Exclude options provided
-			if matchExcludeOptions(mj.excludeOptions, sourceSuffix) {
+			//Skip the object, if it is excluded by the filter provided
+			if mj.filter.isExcluded(sourceSuffix) {
 				continue
 			}
 
@@ -447,7 +749,7 @@ func (mj *mirrorJob) watchMirror(ctx context.Context, cancelMirror context.Cance
 				sourceClient, err := newClient(aliasedPath)
 				if err != nil {
 					// cannot create sourceclient
-					mj.statusCh <- mirrorURL.WithError(err)
+					mj.report(mirrorURL.WithError(err))
 					continue
 				}
 				// we are checking if a destination file exists now, and if we only
@@ -455,7 +757,7 @@ func (mj *mirrorJob) watchMirror(ctx context.Context, cancelMirror context.Cance
 				sourceContent, err := sourceClient.Stat(false, true, false, srcSSE)
 				if err != nil {
 					// source doesn't exist anymore
-					mj.statusCh <- mirrorURL.WithError(err)
+					mj.report(mirrorURL.WithError(err))
 					continue
 				}
 				if sourceContent.Metadata[multiMasterETagKey] != "" {
@@ -468,7 +770,7 @@ func (mj *mirrorJob) watchMirror(ctx context.Context, cancelMirror context.Cance
 					targetClient, err := newClient(targetPath)
 					if err != nil {
 						// cannot create targetclient
-						mj.statusCh <- mirrorURL.WithError(err)
+						mj.report(mirrorURL.WithError(err))
 						return
 					}
 					shouldQueue := false
@@ -487,7 +789,9 @@ func (mj *mirrorJob) watchMirror(ctx context.Context, cancelMirror context.Cance
 						mj.status.AddCounts(1)
 						mirrorURL.TotalSize = mj.status.Get()
 						mirrorURL.TotalCount = mj.status.GetCounts()
-						mj.statusCh <- mj.doMirror(ctx, cancelMirror, mirrorURL)
+						mj.reportWatch(mj.parallel.pool.run(func() URLs {
+							return mj.doMirror(ctx, cancelMirror, mirrorURL)
+						}))
 					}
 					continue
 				}
@@ -496,7 +800,7 @@ func (mj *mirrorJob) watchMirror(ctx context.Context, cancelMirror context.Cance
 					targetClient, err := newClient(targetPath)
 					if err != nil {
 						// cannot create targetclient
-						mj.statusCh <- mirrorURL.WithError(err)
+						mj.report(mirrorURL.WithError(err))
 						return
 					}
 					_, err = targetClient.Stat(false, false, false, tgtSSE)
@@ -518,7 +822,9 @@ func (mj *mirrorJob) watchMirror(ctx context.Context, cancelMirror context.Cance
 					mj.status.AddCounts(1)
 					mirrorURL.TotalSize = mj.status.Get()
 					mirrorURL.TotalCount = mj.status.GetCounts()
-					mj.statusCh <- mj.doMirror(ctx, cancelMirror, mirrorURL)
+					mj.reportWatch(mj.parallel.pool.run(func() URLs {
+						return mj.doMirror(ctx, cancelMirror, mirrorURL)
+					}))
 				}
 			} else if event.Type == EventRemove {
 				if strings.Contains(event.UserAgent, uaMirrorAppName) {
@@ -534,7 +840,13 @@ func (mj *mirrorJob) watchMirror(ctx context.Context, cancelMirror context.Cance
 				mirrorURL.TotalCount = mj.status.GetCounts()
 				mirrorURL.TotalSize = mj.status.Get()
 				if mirrorURL.TargetContent != nil && (mj.isRemove || mj.multiMasterEnable) {
-					mj.statusCh <- mj.doRemove(mirrorURL)
+					if err := mj.removals.checkWatchRemove(mj.isSourceEmpty); err != nil {
+						mj.report(mirrorURL.WithError(err.Trace(mj.sourceURL, mj.targetURL)))
+						continue
+					}
+					mj.reportWatch(mj.parallel.pool.run(func() URLs {
+						return mj.doRemove(mirrorURL)
+					}))
 				}
 			}
 
@@ -544,7 +856,7 @@ func (mj *mirrorJob) watchMirror(ctx context.Context, cancelMirror context.Cance
 				errorIf(err.Trace(), "Unable to Watch on source, ignoring.")
 				return
 			}
-			mj.statusCh <- URLs{Error: err}
+			mj.report(URLs{Error: err})
 			return
 		case <-mj.trapCh:
 			return
@@ -554,14 +866,68 @@ func (mj *mirrorJob) watchMirror(ctx context.Context, cancelMirror context.Cance
 	}
 }
 
+// isSourceEmpty - returns true when nothing is found on source, listing
+// only its top level until a first entry is found.
+func (mj *mirrorJob) isSourceEmpty() (bool, *probe.Error) {
+	sourceClnt, err := newClient(mj.sourceURL)
+	if err != nil {
+		return false, err.Trace(mj.sourceURL)
+	}
+	contentCh := sourceClnt.List(false, false, false, DirNone)
+	// Listings cannot be stopped, drain the rest in background.
+	defer func() {
+		go func() {
+			for range contentCh {
+			}
+		}()
+	}()
+	for content := range contentCh {
+		if content.Err != nil {
+			return false, content.Err.Trace(mj.sourceURL)
+		}
+		return false, nil
+	}
+	return true, nil
+}
+
 func (mj *mirrorJob) watchURL(sourceClient Client) *probe.Error {
 	return mj.watcher.Join(sourceClient, true)
 }
 
+// account - skips objects out of the age filters and adds the others
+// to the totals of the progress status.
+func (mj *mirrorJob) account(sURLs URLs) (URLs, bool) {
+	if sURLs.SourceContent != nil {
+		if mj.olderThan != "" && isOlder(sURLs.SourceContent.Time, mj.olderThan) {
+			return sURLs, false
+		}
+		if mj.newerThan != "" && isNewer(sURLs.SourceContent.Time, mj.newerThan) {
+			return sURLs, false
+		}
+	}
+
+	if sURLs.SourceContent != nil {
+		mj.status.Add(sURLs.SourceContent.Size)
+	}
+	mj.status.SetTotal(mj.status.Get()).Update()
+	mj.status.AddCounts(1)
+
+	// Save total count.
+	sURLs.TotalCount = mj.status.GetCounts()
+	// Save totalSize.
+	sURLs.TotalSize = mj.status.Get()
+	return sURLs, true
+}
+
 // Fetch urls that need to be mirrored
 func (mj *mirrorJob) startMirror(ctx context.Context, cancelMirror context.CancelFunc, stopParallel func()) {
+	if len(mj.targetURLs) > 0 {
+		mj.startMirrorMulti(ctx, cancelMirror, stopParallel)
+		return
+	}
+
 	isMetadata := len(mj.userMetadata) > 0 || mj.isPreserve
-	URLsCh := prepareMirrorURLs(mj.sourceURL, mj.targetURL, mj.isFake, mj.isOverwrite, mj.isRemove, isMetadata, mj.excludeOptions, mj.encKeyDB)
+	URLsCh := prepareMirrorURLs(mj.sourceURL, mj.targetURL, mj.isFake, mj.isOverwrite, mj.isRemove, isMetadata, mj.isTransformed(), mj.filter, mj.removals, mj.encKeyDB)
 
 	for {
 		select {
@@ -573,38 +939,23 @@ func (mj *mirrorJob) startMirror(ctx context.Context, cancelMirror context.Cance
 				return
 			}
 			if sURLs.Error != nil {
-				mj.statusCh <- sURLs
+				mj.report(sURLs)
 				continue
 			}
 
-			if sURLs.SourceContent != nil {
-				if mj.olderThan != "" && isOlder(sURLs.SourceContent.Time, mj.olderThan) {
-					continue
-				}
-				if mj.newerThan != "" && isNewer(sURLs.SourceContent.Time, mj.newerThan) {
-					continue
-				}
-			}
-
-			if sURLs.SourceContent != nil {
-				mj.status.Add(sURLs.SourceContent.Size)
+			sURLs, ok = mj.account(sURLs)
+			if !ok {
+				continue
 			}
-			mj.status.SetTotal(mj.status.Get()).Update()
-			mj.status.AddCounts(1)
-
-			// Save total count.
-			sURLs.TotalCount = mj.status.GetCounts()
-			// Save totalSize.
-			sURLs.TotalSize = mj.status.Get()
 
 			if sURLs.SourceContent != nil {
-				mj.queueCh <- func() URLs {
+				mj.queue(func() URLs {
 					return mj.doMirror(ctx, cancelMirror, sURLs)
-				}
+				})
 			} else if sURLs.TargetContent != nil && mj.isRemove {
-				mj.queueCh <- func() URLs {
+				mj.queue(func() URLs {
 					return mj.doRemove(sURLs)
-				}
+				})
 			}
 		case <-mj.trapCh:
 			if stopParallel != nil {
@@ -627,6 +978,9 @@ func (mj *mirrorJob) mirror(ctx context.Context, cancelMirror context.CancelFunc
 
 	var wg sync.WaitGroup
 
+	// Removals of the listing and of the watch events are accounted together.
+	mj.removals = newRemoveAccount(mj.removeLimits)
+
 	// Starts watcher loop for watching for new events.
 	if mj.isWatch {
 		wg.Add(1)
@@ -645,6 +999,10 @@ func (mj *mirrorJob) mirror(ctx context.Context, cancelMirror context.CancelFunc
 			mj.parallel.wait()
 		}
 		mj.startMirror(ctx, cancelMirror, stopParallel)
+		if !mj.isFake && atomic.LoadInt64(&mj.failures) == 0 {
+			atomic.StoreInt32(&mj.isSynced, 1)
+			globalMirrorMetrics.synced()
+		}
 	}()
 
 	// TODO: Remove this code when we fix
@@ -684,12 +1042,11 @@ func (mj *mirrorJob) mirror(ctx context.Context, cancelMirror context.CancelFunc
 	return mj.monitorMirrorStatus()
 }
 
-func newMirrorJob(srcURL, dstURL string, isFake, isRemove, isOverwrite, isWatch, isPreserve, multiMasterEnable bool, excludeOptions []string, olderThan, newerThan string, storageClass string, multiMasterSTag string, userMetadata map[string]string, encKeyDB map[string][]prefixSSEPair) *mirrorJob {
+func newMirrorJob(srcURL, dstURL string, isFake, isRemove, isOverwrite, isWatch, isPreserve, multiMasterEnable bool, filter urlFilter, olderThan, newerThan string, storageClass string, multiMasterSTag string, userMetadata map[string]string, encKeyDB map[string][]prefixSSEPair) *mirrorJob {
 	if multiMasterEnable {
 		isPreserve = true
 	}
 	mj := mirrorJob{
-		trapCh: signalTrap(os.Interrupt, syscall.SIGTERM, syscall.SIGKILL),
 		stopCh: make(chan struct{}),
 		m:      new(sync.Mutex),
 
@@ -701,7 +1058,7 @@ func newMirrorJob(srcURL, dstURL string, isFake, isRemove, isOverwrite, isWatch,
 		isOverwrite:       isOverwrite,
 		isWatch:           isWatch,
 		isPreserve:        isPreserve,
-		excludeOptions:    excludeOptions,
+		filter:            filter,
 		olderThan:         olderThan,
 		newerThan:         newerThan,
 		storageClass:      storageClass,
@@ -752,22 +1109,43 @@ func copyBucketPolicies(srcClt, dstClt Client, isOverwrite bool) *probe.Error {
 	return nil
 }
 
-// runMirror - mirrors all buckets to another S3 server
-func runMirror(srcURL, dstURL string, ctx *cli.Context, encKeyDB map[string][]prefixSSEPair) bool {
-	// This is kept for backward compatibility, `--force` means
-	// --overwrite.
-	isOverwrite := ctx.Bool("force")
-	if !isOverwrite {
-		isOverwrite = ctx.Bool("overwrite")
+// prepareMirrorBucket - creates the target bucket if it does not exist
+// yet, with the object lock configuration of the source, and copies the
+// bucket policies. Only an error creating the bucket is returned, other
+// errors are reported and flagged through errorDetected.
+func prepareMirrorBucket(srcClt, dstClt Client, region string, isOverwrite bool) (errorDetected bool, err *probe.Error) {
+	withLock := false
+	mode, validity, unit, err := srcClt.GetObjectLockConfig()
+	if err == nil {
+		withLock = true
 	}
 
-	// Parse metadata.
-	userMetaMap := make(map[string]string)
-	if ctx.String("attr") != "" {
-		var err *probe.Error
-		userMetaMap, err = getMetaDataEntry(ctx.String("attr"))
-		fatalIf(err, "Unable to parse attribute %v", ctx.String("attr"))
+	// Create bucket if it doesn't exist at destination.
+	// ignore if already exists.
+	if err = dstClt.MakeBucket(region, true, withLock); err != nil {
+		return false, err
+	}
+
+	// object lock configuration set on bucket
+	if mode != nil {
+		err = dstClt.SetObjectLockConfig(mode, validity, unit)
+		errorIf(err, "Unable to set object lock config in `"+dstClt.GetURL().String()+"`.")
+		if err != nil {
+			errorDetected = true
+		}
+	}
+
+	err = copyBucketPolicies(srcClt, dstClt, isOverwrite)
+	errorIf(err, "Unable to copy bucket policies to `"+dstClt.GetURL().String()+"`.")
+	if err != nil {
+		errorDetected = true
 	}
+	return errorDetected, nil
+}
+
+// runMirror - mirrors all buckets to another S3 server
+func runMirror(srcURL, dstURL string, opts mirrorOptions, session *sessionV8, encKeyDB map[string][]prefixSSEPair) bool {
+	isOverwrite := opts.isOverwrite
 
 	srcClt, err := newClient(srcURL)
 	fatalIf(err, "Unable to initialize `"+srcURL+"`.")
@@ -789,27 +1167,43 @@ func runMirror(srcURL, dstURL string, ctx *cli.Context, encKeyDB map[string][]pr
 		fatalIf(err, "Unable to initialize `"+dstURL+"`.")
 	}
 
-	multiMasterSTag := ctx.String("multi-master")
+	multiMasterSTag := opts.multiMasterSTag
 	multiMasterEnable := multiMasterSTag != ""
 
+	// Honor the ignore file at the root of a single source folder.
+	filter := opts.filter
+	if !mirrorAllBuckets {
+		filter, err = filter.withIgnoreFile(srcURL, encKeyDB)
+		fatalIf(err, "Unable to read `"+mcIgnoreFile+"` from `"+srcURL+"`.")
+	}
+
 	// Create a new mirror job and execute it
 	mj := newMirrorJob(srcURL, dstURL,
-		ctx.Bool("fake"),
-		ctx.Bool("remove"),
+		opts.isFake,
+		opts.isRemove,
 		isOverwrite,
-		ctx.Bool("watch"),
-		ctx.Bool("a"),
+		opts.isWatch,
+		opts.isPreserve,
 		multiMasterEnable,
-		ctx.StringSlice("exclude"),
-		ctx.String("older-than"),
-		ctx.String("newer-than"),
-		ctx.String("storage-class"),
+		filter,
+		opts.olderThan,
+		opts.newerThan,
+		opts.storageClass,
 		multiMasterSTag,
-		userMetaMap,
+		opts.userMetadata,
 		encKeyDB)
+	mj.removeLimits = opts.removeLimits
+	mj.backupDir = opts.backupDir
+	mj.compress = opts.compress
+	mj.isDecompress = opts.isDecompress
+	mj.trapCh = signalTrap(os.Interrupt, syscall.SIGTERM, syscall.SIGKILL)
 
 	go func() {
 		<-mj.trapCh
+		if session != nil {
+			// Keep the session around to be resumed later.
+			session.CloseAndDie()
+		}
 		os.Exit(globalErrorExitStatus)
 	}()
 
@@ -842,7 +1236,7 @@ func runMirror(srcURL, dstURL string, ctx *cli.Context, encKeyDB map[string][]pr
 					withLock = true
 				}
 				// Bucket only exists in the source, create the same bucket in the destination
-				if err := newDstClt.MakeBucket(ctx.String("region"), false, withLock); err != nil {
+				if err := newDstClt.MakeBucket(opts.region, false, withLock); err != nil {
 					errorIf(err, "Unable to create bucket at `"+newTgtURL+"`.")
 					continue
 				}
@@ -868,37 +1262,15 @@ func runMirror(srcURL, dstURL string, ctx *cli.Context, encKeyDB map[string][]pr
 			}
 		}
 	} else {
-		withLock := false
-		mode, validity, unit, err := srcClt.GetObjectLockConfig()
-		if err == nil {
-			withLock = true
-		}
-
-		// Create bucket if it doesn't exist at destination.
-		// ignore if already exists.
-		if mj.multiMasterEnable {
-			err = dstClt.MakeBucket(ctx.String("region"), true, withLock)
-			errorIf(err, "Unable to create bucket at `"+dstURL+"`.")
-			if err != nil {
-				return true
-			}
-		} else {
-			mj.status.fatalIf(dstClt.MakeBucket(ctx.String("region"), true, withLock),
-				"Unable to create bucket at `"+dstURL+"`.")
-		}
-
-		// object lock configuration set on bucket
-		if mode != nil {
-			err = dstClt.SetObjectLockConfig(mode, validity, unit)
-			errorIf(err, "Unable to set object lock config in `"+dstURL+"`.")
-			if err != nil && mj.multiMasterEnable {
+		errorDetected, err := prepareMirrorBucket(srcClt, dstClt, opts.region, isOverwrite)
+		if err != nil {
+			if mj.multiMasterEnable {
+				errorIf(err, "Unable to create bucket at `"+dstURL+"`.")
 				return true
 			}
+			mj.status.fatalIf(err, "Unable to create bucket at `"+dstURL+"`.")
 		}
-
-		err = copyBucketPolicies(srcClt, dstClt, isOverwrite)
-		errorIf(err, "Unable to copy bucket policies to `"+dstClt.GetURL().String()+"`.")
-		if err != nil && mj.multiMasterEnable {
+		if errorDetected && mj.multiMasterEnable {
 			return true
 		}
 	}
@@ -918,6 +1290,11 @@ func runMirror(srcURL, dstURL string, ctx *cli.Context, encKeyDB map[string][]pr
 	ctxt, cancelMirror := context.WithCancel(context.Background())
 	defer cancelMirror()
 
+	if session != nil {
+		// Start mirroring the entries planned in the session
+		return mj.mirrorSession(ctxt, cancelMirror, session)
+	}
+
 	// Start mirroring job
 	return mj.mirror(ctxt, cancelMirror)
 }
@@ -927,6 +1304,16 @@ func mainMirror(ctx *cli.Context) error {
 	// Parse encryption keys per command.
 	encKeyDB, err := getEncKeys(ctx)
 	fatalIf(err, "Unable to parse encryption keys.")
+	setParallelListFromContext(ctx)
+
+	if addr := ctx.String("metrics-addr"); addr != "" {
+		fatalIf(startMetricsServer(addr), "Unable to serve metrics at `"+addr+"`.")
+	}
+
+	// Run the jobs of a configuration file.
+	if ctx.String("config") != "" {
+		return mainMirrorDaemon(ctx, encKeyDB)
+	}
 
 	// check 'mirror' cli arguments.
 	checkMirrorSyntax(ctx, encKeyDB)
@@ -939,14 +1326,27 @@ func mainMirror(ctx *cli.Context) error {
 	srcURL := args[0]
 	tgtURL := args[1]
 
-	if ctx.String("multi-master") != "" {
+	opts := newMirrorOptions(ctx)
+
+	if ctx.Bool("continue") {
+		return mainMirrorSession(ctx, opts)
+	}
+
+	if len(args) > 2 {
+		if errorDetected := runMirrorMulti(srcURL, args[1:], opts, encKeyDB); errorDetected {
+			return exitStatus(globalErrorExitStatus)
+		}
+		return nil
+	}
+
+	if opts.multiMasterSTag != "" {
 		for {
-			runMirror(srcURL, tgtURL, ctx, encKeyDB)
+			runMirror(srcURL, tgtURL, opts, nil, encKeyDB)
 			time.Sleep(time.Second * 2)
 		}
 	}
 
-	if errorDetected := runMirror(srcURL, tgtURL, ctx, encKeyDB); errorDetected {
+	if errorDetected := runMirror(srcURL, tgtURL, opts, nil, encKeyDB); errorDetected {
 		return exitStatus(globalErrorExitStatus)
 	}
 
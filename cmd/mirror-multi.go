@@ -0,0 +1,180 @@
+/*
+ * MinIO Client (C) 2019 MinIO, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"context"
+	"os"
+	"syscall"
+)
+
+// doMirrorMulti - mirrors one source object to several targets, reading
+// it only once. The status of all targets but the last one is sent on
+// the status channel, the last one is returned to the worker.
+func (mj *mirrorJob) doMirrorMulti(ctx context.Context, cancelMirror context.CancelFunc, group []URLs) URLs {
+	var results, uploads []URLs
+	for _, sURLs := range group {
+		if mj.isFake {
+			results = append(results, mj.doMirror(ctx, cancelMirror, sURLs))
+			continue
+		}
+		sURLs = mj.prepareMirrorTarget(sURLs)
+		if sURLs.Error != nil {
+			results = append(results, sURLs)
+			continue
+		}
+		uploads = append(uploads, sURLs)
+	}
+	if len(uploads) > 0 {
+		results = append(results, uploadSourceToTargetURLs(ctx, uploads, mj.status, mj.encKeyDB)...)
+	}
+
+	for _, result := range results[:len(results)-1] {
+		mj.report(result)
+	}
+	return results[len(results)-1]
+}
+
+// startMirrorMulti - fetches the objects to copy or remove on all
+// targets, and queues them grouped by source object.
+func (mj *mirrorJob) startMirrorMulti(ctx context.Context, cancelMirror context.CancelFunc, stopParallel func()) {
+	isMetadata := len(mj.userMetadata) > 0 || mj.isPreserve
+	URLsCh := prepareMirrorMultiURLs(mj.sourceURL, mj.targetURLs, mj.isFake, mj.isOverwrite, mj.isRemove, isMetadata, mj.isTransformed(), mj.filter, mj.removeLimits, mj.encKeyDB)
+
+	for {
+		select {
+		case group, ok := <-URLsCh:
+			if !ok {
+				if stopParallel != nil {
+					stopParallel()
+				}
+				return
+			}
+
+			var queued []URLs
+			for _, sURLs := range group {
+				if sURLs.Error != nil {
+					mj.report(sURLs)
+					continue
+				}
+				if sURLs, ok = mj.account(sURLs); ok {
+					queued = append(queued, sURLs)
+				}
+			}
+			if len(queued) == 0 {
+				continue
+			}
+
+			if queued[0].SourceContent != nil {
+				mj.queue(func() URLs {
+					return mj.doMirrorMulti(ctx, cancelMirror, queued)
+				})
+			} else if queued[0].TargetContent != nil && mj.isRemove {
+				sURLs := queued[0]
+				mj.queue(func() URLs {
+					return mj.doRemove(sURLs)
+				})
+			}
+		case <-mj.trapCh:
+			if stopParallel != nil {
+				stopParallel()
+			}
+			cancelMirror()
+			return
+		case <-mj.stopCh:
+			if stopParallel != nil {
+				stopParallel()
+			}
+			cancelMirror()
+			return
+		}
+	}
+}
+
+// runMirrorMulti - mirrors a folder or a bucket to several targets in
+// one pass. A target whose bucket cannot be prepared is reported and
+// left out, the others are mirrored anyway.
+func runMirrorMulti(srcURL string, dstURLs []string, opts mirrorOptions, encKeyDB map[string][]prefixSSEPair) bool {
+	srcClt, err := newClient(srcURL)
+	fatalIf(err, "Unable to initialize `"+srcURL+"`.")
+
+	if srcClt.GetURL().Type == objectStorage && srcClt.GetURL().Path == string(srcClt.GetURL().Separator) {
+		fatalIf(errInvalidArgument().Trace(srcURL), "Mirroring all buckets of `"+srcURL+"` is only supported to a single target.")
+	}
+
+	var errorDetected bool
+	var targetURLs []string
+	for _, dstURL := range dstURLs {
+		dstClt, err := newClient(dstURL)
+		fatalIf(err, "Unable to initialize `"+dstURL+"`.")
+
+		// Mirror the bucket of the source into a bucket of the same name.
+		if dstClt.GetURL().Type == objectStorage && dstClt.GetURL().Path == string(dstClt.GetURL().Separator) {
+			dstURL = urlJoinPath(dstURL, srcClt.GetURL().Path)
+			dstClt, err = newClient(dstURL)
+			fatalIf(err, "Unable to initialize `"+dstURL+"`.")
+		}
+
+		if _, err = prepareMirrorBucket(srcClt, dstClt, opts.region, opts.isOverwrite); err != nil {
+			errorIf(err, "Unable to create bucket at `"+dstURL+"`.")
+			errorDetected = true
+			continue
+		}
+		targetURLs = append(targetURLs, dstURL)
+	}
+	if len(targetURLs) == 0 {
+		return true
+	}
+
+	// Honor the ignore file at the root of the source folder.
+	filter, err := opts.filter.withIgnoreFile(srcURL, encKeyDB)
+	fatalIf(err, "Unable to read `"+mcIgnoreFile+"` from `"+srcURL+"`.")
+
+	// Create a new mirror job and execute it
+	mj := newMirrorJob(srcURL, targetURLs[0],
+		opts.isFake,
+		opts.isRemove,
+		opts.isOverwrite,
+		false,
+		opts.isPreserve,
+		false,
+		filter,
+		opts.olderThan,
+		opts.newerThan,
+		opts.storageClass,
+		"",
+		opts.userMetadata,
+		encKeyDB)
+	mj.targetURLs = targetURLs
+	mj.removeLimits = opts.removeLimits
+	mj.compress = opts.compress
+	mj.isDecompress = opts.isDecompress
+	mj.trapCh = signalTrap(os.Interrupt, syscall.SIGTERM, syscall.SIGKILL)
+
+	go func() {
+		<-mj.trapCh
+		os.Exit(globalErrorExitStatus)
+	}()
+
+	ctxt, cancelMirror := context.WithCancel(context.Background())
+	defer cancelMirror()
+
+	if mj.mirror(ctxt, cancelMirror) {
+		errorDetected = true
+	}
+	return errorDetected
+}
@@ -0,0 +1,361 @@
+/*
+ * MinIO Client (C) 2019 MinIO, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/console"
+	"github.com/minio/mc/pkg/probe"
+)
+
+// sessionSyncedList - keeps track of the mirror session entries which
+// are already synced, persisted one entry per line so that a resumed
+// session skips them without any further listing or comparison.
+type sessionSyncedList struct {
+	mutex  *sync.Mutex
+	file   *os.File
+	synced map[string]struct{}
+}
+
+// loadSessionSyncedList - loads the list of synced entries of a
+// session, the list is created if it does not exist yet.
+func loadSessionSyncedList(sid string) (*sessionSyncedList, *probe.Error) {
+	syncedFile, err := getSessionSyncedFile(sid)
+	if err != nil {
+		return nil, err.Trace(sid)
+	}
+	file, e := os.OpenFile(syncedFile, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0600)
+	if e != nil {
+		return nil, probe.NewError(e).Trace(syncedFile)
+	}
+	l := &sessionSyncedList{
+		mutex:  new(sync.Mutex),
+		file:   file,
+		synced: make(map[string]struct{}),
+	}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var key string
+		if e = json.Unmarshal(scanner.Bytes(), &key); e != nil {
+			// Ignore a partially written last entry.
+			continue
+		}
+		l.synced[key] = struct{}{}
+	}
+	if e = scanner.Err(); e != nil {
+		file.Close()
+		return nil, probe.NewError(e).Trace(syncedFile)
+	}
+	return l, nil
+}
+
+// sessionSyncedKey - key identifying an entry of the mirror plan, the
+// target is unique across copies and removals.
+func sessionSyncedKey(sURLs URLs) string {
+	return sURLs.TargetAlias + ":" + sURLs.TargetContent.URL.String()
+}
+
+// isSynced - returns true if the entry is already synced.
+func (l *sessionSyncedList) isSynced(sURLs URLs) bool {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	_, ok := l.synced[sessionSyncedKey(sURLs)]
+	return ok
+}
+
+// add - records the entry as synced.
+func (l *sessionSyncedList) add(sURLs URLs) *probe.Error {
+	key := sessionSyncedKey(sURLs)
+	keyJSON, e := json.Marshal(key)
+	if e != nil {
+		return probe.NewError(e)
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if _, e = l.file.Write(append(keyJSON, '\n')); e != nil {
+		return probe.NewError(e)
+	}
+	l.synced[key] = struct{}{}
+	return nil
+}
+
+// Close - flushes and closes the list.
+func (l *sessionSyncedList) Close() *probe.Error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if e := l.file.Sync(); e != nil {
+		l.file.Close()
+		return probe.NewError(e)
+	}
+	if e := l.file.Close(); e != nil {
+		return probe.NewError(e)
+	}
+	return nil
+}
+
+// saveMirrorOptions - saves mirror options into the session header.
+func saveMirrorOptions(header *sessionV8Header, opts mirrorOptions) {
+	header.CommandBoolFlags["remove"] = opts.isRemove
+	header.CommandBoolFlags["overwrite"] = opts.isOverwrite
+	header.CommandBoolFlags["preserve"] = opts.isPreserve
+	header.CommandBoolFlags["decompress"] = opts.isDecompress
+	header.CommandBoolFlags["allow-empty-source"] = opts.removeLimits.allowEmptySource
+	header.CommandStringFlags["older-than"] = opts.olderThan
+	header.CommandStringFlags["newer-than"] = opts.newerThan
+	header.CommandStringFlags["storage-class"] = opts.storageClass
+	header.CommandStringFlags["compress"] = opts.compress
+	header.CommandStringFlags["region"] = opts.region
+	header.CommandStringFlags["max-delete"] = opts.removeLimits.String()
+	header.CommandStringFlags["backup-dir"] = opts.backupDir
+	header.CommandStringSliceFlags["include"] = opts.filter.includeOptions
+	header.CommandStringSliceFlags["exclude"] = opts.filter.excludeOptions
+	header.UserMetaData = opts.userMetadata
+}
+
+// loadMirrorOptions - restores mirror options from the session header.
+func loadMirrorOptions(header *sessionV8Header) mirrorOptions {
+	limits, err := parseRemoveLimits(header.CommandStringFlags["max-delete"], header.CommandBoolFlags["allow-empty-source"])
+	fatalIf(err.Trace(header.CommandStringFlags["max-delete"]), "Unable to parse `--max-delete` of the session.")
+
+	return mirrorOptions{
+		isRemove:     header.CommandBoolFlags["remove"],
+		isOverwrite:  header.CommandBoolFlags["overwrite"],
+		isPreserve:   header.CommandBoolFlags["preserve"],
+		isDecompress: header.CommandBoolFlags["decompress"],
+		olderThan:    header.CommandStringFlags["older-than"],
+		newerThan:    header.CommandStringFlags["newer-than"],
+		storageClass: header.CommandStringFlags["storage-class"],
+		compress:     header.CommandStringFlags["compress"],
+		region:       header.CommandStringFlags["region"],
+		backupDir:    header.CommandStringFlags["backup-dir"],
+		removeLimits: limits,
+		userMetadata: header.UserMetaData,
+		filter: urlFilter{
+			includeOptions: header.CommandStringSliceFlags["include"],
+			excludeOptions: header.CommandStringSliceFlags["exclude"],
+		},
+	}
+}
+
+// doMirrorSession - runs or resumes a mirror session, the session is
+// kept around if any entry failed so that it can be resumed later.
+func doMirrorSession(session *sessionV8, encKeyDB map[string][]prefixSSEPair) {
+	args := session.Header.CommandArgs
+	opts := loadMirrorOptions(session.Header)
+	if errorDetected := runMirror(args[0], args[1], opts, session, encKeyDB); errorDetected {
+		session.CloseAndDie()
+	}
+}
+
+// mainMirrorSession - creates or resumes the mirror session matching
+// the command line arguments.
+func mainMirrorSession(ctx *cli.Context, opts mirrorOptions) error {
+	sseKeys := os.Getenv("MC_ENCRYPT_KEY")
+	if key := ctx.String("encrypt-key"); key != "" {
+		sseKeys = key
+	}
+	if sseKeys != "" {
+		var err *probe.Error
+		sseKeys, err = getDecodedKey(sseKeys)
+		fatalIf(err, "Unable to parse encryption keys.")
+	}
+	sse := os.Getenv("MC_ENCRYPT")
+	if prefix := ctx.String("encrypt"); prefix != "" {
+		sse = prefix
+	}
+
+	sessionID := getHash("mirror", ctx.Args())
+	if isSessionExists(sessionID) {
+		resumeSession(sessionID)
+		return nil
+	}
+
+	session := newSessionV8(sessionID)
+	session.Header.CommandType = "mirror"
+	session.Header.CommandStringFlags["encrypt-key"] = sseKeys
+	session.Header.CommandStringFlags["encrypt"] = sse
+	saveMirrorOptions(session.Header, opts)
+
+	var e error
+	if session.Header.RootPath, e = os.Getwd(); e != nil {
+		session.Delete()
+		fatalIf(probe.NewError(e), "Unable to get current working folder.")
+	}
+	session.Header.CommandArgs = ctx.Args()
+
+	encKeyDB, err := parseAndValidateEncryptionKeys(sseKeys, sse)
+	fatalIf(err, "Unable to parse encryption keys.")
+
+	doMirrorSession(session, encKeyDB)
+	session.Delete()
+	return nil
+}
+
+// prepareMirrorSession - computes the delta between source and target
+// once and saves the entries to copy or remove in the session data file.
+func (mj *mirrorJob) prepareMirrorSession(session *sessionV8) {
+	dataFP := session.NewDataWriter()
+
+	var scanBar scanBarFunc
+	if !globalQuiet && !globalJSON { // set up progress bar
+		scanBar = scanBarFactory()
+	}
+
+	var totalBytes, totalObjects int64
+	isMetadata := len(mj.userMetadata) > 0 || mj.isPreserve
+	URLsCh := prepareMirrorURLs(mj.sourceURL, mj.targetURL, mj.isFake, mj.isOverwrite, mj.isRemove, isMetadata, mj.isTransformed(), mj.filter, newRemoveAccount(mj.removeLimits), mj.encKeyDB)
+	for sURLs := range URLsCh {
+		if sURLs.Error != nil {
+			// Print in new line and adjust to top so that we don't print over the ongoing scan bar
+			if !globalQuiet && !globalJSON {
+				console.Eraseline()
+			}
+			errorIf(sURLs.Error.Trace(), "Unable to prepare URL for mirroring.")
+			continue
+		}
+
+		if sURLs.SourceContent != nil {
+			if mj.olderThan != "" && isOlder(sURLs.SourceContent.Time, mj.olderThan) {
+				continue
+			}
+			if mj.newerThan != "" && isNewer(sURLs.SourceContent.Time, mj.newerThan) {
+				continue
+			}
+		} else if !mj.isRemove {
+			continue
+		}
+
+		jsonData, e := json.Marshal(sURLs)
+		if e != nil {
+			session.Delete()
+			fatalIf(probe.NewError(e), "Unable to prepare URL for mirroring. Error in JSON marshaling.")
+		}
+		fmt.Fprintln(dataFP, string(jsonData))
+
+		if sURLs.SourceContent != nil {
+			if scanBar != nil {
+				scanBar(sURLs.SourceContent.URL.String())
+			}
+			totalBytes += sURLs.SourceContent.Size
+		}
+		totalObjects++
+	}
+
+	session.Header.TotalBytes = totalBytes
+	session.Header.TotalObjects = totalObjects
+	session.Save()
+}
+
+// startMirrorSession - queues the entries planned in the session which
+// are not synced yet.
+func (mj *mirrorJob) startMirrorSession(ctx context.Context, cancelMirror context.CancelFunc, session *sessionV8) {
+	urlScanner := bufio.NewScanner(session.NewDataReader())
+	for urlScanner.Scan() {
+		var sURLs URLs
+		// Unmarshal URLs from each line. This expects each line to be
+		// an entire JSON object.
+		if e := json.Unmarshal([]byte(urlScanner.Text()), &sURLs); e != nil {
+			errorIf(probe.NewError(e), "Unable to unmarshal %s", urlScanner.Text())
+			continue
+		}
+		if sURLs.TargetContent == nil || mj.synced.isSynced(sURLs) {
+			continue
+		}
+		sURLs.encKeyDB = mj.encKeyDB
+
+		if sURLs.SourceContent != nil {
+			mj.status.Add(sURLs.SourceContent.Size)
+		}
+		mj.status.SetTotal(mj.status.Get()).Update()
+		mj.status.AddCounts(1)
+
+		// Save total count.
+		sURLs.TotalCount = mj.status.GetCounts()
+		// Save totalSize.
+		sURLs.TotalSize = mj.status.Get()
+
+		if sURLs.SourceContent != nil {
+			mj.queue(func() URLs {
+				return mj.doMirror(ctx, cancelMirror, sURLs)
+			})
+		} else {
+			mj.queue(func() URLs {
+				return mj.doRemove(sURLs)
+			})
+		}
+	}
+	if e := urlScanner.Err(); e != nil {
+		errorIf(probe.NewError(e), "Unable to read mirror session data.")
+	}
+}
+
+// mirrorSession - mirrors the entries planned in the session, the plan
+// is computed only if the session does not have one yet.
+func (mj *mirrorJob) mirrorSession(ctx context.Context, cancelMirror context.CancelFunc, session *sessionV8) bool {
+	if session.Header.TotalObjects == 0 {
+		mj.prepareMirrorSession(session)
+	}
+
+	synced, err := loadSessionSyncedList(session.SessionID)
+	fatalIf(err.Trace(session.SessionID), "Unable to load mirror session.")
+	defer synced.Close()
+
+	mj.session = session
+	mj.synced = synced
+
+	go func() {
+		mj.startMirrorSession(ctx, cancelMirror, session)
+		close(mj.queueCh)
+		mj.parallel.wait()
+		if atomic.LoadInt64(&mj.failures) == 0 {
+			atomic.StoreInt32(&mj.isSynced, 1)
+			globalMirrorMetrics.synced()
+		}
+		close(mj.statusCh)
+	}()
+
+	return mj.monitorMirrorStatus()
+}
+
+// markSynced - records a successfully mirrored or removed entry.
+func (mj *mirrorJob) markSynced(sURLs URLs) {
+	if sURLs.TargetContent == nil {
+		return
+	}
+	if err := mj.synced.add(sURLs); err != nil {
+		errorIf(err.Trace(), "Unable to save mirror session.")
+		return
+	}
+	// Resuming skips only the entries of the synced list, which are
+	// recorded per target, the last copied and removed URLs are
+	// informational.
+	if sURLs.SourceContent != nil {
+		mj.session.Header.LastCopied = sURLs.SourceContent.URL.String()
+	} else {
+		mj.session.Header.LastRemoved = sURLs.TargetContent.URL.String()
+	}
+	mj.session.Save()
+}
@@ -17,11 +17,17 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
+	"path/filepath"
 	"runtime"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/probe"
 	"github.com/minio/minio/pkg/wildcard"
 )
 
@@ -29,17 +35,52 @@ import (
 //   * MIRROR ARGS - VALID CASES
 //   =========================
 //   mirror(d1..., d2) -> []mirror(d1/f, d2/d1/f)
+//   mirror(d1..., d2, d3) -> []mirror(d1/f, [d2/d1/f, d3/d1/f])
 
 // checkMirrorSyntax(URLs []string)
 func checkMirrorSyntax(ctx *cli.Context, encKeyDB map[string][]prefixSSEPair) {
-	if len(ctx.Args()) != 2 {
+	if len(ctx.Args()) < 2 {
 		cli.ShowCommandHelpAndExit(ctx, "mirror", 1) // last argument is exit code.
 	}
 
 	// extract URLs.
 	URLs := ctx.Args()
 	srcURL := URLs[0]
-	tgtURL := URLs[1]
+	tgtURLs := URLs[1:]
+
+	checkCompressSyntax(ctx)
+
+	if len(tgtURLs) > 1 {
+		if ctx.Bool("watch") || ctx.String("multi-master") != "" || ctx.Bool("continue") {
+			fatalIf(errInvalidArgument().Trace(URLs...), "`--watch`, `--multi-master` and `--continue` cannot be used with multiple targets.")
+		}
+		if ctx.String("backup-dir") != "" {
+			fatalIf(errInvalidArgument().Trace(URLs...), "`--backup-dir` cannot be used with multiple targets.")
+		}
+	}
+
+	if ctx.Bool("continue") {
+		if ctx.Bool("watch") || ctx.String("multi-master") != "" {
+			fatalIf(errInvalidArgument().Trace(URLs...), "`--continue` cannot be used with `--watch` or `--multi-master`.")
+		}
+		if ctx.Bool("fake") {
+			fatalIf(errInvalidArgument().Trace(URLs...), "`--continue` cannot be used with `--fake`.")
+		}
+	}
+
+	if !ctx.Bool("remove") && (ctx.String("max-delete") != "" || ctx.String("backup-dir") != "" || ctx.Bool("allow-empty-source")) {
+		fatalIf(errInvalidArgument().Trace(URLs...), "`--max-delete`, `--backup-dir` and `--allow-empty-source` can only be used with `--remove`.")
+	}
+	if _, err := parseRemoveLimits(ctx.String("max-delete"), ctx.Bool("allow-empty-source")); err != nil {
+		fatalIf(err.Trace(ctx.String("max-delete")), "Unable to parse `--max-delete`.")
+	}
+	if backupDir := ctx.String("backup-dir"); backupDir != "" {
+		_, expandedBackupDir, _ := mustExpandAlias(backupDir)
+		_, expandedTargetURL, _ := mustExpandAlias(tgtURLs[0])
+		if isURLContains(expandedTargetURL, expandedBackupDir, string(newClientURL(expandedTargetURL).Separator)) {
+			fatalIf(errInvalidArgument().Trace(backupDir), "Backup folder `"+backupDir+"` cannot be inside target `"+tgtURLs[0]+"`.")
+		}
+	}
 
 	if ctx.Bool("force") && ctx.Bool("remove") {
 		errorIf(errInvalidArgument().Trace(URLs...), "`--force` is deprecated please use `--overwrite` instead with `--remove` for the same functionality.")
@@ -47,24 +88,27 @@ func checkMirrorSyntax(ctx *cli.Context, encKeyDB map[string][]prefixSSEPair) {
 		errorIf(errInvalidArgument().Trace(URLs...), "`--force` is deprecated please use `--overwrite` instead for the same functionality.")
 	}
 
-	tgtClientURL := newClientURL(tgtURL)
-	if tgtClientURL.Host != "" {
-		if tgtClientURL.Path == string(tgtClientURL.Separator) {
-			fatalIf(errInvalidArgument().Trace(tgtURL),
-				fmt.Sprintf("Target `%s` does not contain bucket name.", tgtURL))
-		}
-	}
-
 	_, expandedSourcePath, _ := mustExpandAlias(srcURL)
 	srcClient := newClientURL(expandedSourcePath)
-	_, expandedTargetPath, _ := mustExpandAlias(tgtURL)
-	destClient := newClientURL(expandedTargetPath)
 
-	// Mirror with preserve option on windows
-	// only works for object storage to object storage
-	if runtime.GOOS == "windows" && ctx.Bool("a") {
-		if srcClient.Type == fileSystem || destClient.Type == fileSystem {
-			errorIf(errInvalidArgument(), "Preserve functionality on windows support object storage to object storage transfer only.")
+	for _, tgtURL := range tgtURLs {
+		tgtClientURL := newClientURL(tgtURL)
+		if tgtClientURL.Host != "" {
+			if tgtClientURL.Path == string(tgtClientURL.Separator) {
+				fatalIf(errInvalidArgument().Trace(tgtURL),
+					fmt.Sprintf("Target `%s` does not contain bucket name.", tgtURL))
+			}
+		}
+
+		_, expandedTargetPath, _ := mustExpandAlias(tgtURL)
+		destClient := newClientURL(expandedTargetPath)
+
+		// Mirror with preserve option on windows
+		// only works for object storage to object storage
+		if runtime.GOOS == "windows" && ctx.Bool("a") {
+			if srcClient.Type == fileSystem || destClient.Type == fileSystem {
+				errorIf(errInvalidArgument(), "Preserve functionality on windows support object storage to object storage transfer only.")
+			}
 		}
 	}
 
@@ -95,7 +139,179 @@ func matchExcludeOptions(excludeOptions []string, srcSuffix string) bool {
 	return false
 }
 
-func deltaSourceTarget(sourceURL, targetURL string, isFake, isOverwrite, isRemove, isMetadata bool, excludeOptions []string, URLsCh chan<- URLs, encKeyDB map[string][]prefixSSEPair) {
+// removeLimits - safeguards checked before any object is removed on target.
+type removeLimits struct {
+	maxDelete        int64   // maximum number of objects removed, 0 means no limit.
+	maxDeletePercent float64 // maximum percentage of target objects removed, 0 means no limit.
+	allowEmptySource bool    // allow removing objects on target when source is empty.
+}
+
+// parseRemoveLimits - parses `--max-delete` value of the form N or N%.
+func parseRemoveLimits(maxDelete string, allowEmptySource bool) (removeLimits, *probe.Error) {
+	limits := removeLimits{allowEmptySource: allowEmptySource}
+	if maxDelete == "" {
+		return limits, nil
+	}
+	if strings.HasSuffix(maxDelete, "%") {
+		percent, e := strconv.ParseFloat(strings.TrimSuffix(maxDelete, "%"), 64)
+		if e != nil || percent <= 0 || percent > 100 {
+			return limits, probe.NewError(errors.New("percentage should be between 0% and 100%"))
+		}
+		limits.maxDeletePercent = percent
+		return limits, nil
+	}
+	count, e := strconv.ParseInt(maxDelete, 10, 64)
+	if e != nil || count <= 0 {
+		return limits, probe.NewError(errors.New("value should be a positive number or a percentage"))
+	}
+	limits.maxDelete = count
+	return limits, nil
+}
+
+// String - returns the `--max-delete` value of the limits.
+func (l removeLimits) String() string {
+	switch {
+	case l.maxDelete > 0:
+		return strconv.FormatInt(l.maxDelete, 10)
+	case l.maxDeletePercent > 0:
+		return strconv.FormatFloat(l.maxDeletePercent, 'f', -1, 64) + "%"
+	}
+	return ""
+}
+
+// check - validates removing toRemove objects out of targetObjects
+// objects on target, given sourceObjects objects on source.
+func (l removeLimits) check(sourceObjects, targetObjects, toRemove int64) *probe.Error {
+	if toRemove == 0 {
+		return nil
+	}
+	if sourceObjects == 0 && !l.allowEmptySource {
+		return errEmptySourceRemove(toRemove)
+	}
+	if l.maxDelete > 0 && toRemove > l.maxDelete {
+		return errRemoveLimitExceeded(toRemove, l.String())
+	}
+	if l.maxDeletePercent > 0 && float64(toRemove)*100 > l.maxDeletePercent*float64(targetObjects) {
+		return errRemoveLimitExceeded(toRemove, l.String())
+	}
+	return nil
+}
+
+// removeQueue - holds back removals on target while the removal limits
+// could still be exceeded, and sends them right away once they cannot,
+// so that no removal happens before the limits are checked without
+// keeping every removal in memory when no limit applies.
+type removeQueue struct {
+	limits  removeLimits
+	send    func(URLs)
+	pending []URLs
+
+	// removals added so far and whether an object was found on source.
+	count      int64
+	sourceSeen bool
+}
+
+// isStreaming - returns true when removals can be sent right away, with
+// no limit on their number and a source known not to be empty.
+func (q *removeQueue) isStreaming() bool {
+	return q.limits.maxDelete == 0 && q.limits.maxDeletePercent == 0 &&
+		(q.sourceSeen || q.limits.allowEmptySource)
+}
+
+// add - queues a removal on target.
+func (q *removeQueue) add(removeURL URLs) {
+	q.count++
+	switch {
+	case q.isStreaming():
+		q.send(removeURL)
+	case q.limits.maxDelete > 0 && q.count > q.limits.maxDelete:
+		// The limit is already exceeded, nothing will be removed.
+		q.pending = nil
+	default:
+		q.pending = append(q.pending, removeURL)
+	}
+}
+
+// addSource - records that an object was found on source.
+func (q *removeQueue) addSource() {
+	if q.sourceSeen {
+		return
+	}
+	q.sourceSeen = true
+	if q.isStreaming() {
+		q.flush()
+	}
+}
+
+// flush - sends the removals held back.
+func (q *removeQueue) flush() {
+	for _, removeURL := range q.pending {
+		q.send(removeURL)
+	}
+	q.pending = nil
+}
+
+// finish - checks all removals against the limits once the listings are
+// complete and sends the removals held back if they are allowed.
+func (q *removeQueue) finish(sourceObjects, targetObjects int64) *probe.Error {
+	if err := q.limits.check(sourceObjects, targetObjects, q.count); err != nil {
+		return err
+	}
+	q.flush()
+	return nil
+}
+
+// removeAccount - object counts of the last listing of a mirror job and
+// the removals done since, so that removals caused by watch events are
+// checked against the same removal limits as those of the listing.
+type removeAccount struct {
+	mutex  sync.Mutex
+	limits removeLimits
+
+	// sourceObjects is a lower bound while watching, objects created
+	// on source are not counted.
+	sourceObjects, targetObjects int64
+	removed                      int64
+}
+
+// newRemoveAccount - returns an account of removals for limits.
+func newRemoveAccount(limits removeLimits) *removeAccount {
+	return &removeAccount{limits: limits}
+}
+
+// record - saves the object counts and the removals of a listing.
+func (a *removeAccount) record(sourceObjects, targetObjects, removed int64) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.sourceObjects, a.targetObjects, a.removed = sourceObjects, targetObjects, removed
+}
+
+// checkWatchRemove - checks one more removal on target, caused by the
+// removal of an object on source, and counts it if it is allowed.
+// isSourceEmpty is only called when the counts cannot tell.
+func (a *removeAccount) checkWatchRemove(isSourceEmpty func() (bool, *probe.Error)) *probe.Error {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	if a.sourceObjects > 0 {
+		a.sourceObjects--
+	}
+	if a.sourceObjects == 0 && !a.limits.allowEmptySource {
+		empty, err := isSourceEmpty()
+		if err != nil {
+			return err
+		}
+		if !empty {
+			a.sourceObjects = 1
+		}
+	}
+	if err := a.limits.check(a.sourceObjects, a.targetObjects, a.removed+1); err != nil {
+		return err
+	}
+	a.removed++
+	return nil
+}
+
+func deltaSourceTarget(sourceURL, targetURL string, isFake, isOverwrite, isRemove, isMetadata, isTransformed bool, filter urlFilter, removals *removeAccount, URLsCh chan<- URLs, encKeyDB map[string][]prefixSSEPair) {
 	// source and targets are always directories
 	sourceSeparator := string(newClientURL(sourceURL).Separator)
 	if !strings.HasSuffix(sourceURL, sourceSeparator) {
@@ -124,8 +340,21 @@ func deltaSourceTarget(sourceURL, targetURL string, isFake, isOverwrite, isRemov
 		return
 	}
 
+	// Objects to be removed on target are held back until removal
+	// limits can be checked, only removals without limits are not checked.
+	queue := removeQueue{
+		limits: removeLimits{allowEmptySource: true},
+		send:   func(removeURL URLs) { URLsCh <- removeURL },
+	}
+	if isRemove {
+		queue.limits = removals.limits
+	}
+	var sourceObjects, targetObjects int64
+
 	// List both source and target, compare and return values through channel.
-	for diffMsg := range objectDifference(sourceClnt, targetClnt, sourceURL, targetURL, isMetadata) {
+	// Similar objects are only needed to count objects for removal limits.
+	isRecursive := true
+	for diffMsg := range difference(sourceClnt, targetClnt, sourceURL, targetURL, isMetadata, isRecursive, isRemove, DirNone) {
 		if diffMsg.Error != nil {
 			// Send all errors through the channel
 			URLsCh <- URLs{Error: diffMsg.Error}
@@ -133,25 +362,37 @@ func deltaSourceTarget(sourceURL, targetURL string, isFake, isOverwrite, isRemov
 		}
 
 		srcSuffix := strings.TrimPrefix(diffMsg.FirstURL, sourceURL)
-		//Skip the source object if it matches the Exclude options provided
-		if matchExcludeOptions(excludeOptions, srcSuffix) {
+		//Skip the source object if it is excluded by the filter provided
+		if diffMsg.FirstURL != "" && filter.isExcluded(srcSuffix) {
 			continue
 		}
 
 		tgtSuffix := strings.TrimPrefix(diffMsg.SecondURL, targetURL)
-		//Skip the target object if it matches the Exclude options provided
-		if matchExcludeOptions(excludeOptions, tgtSuffix) {
+		//Skip the target object if it is excluded by the filter provided
+		if diffMsg.SecondURL != "" && filter.isExcluded(tgtSuffix) {
 			continue
 		}
 
+		if diffMsg.FirstURL != "" {
+			sourceObjects++
+			queue.addSource()
+		}
+		if diffMsg.SecondURL != "" {
+			targetObjects++
+		}
+
 		switch diffMsg.Diff {
 		case differInNone:
 			// No difference, continue.
 		case differInType:
 			URLsCh <- URLs{Error: errInvalidTarget(diffMsg.SecondURL)}
-		case differInSize, differInMetadata, differInETag:
+		case differInSize, differInMetadata:
+			if isTransformed && diffMsg.Diff == differInSize &&
+				isTransformedCopy(targetAlias, diffMsg.firstContent, diffMsg.secondContent, encKeyDB) {
+				continue
+			}
 			if !isOverwrite && !isFake {
-				// Size or time or etag differs but --overwrite not set.
+				// Size or time differs but --overwrite not set.
 				URLsCh <- URLs{Error: errOverWriteNotAllowed(diffMsg.SecondURL)}
 				continue
 			}
@@ -183,21 +424,212 @@ func deltaSourceTarget(sourceURL, targetURL string, isFake, isOverwrite, isRemov
 			if !isRemove && !isFake {
 				continue
 			}
-			URLsCh <- URLs{
+			queue.add(URLs{
 				TargetAlias:   targetAlias,
 				TargetContent: diffMsg.secondContent,
-			}
+			})
 		default:
 			URLsCh <- URLs{
 				Error: errUnrecognizedDiffType(diffMsg.Diff).Trace(diffMsg.FirstURL, diffMsg.SecondURL),
 			}
 		}
 	}
+
+	if err := queue.finish(sourceObjects, targetObjects); err != nil {
+		URLsCh <- URLs{Error: err.Trace(sourceURL, targetURL)}
+		removals.record(sourceObjects, targetObjects, 0)
+		return
+	}
+	removals.record(sourceObjects, targetObjects, queue.count)
+}
+
+// isTransformedCopy - returns true if a target compressed or decompressed
+// while it was mirrored is a copy of its source as it is now. Objects
+// are compared with the source recorded in their metadata, local files
+// cannot record it and are copies when they are newer than their source.
+func isTransformedCopy(targetAlias string, srcCtnt, tgtCtnt *clientContent, encKeyDB map[string][]prefixSSEPair) bool {
+	if tgtCtnt.URL.Type == fileSystem {
+		return !tgtCtnt.Time.Before(srcCtnt.Time)
+	}
+	clnt, err := newClientFromAlias(targetAlias, tgtCtnt.URL.String())
+	if err != nil {
+		return false
+	}
+	targetPath := filepath.ToSlash(filepath.Join(targetAlias, tgtCtnt.URL.Path))
+	st, err := clnt.Stat(false, true, false, getSSE(targetPath, encKeyDB[targetAlias]))
+	if err != nil {
+		return false
+	}
+	return isSourceRecorded(st.Metadata, srcCtnt)
+}
+
+// isSourceRecorded - returns true if metadata records the size and the
+// modification time of src.
+func isSourceRecorded(metadata map[string]string, src *clientContent) bool {
+	size, ok := metadata[mcSourceSizeKey]
+	return ok && size == strconv.FormatInt(src.Size, 10) &&
+		metadata[mcSourceMtimeKey] == src.Time.UTC().Format(time.RFC3339Nano)
 }
 
 // Prepares urls that need to be copied or removed based on requested options.
-func prepareMirrorURLs(sourceURL string, targetURL string, isFake, isOverwrite, isRemove, isMetadata bool, excludeOptions []string, encKeyDB map[string][]prefixSSEPair) <-chan URLs {
+func prepareMirrorURLs(sourceURL string, targetURL string, isFake, isOverwrite, isRemove, isMetadata, isTransformed bool, filter urlFilter, removals *removeAccount, encKeyDB map[string][]prefixSSEPair) <-chan URLs {
 	URLsCh := make(chan URLs)
-	go deltaSourceTarget(sourceURL, targetURL, isFake, isOverwrite, isRemove, isMetadata, excludeOptions, URLsCh, encKeyDB)
+	go deltaSourceTarget(sourceURL, targetURL, isFake, isOverwrite, isRemove, isMetadata, isTransformed, filter, removals, URLsCh, encKeyDB)
+	return URLsCh
+}
+
+// deltaSourceTargets - compares a source with several targets, listing
+// the source only once. Objects to copy are sent grouped, one URLs per
+// target needing the same source object. Removals and errors are sent
+// alone, errors of a target do not stop the others.
+func deltaSourceTargets(sourceURL string, targetURLs []string, isFake, isOverwrite, isRemove, isMetadata, isTransformed bool, filter urlFilter, limits removeLimits, URLsCh chan<- []URLs, encKeyDB map[string][]prefixSSEPair) {
+	// source and targets are always directories
+	sourceSeparator := string(newClientURL(sourceURL).Separator)
+	if !strings.HasSuffix(sourceURL, sourceSeparator) {
+		sourceURL = sourceURL + sourceSeparator
+	}
+
+	// Extract alias and expanded URL
+	sourceAlias, sourceURL, _ := mustExpandAlias(sourceURL)
+
+	defer close(URLsCh)
+
+	sourceClnt, err := newClientFromAlias(sourceAlias, sourceURL)
+	if err != nil {
+		URLsCh <- []URLs{{Error: err.Trace(sourceAlias, sourceURL)}}
+		return
+	}
+
+	targetAliases := make([]string, len(targetURLs))
+	expandedTargetURLs := make([]string, len(targetURLs))
+	targetClnts := make([]Client, len(targetURLs))
+	for i, targetURL := range targetURLs {
+		targetSeparator := string(newClientURL(targetURL).Separator)
+		if !strings.HasSuffix(targetURL, targetSeparator) {
+			targetURL = targetURL + targetSeparator
+		}
+		targetAliases[i], expandedTargetURLs[i], _ = mustExpandAlias(targetURL)
+		targetClnts[i], err = newClientFromAlias(targetAliases[i], expandedTargetURLs[i])
+		if err != nil {
+			URLsCh <- []URLs{{Error: err.Trace(targetAliases[i], expandedTargetURLs[i])}}
+			return
+		}
+	}
+
+	// Objects to be removed on each target are held back until removal
+	// limits can be checked. Nothing held back is removed on a target
+	// whose listing failed.
+	queues := make([]removeQueue, len(targetURLs))
+	for i := range queues {
+		queues[i].limits = removeLimits{allowEmptySource: true}
+		if isRemove {
+			queues[i].limits = limits
+		}
+		queues[i].send = func(removeURL URLs) { URLsCh <- []URLs{removeURL} }
+	}
+	targetObjects := make([]int64, len(targetURLs))
+	failed := make([]bool, len(targetURLs))
+	var sourceObjects int64
+
+	// Similar objects are only needed to count objects for removal limits.
+	for diffMsg := range multiDifference(sourceClnt, targetClnts, sourceURL, expandedTargetURLs, isMetadata, isRemove) {
+		if diffMsg.Error != nil {
+			// Source listing errors concern all targets.
+			URLsCh <- []URLs{{Error: diffMsg.Error}}
+			continue
+		}
+
+		if diffMsg.FirstURL != "" && !filter.isExcluded(strings.TrimPrefix(diffMsg.FirstURL, sourceURL)) {
+			sourceObjects++
+			for i := range queues {
+				queues[i].addSource()
+			}
+		}
+
+		var copyURLs []URLs
+		for i, targetURL := range expandedTargetURLs {
+			d, ok := diffMsg.Diffs[i]
+			if !ok {
+				continue
+			}
+			if d.Error != nil {
+				// Only listing errors come without an object.
+				if d.SecondURL == "" {
+					failed[i] = true
+				}
+				URLsCh <- []URLs{{Error: d.Error}}
+				continue
+			}
+
+			//Skip the source object if it is excluded by the filter provided
+			if d.FirstURL != "" && filter.isExcluded(strings.TrimPrefix(d.FirstURL, sourceURL)) {
+				continue
+			}
+			//Skip the target object if it is excluded by the filter provided
+			if d.SecondURL != "" && filter.isExcluded(strings.TrimPrefix(d.SecondURL, targetURL)) {
+				continue
+			}
+
+			if d.SecondURL != "" {
+				targetObjects[i]++
+			}
+
+			switch d.Diff {
+			case differInNone:
+				// No difference, continue.
+			case differInType:
+				URLsCh <- []URLs{{Error: errInvalidTarget(d.SecondURL)}}
+			case differInSize, differInMetadata, differInFirst:
+				if isTransformed && d.Diff == differInSize &&
+					isTransformedCopy(targetAliases[i], d.firstContent, d.secondContent, encKeyDB) {
+					continue
+				}
+				if d.Diff != differInFirst && !isOverwrite && !isFake {
+					// Size or time differs but --overwrite not set.
+					URLsCh <- []URLs{{Error: errOverWriteNotAllowed(d.SecondURL)}}
+					continue
+				}
+				sourceSuffix := strings.TrimPrefix(d.FirstURL, sourceURL)
+				targetPath := urlJoinPath(targetURL, sourceSuffix)
+				copyURLs = append(copyURLs, URLs{
+					SourceAlias:   sourceAlias,
+					SourceContent: d.firstContent,
+					TargetAlias:   targetAliases[i],
+					TargetContent: &clientContent{URL: *newClientURL(targetPath)},
+				})
+			case differInSecond:
+				if !isRemove && !isFake {
+					continue
+				}
+				queues[i].add(URLs{
+					TargetAlias:   targetAliases[i],
+					TargetContent: d.secondContent,
+				})
+			default:
+				URLsCh <- []URLs{{
+					Error: errUnrecognizedDiffType(d.Diff).Trace(d.FirstURL, d.SecondURL),
+				}}
+			}
+		}
+		if len(copyURLs) > 0 {
+			URLsCh <- copyURLs
+		}
+	}
+
+	for i := range targetURLs {
+		if failed[i] {
+			continue
+		}
+		if err := queues[i].finish(sourceObjects, targetObjects[i]); err != nil {
+			URLsCh <- []URLs{{Error: err.Trace(sourceURL, expandedTargetURLs[i])}}
+		}
+	}
+}
+
+// Prepares urls that need to be copied to or removed on several targets
+// based on requested options.
+func prepareMirrorMultiURLs(sourceURL string, targetURLs []string, isFake, isOverwrite, isRemove, isMetadata, isTransformed bool, filter urlFilter, limits removeLimits, encKeyDB map[string][]prefixSSEPair) <-chan []URLs {
+	URLsCh := make(chan []URLs)
+	go deltaSourceTargets(sourceURL, targetURLs, isFake, isOverwrite, isRemove, isMetadata, isTransformed, filter, limits, URLsCh, encKeyDB)
 	return URLsCh
 }
@@ -0,0 +1,185 @@
+/*
+ * MinIO Client (C) 2019 MinIO, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/minio/mc/pkg/probe"
+)
+
+func TestRemoveLimits(t *testing.T) {
+	testCases := []struct {
+		maxDelete        string
+		allowEmptySource bool
+		parseErr         bool
+		source, target   int64
+		toRemove         int64
+		checkErr         bool
+	}{
+		{"", false, false, 10, 10, 10, false},
+		{"", false, false, 0, 10, 10, true},
+		{"", true, false, 0, 10, 10, false},
+		{"", false, false, 0, 10, 0, false},
+		{"5", false, false, 10, 10, 5, false},
+		{"5", false, false, 10, 10, 6, true},
+		{"50%", false, false, 10, 10, 5, false},
+		{"50%", false, false, 10, 10, 6, true},
+		{"100%", true, false, 0, 10, 10, false},
+		{"0", false, true, 0, 0, 0, false},
+		{"-1", false, true, 0, 0, 0, false},
+		{"101%", false, true, 0, 0, 0, false},
+		{"5x", false, true, 0, 0, 0, false},
+	}
+
+	for i, testCase := range testCases {
+		limits, err := parseRemoveLimits(testCase.maxDelete, testCase.allowEmptySource)
+		if (err != nil) != testCase.parseErr {
+			t.Fatalf("Test %d: expected parse error to be %t, found %v", i+1, testCase.parseErr, err)
+		}
+		if err != nil {
+			continue
+		}
+		if limits.String() != testCase.maxDelete {
+			t.Errorf("Test %d: expected limits `%s`, found `%s`", i+1, testCase.maxDelete, limits.String())
+		}
+		err = limits.check(testCase.source, testCase.target, testCase.toRemove)
+		if (err != nil) != testCase.checkErr {
+			t.Errorf("Test %d: expected check error to be %t, found %v", i+1, testCase.checkErr, err)
+		}
+	}
+}
+
+func TestRemoveQueue(t *testing.T) {
+	testCases := []struct {
+		maxDelete        string
+		allowEmptySource bool
+		// removals added before and after the first source object.
+		before, after int
+		// removals sent before finish and in total.
+		streamed, sent int
+		finishErr      bool
+	}{
+		{"", false, 2, 3, 5, 5, false},
+		{"", false, 0, 3, 3, 3, false},
+		{"", true, 2, 3, 5, 5, false},
+		{"5", false, 2, 3, 0, 5, false},
+		{"5", false, 2, 4, 0, 0, true},
+		{"50%", true, 2, 3, 0, 5, false},
+	}
+
+	for i, testCase := range testCases {
+		limits, err := parseRemoveLimits(testCase.maxDelete, testCase.allowEmptySource)
+		if err != nil {
+			t.Fatalf("Test %d: %v", i+1, err)
+		}
+		var sent int
+		q := removeQueue{limits: limits, send: func(URLs) { sent++ }}
+		for j := 0; j < testCase.before; j++ {
+			q.add(URLs{})
+		}
+		q.addSource()
+		for j := 0; j < testCase.after; j++ {
+			q.add(URLs{})
+		}
+		if sent != testCase.streamed {
+			t.Errorf("Test %d: expected %d removals sent before finish, found %d", i+1, testCase.streamed, sent)
+		}
+		if q.limits.maxDelete > 0 && int64(len(q.pending)) > q.limits.maxDelete {
+			t.Errorf("Test %d: expected at most %d removals held back, found %d", i+1, q.limits.maxDelete, len(q.pending))
+		}
+		err = q.finish(10, 10)
+		if (err != nil) != testCase.finishErr {
+			t.Errorf("Test %d: expected finish error to be %t, found %v", i+1, testCase.finishErr, err)
+		}
+		if sent != testCase.sent {
+			t.Errorf("Test %d: expected %d removals sent, found %d", i+1, testCase.sent, sent)
+		}
+	}
+}
+
+func TestRemoveAccountWatch(t *testing.T) {
+	limits, err := parseRemoveLimits("3", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	account := newRemoveAccount(limits)
+	account.record(2, 10, 1)
+
+	var listed int
+	isSourceEmpty := func(empty bool) func() (bool, *probe.Error) {
+		return func() (bool, *probe.Error) {
+			listed++
+			return empty, nil
+		}
+	}
+
+	// One object is left on source, no listing is needed.
+	if err = account.checkWatchRemove(isSourceEmpty(false)); err != nil || listed != 0 {
+		t.Fatalf("expected removal to be allowed without listing, found %v after %d listings", err, listed)
+	}
+	// The count reaches zero, the source is listed.
+	if err = account.checkWatchRemove(isSourceEmpty(false)); err != nil || listed != 1 {
+		t.Fatalf("expected removal to be allowed after listing, found %v after %d listings", err, listed)
+	}
+	// The fourth removal exceeds --max-delete 3.
+	if err = account.checkWatchRemove(isSourceEmpty(false)); err == nil {
+		t.Fatalf("expected removal to exceed the limit")
+	}
+
+	account = newRemoveAccount(removeLimits{})
+	if err = account.checkWatchRemove(isSourceEmpty(true)); err == nil {
+		t.Fatalf("expected removal to be refused with an empty source")
+	}
+}
+
+func TestIsTransformedCopy(t *testing.T) {
+	mtime := time.Date(2019, 12, 1, 10, 0, 0, 5000, time.FixedZone("", 3600))
+	src := &clientContent{Size: 100, Time: mtime}
+
+	testCases := []struct {
+		metadata map[string]string
+		expected bool
+	}{
+		{map[string]string{mcSourceSizeKey: "100", mcSourceMtimeKey: "2019-12-01T09:00:00.000005Z"}, true},
+		{map[string]string{mcSourceSizeKey: "101", mcSourceMtimeKey: "2019-12-01T09:00:00.000005Z"}, false},
+		{map[string]string{mcSourceSizeKey: "100", mcSourceMtimeKey: "2019-12-01T09:00:01Z"}, false},
+		{map[string]string{mcSourceSizeKey: "100"}, false},
+		{nil, false},
+	}
+	for i, testCase := range testCases {
+		if isSourceRecorded(testCase.metadata, src) != testCase.expected {
+			t.Fatalf("Test %d: expected %t", i+1, testCase.expected)
+		}
+	}
+
+	// Local files are copies when they are newer than their source.
+	for _, testCase := range []struct {
+		tgtTime  time.Time
+		expected bool
+	}{
+		{mtime.Add(time.Second), true},
+		{mtime, true},
+		{mtime.Add(-time.Second), false},
+	} {
+		tgt := &clientContent{URL: *newClientURL("/tmp/logs/a.log"), Size: 40, Time: testCase.tgtTime}
+		if isTransformedCopy("", src, tgt, nil) != testCase.expected {
+			t.Fatalf("Target time %v: expected %t", testCase.tgtTime, testCase.expected)
+		}
+	}
+}
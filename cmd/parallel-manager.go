@@ -51,6 +51,55 @@ type ParallelManager struct {
 	resultCh chan URLs
 
 	stopMonitorCh chan struct{}
+
+	// Optional pool shared with other managers, bounding
+	// the number of tasks running at the same time.
+	pool *workerPool
+}
+
+// workerPool bounds the number of tasks running at the same time
+// across several parallel managers, a nil pool is unbounded.
+type workerPool struct {
+	mutex   sync.Mutex
+	cond    *sync.Cond
+	size    int
+	running int
+}
+
+// newWorkerPool creates a pool running at most size tasks at once.
+func newWorkerPool(size int) *workerPool {
+	w := &workerPool{size: size}
+	w.cond = sync.NewCond(&w.mutex)
+	return w
+}
+
+// resize changes the number of tasks running at once, tasks already
+// running above the new size are not interrupted.
+func (w *workerPool) resize(size int) {
+	w.mutex.Lock()
+	w.size = size
+	w.mutex.Unlock()
+	w.cond.Broadcast()
+}
+
+// run executes fn once a slot is available in the pool.
+func (w *workerPool) run(fn func() URLs) URLs {
+	if w == nil {
+		return fn()
+	}
+	w.mutex.Lock()
+	for w.running >= w.size {
+		w.cond.Wait()
+	}
+	w.running++
+	w.mutex.Unlock()
+	defer func() {
+		w.mutex.Lock()
+		w.running--
+		w.mutex.Unlock()
+		w.cond.Signal()
+	}()
+	return fn()
 }
 
 // addWorker creates a new worker to process tasks
@@ -66,18 +115,23 @@ func (p *ParallelManager) addWorker() {
 
 	// Start a new worker
 	p.wg.Add(1)
+	atomic.AddInt64(&globalMirrorMetrics.workers, 1)
 	go func() {
 		for {
 			// Wait for jobs
 			fn, ok := <-p.queueCh
 			if !ok {
 				// No more tasks, quit
+				atomic.AddInt64(&globalMirrorMetrics.workers, -1)
 				p.wg.Done()
 				return
 			}
 			// Execute the task and send the result
 			// to result channel.
-			p.resultCh <- fn()
+			atomic.AddInt64(&globalMirrorMetrics.busyWorkers, 1)
+			result := p.pool.run(fn)
+			atomic.AddInt64(&globalMirrorMetrics.busyWorkers, -1)
+			p.resultCh <- result
 		}
 	}()
 }
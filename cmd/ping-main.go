@@ -0,0 +1,592 @@
+/*
+ * MinIO Client (C) 2019 MinIO, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/minio/cli"
+	json "github.com/minio/mc/pkg/colorjson"
+	"github.com/minio/mc/pkg/console"
+	"github.com/minio/mc/pkg/probe"
+)
+
+const (
+	// Timeout of a single ping request.
+	pingTimeout = 10 * time.Second
+	// Clock skew above which S3 servers reject signed requests.
+	pingMaxClockSkew = 15 * time.Minute
+	// Certificates expiring sooner than this are reported as a warning.
+	pingCertExpiryWarning = 30 * 24 * time.Hour
+)
+
+var (
+	pingFlags = []cli.Flag{
+		cli.IntFlag{
+			Name:  "count, c",
+			Usage: "number of requests sent to each alias, 0 to ping until interrupted",
+			Value: 4,
+		},
+		cli.StringFlag{
+			Name:  "interval",
+			Usage: "interval between requests",
+			Value: "1s",
+		},
+	}
+)
+
+// Measure connectivity and latency of aliases.
+var pingCmd = cli.Command{
+	Name:   "ping",
+	Usage:  "measure network latency, clock skew and certificate expiry of aliases",
+	Action: mainPing,
+	Before: setGlobalsFromContext,
+	Flags:  append(pingFlags, globalFlags...),
+	CustomHelpTemplate: `NAME:
+  {{.HelpName}} - {{.Usage}}
+
+USAGE:
+  {{.HelpName}} [FLAGS] ALIAS [ALIAS...]
+
+FLAGS:
+  {{range .VisibleFlags}}{{.}}
+  {{end}}
+NOTE:
+  Each request opens a new connection to the endpoint of the alias and sends an
+  anonymous HEAD request, any HTTP response counts as a reply. The time spent resolving
+  the host name (dns), connecting (connect), in the TLS handshake (tls) and waiting for
+  the response (request) is reported separately. The clock skew is measured from the
+  'Date' header of the responses with a precision of one second. Durations in JSON
+  output are in nanoseconds.
+
+EXAMPLES:
+  1. Send 4 requests to the play server.
+     {{.Prompt}} {{.HelpName}} play
+
+  2. Send 10 requests to two aliases every 500 milliseconds.
+     {{.Prompt}} {{.HelpName}} --count 10 --interval 500ms play s3
+
+  3. Ping an alias until interrupted and print the statistics as JSON.
+     {{.Prompt}} {{.HelpName}} --count 0 --json play
+`,
+}
+
+// pingResult - timings and server information of one ping request.
+type pingResult struct {
+	DNS         time.Duration
+	Connect     time.Duration
+	TLS         time.Duration
+	Request     time.Duration
+	Total       time.Duration
+	HTTPStatus  int
+	ClockSkew   time.Duration
+	HasDate     bool
+	Certificate *x509.Certificate
+}
+
+// pingEndpoint - sends an anonymous HEAD request to endpoint over a new
+// connection and measures the time spent in each phase of the request.
+// The server certificate is returned even if it failed verification.
+func pingEndpoint(endpoint string, insecure bool) (res pingResult, e error) {
+	req, e := http.NewRequest(http.MethodHead, endpoint, nil)
+	if e != nil {
+		return res, e
+	}
+
+	// Trace hooks may be called concurrently, e.g. when dialing
+	// several addresses of a host.
+	var mu sync.Mutex
+	transport := &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout: pingTimeout,
+		}).DialContext,
+		TLSHandshakeTimeout: pingTimeout,
+		DisableKeepAlives:   true,
+		DisableCompression:  true,
+		TLSClientConfig: &tls.Config{
+			RootCAs:    globalRootCAs,
+			MinVersion: tls.VersionTLS12,
+			// The certificate is verified below instead, once it is
+			// recorded, to report its expiry when it is rejected.
+			InsecureSkipVerify: true,
+			VerifyPeerCertificate: func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+				certs := make([]*x509.Certificate, len(rawCerts))
+				for i, rawCert := range rawCerts {
+					cert, e := x509.ParseCertificate(rawCert)
+					if e != nil {
+						return e
+					}
+					certs[i] = cert
+				}
+				if len(certs) == 0 {
+					return errors.New("server presented no certificate")
+				}
+				mu.Lock()
+				res.Certificate = certs[0]
+				mu.Unlock()
+				if insecure {
+					return nil
+				}
+				opts := x509.VerifyOptions{
+					Roots:         globalRootCAs,
+					DNSName:       req.URL.Hostname(),
+					Intermediates: x509.NewCertPool(),
+				}
+				for _, cert := range certs[1:] {
+					opts.Intermediates.AddCert(cert)
+				}
+				_, e := certs[0].Verify(opts)
+				return e
+			},
+		},
+	}
+	defer transport.CloseIdleConnections()
+
+	var dnsStart, connectStart, tlsStart, wroteRequest time.Time
+	trace := &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) {
+			mu.Lock()
+			dnsStart = time.Now()
+			mu.Unlock()
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			mu.Lock()
+			res.DNS = time.Since(dnsStart)
+			mu.Unlock()
+		},
+		ConnectStart: func(network, addr string) {
+			mu.Lock()
+			connectStart = time.Now()
+			mu.Unlock()
+		},
+		ConnectDone: func(network, addr string, err error) {
+			mu.Lock()
+			if err == nil {
+				res.Connect = time.Since(connectStart)
+			}
+			mu.Unlock()
+		},
+		TLSHandshakeStart: func() {
+			mu.Lock()
+			tlsStart = time.Now()
+			mu.Unlock()
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			mu.Lock()
+			res.TLS = time.Since(tlsStart)
+			mu.Unlock()
+		},
+		WroteRequest: func(httptrace.WroteRequestInfo) {
+			mu.Lock()
+			wroteRequest = time.Now()
+			mu.Unlock()
+		},
+		GotFirstResponseByte: func() {
+			mu.Lock()
+			res.Request = time.Since(wroteRequest)
+			mu.Unlock()
+		},
+	}
+
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), trace))
+
+	client := &http.Client{
+		Transport: transport,
+		Timeout:   pingTimeout,
+		// Redirects are replies of the endpoint itself.
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	start := time.Now()
+	resp, e := client.Do(req)
+	if e != nil {
+		mu.Lock()
+		defer mu.Unlock()
+		return res, e
+	}
+	resp.Body.Close()
+	end := time.Now()
+
+	mu.Lock()
+	defer mu.Unlock()
+	res.Total = end.Sub(start)
+	res.HTTPStatus = resp.StatusCode
+	if date, e := http.ParseTime(resp.Header.Get("Date")); e == nil {
+		// The server generated the header somewhere between start and end,
+		// truncated to the second like the header.
+		local := start.Add(end.Sub(start) / 2).Truncate(time.Second)
+		res.ClockSkew = date.Sub(local)
+		res.HasDate = true
+	}
+	return res, nil
+}
+
+// pingStats - distribution of the durations of a request phase.
+type pingStats struct {
+	Min time.Duration `json:"min"`
+	Avg time.Duration `json:"avg"`
+	Max time.Duration `json:"max"`
+	P50 time.Duration `json:"p50"`
+	P90 time.Duration `json:"p90"`
+	P99 time.Duration `json:"p99"`
+}
+
+// newPingStats - computes the distribution of samples, nil without samples.
+func newPingStats(samples []time.Duration) *pingStats {
+	if len(samples) == 0 {
+		return nil
+	}
+	sorted := make([]time.Duration, len(samples))
+	copy(sorted, samples)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	var sum time.Duration
+	for _, d := range sorted {
+		sum += d
+	}
+	// Nearest-rank percentile.
+	percentile := func(p float64) time.Duration {
+		rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+		if rank < 1 {
+			rank = 1
+		}
+		return sorted[rank-1]
+	}
+	return &pingStats{
+		Min: sorted[0],
+		Avg: sum / time.Duration(len(sorted)),
+		Max: sorted[len(sorted)-1],
+		P50: percentile(50),
+		P90: percentile(90),
+		P99: percentile(99),
+	}
+}
+
+// pingDuration - formats a duration for the console.
+func pingDuration(d time.Duration) string {
+	return d.Round(time.Microsecond).String()
+}
+
+// String - formats the distribution for the console.
+func (s pingStats) String() string {
+	return fmt.Sprintf("min=%s avg=%s max=%s p50=%s p90=%s p99=%s",
+		pingDuration(s.Min), pingDuration(s.Avg), pingDuration(s.Max),
+		pingDuration(s.P50), pingDuration(s.P90), pingDuration(s.P99))
+}
+
+// pingMessage container for the result of one ping request.
+type pingMessage struct {
+	Status     string        `json:"status"`
+	Alias      string        `json:"alias"`
+	Endpoint   string        `json:"endpoint"`
+	Seq        int           `json:"seq"`
+	HTTPStatus int           `json:"httpStatus,omitempty"`
+	DNS        time.Duration `json:"dns,omitempty"`
+	Connect    time.Duration `json:"connect,omitempty"`
+	TLS        time.Duration `json:"tls,omitempty"`
+	Request    time.Duration `json:"request,omitempty"`
+	Total      time.Duration `json:"total,omitempty"`
+	ClockSkew  time.Duration `json:"clockSkew,omitempty"`
+	Error      string        `json:"error,omitempty"`
+}
+
+// Colorized message for console printing.
+func (p pingMessage) String() string {
+	prefix := console.Colorize("Alias", p.Alias+":") + fmt.Sprintf(" seq=%d ", p.Seq)
+	if p.Error != "" {
+		return prefix + console.Colorize("PingError", "error: "+p.Error)
+	}
+	fields := []string{fmt.Sprintf("status=%d", p.HTTPStatus)}
+	for _, phase := range []struct {
+		name string
+		d    time.Duration
+	}{{"dns", p.DNS}, {"connect", p.Connect}, {"tls", p.TLS}, {"request", p.Request}, {"total", p.Total}} {
+		if phase.d > 0 {
+			fields = append(fields, phase.name+"="+pingDuration(phase.d))
+		}
+	}
+	return prefix + console.Colorize("Latency", strings.Join(fields, " "))
+}
+
+// JSON'ified message for scripting.
+func (p pingMessage) JSON() string {
+	msgBytes, e := json.MarshalIndent(p, "", " ")
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+	return string(msgBytes)
+}
+
+// pingCertificate - server certificate of an alias.
+type pingCertificate struct {
+	Subject string    `json:"subject"`
+	Issuer  string    `json:"issuer"`
+	Expiry  time.Time `json:"expiry"`
+}
+
+// pingSummaryMessage container for the statistics of an alias.
+type pingSummaryMessage struct {
+	Status      string           `json:"status"`
+	Alias       string           `json:"alias"`
+	Endpoint    string           `json:"endpoint"`
+	Sent        int              `json:"sent"`
+	Failed      int              `json:"failed"`
+	DNS         *pingStats       `json:"dns,omitempty"`
+	Connect     *pingStats       `json:"connect,omitempty"`
+	TLS         *pingStats       `json:"tls,omitempty"`
+	Request     *pingStats       `json:"request,omitempty"`
+	Total       *pingStats       `json:"total,omitempty"`
+	ClockSkew   *time.Duration   `json:"clockSkew,omitempty"`
+	Certificate *pingCertificate `json:"certificate,omitempty"`
+
+	// Time the certificate expiry is compared against.
+	now time.Time
+}
+
+// Colorized message for console printing.
+func (s pingSummaryMessage) String() string {
+	lines := []string{
+		console.Colorize("Alias", fmt.Sprintf("--- %s (%s) ---", s.Alias, s.Endpoint)),
+		fmt.Sprintf("%d requests, %d replies, %d failed", s.Sent, s.Sent-s.Failed, s.Failed),
+	}
+	for _, phase := range []struct {
+		name  string
+		stats *pingStats
+	}{{"dns", s.DNS}, {"connect", s.Connect}, {"tls", s.TLS}, {"request", s.Request}, {"total", s.Total}} {
+		if phase.stats != nil {
+			lines = append(lines, fmt.Sprintf("%-8s %s", phase.name, console.Colorize("Latency", phase.stats.String())))
+		}
+	}
+	if s.ClockSkew != nil {
+		skew := *s.ClockSkew
+		line := "clock skew: " + skew.String()
+		switch {
+		case skew > 0:
+			line += " (server ahead)"
+		case skew < 0:
+			line += " (server behind)"
+		}
+		if skew > pingMaxClockSkew || skew < -pingMaxClockSkew {
+			line = console.Colorize("PingError", line+", signed requests will be rejected")
+		}
+		lines = append(lines, line)
+	}
+	if s.Certificate != nil {
+		left := s.Certificate.Expiry.Sub(s.now)
+		line := fmt.Sprintf("certificate: %s, expires %s", s.Certificate.Subject,
+			s.Certificate.Expiry.Format(printDate))
+		switch {
+		case left <= 0:
+			line = console.Colorize("PingError", line+" (expired)")
+		case left < pingCertExpiryWarning:
+			line = console.Colorize("PingWarning", fmt.Sprintf("%s (in %d days)", line, int(left.Hours()/24)))
+		default:
+			line = fmt.Sprintf("%s (in %d days)", line, int(left.Hours()/24))
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// JSON'ified message for scripting.
+func (s pingSummaryMessage) JSON() string {
+	msgBytes, e := json.MarshalIndent(s, "", " ")
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+	return string(msgBytes)
+}
+
+// pingTarget - an alias being pinged and its results so far.
+type pingTarget struct {
+	alias    string
+	endpoint string
+	insecure bool
+
+	sent, failed                      int
+	dns, connect, tls, request, total []time.Duration
+	skews                             []time.Duration
+	certificate                       *x509.Certificate
+}
+
+// ping - sends one request to the target and records its result.
+func (t *pingTarget) ping(seq int) pingMessage {
+	t.sent++
+	msg := pingMessage{
+		Status:   "success",
+		Alias:    t.alias,
+		Endpoint: t.endpoint,
+		Seq:      seq,
+	}
+	res, e := pingEndpoint(t.endpoint, t.insecure)
+	// A rejected certificate is reported as well, e.g. once expired.
+	if res.Certificate != nil {
+		t.certificate = res.Certificate
+	}
+	if e != nil {
+		t.failed++
+		msg.Status = "error"
+		msg.Error = e.Error()
+		return msg
+	}
+
+	for _, phase := range []struct {
+		d       time.Duration
+		samples *[]time.Duration
+	}{{res.DNS, &t.dns}, {res.Connect, &t.connect}, {res.TLS, &t.tls}, {res.Request, &t.request}, {res.Total, &t.total}} {
+		// Phases which did not happen, such as dns for an IP address, are not recorded.
+		if phase.d > 0 {
+			*phase.samples = append(*phase.samples, phase.d)
+		}
+	}
+	if res.HasDate {
+		t.skews = append(t.skews, res.ClockSkew)
+		msg.ClockSkew = res.ClockSkew
+	}
+	msg.HTTPStatus = res.HTTPStatus
+	msg.DNS, msg.Connect, msg.TLS = res.DNS, res.Connect, res.TLS
+	msg.Request, msg.Total = res.Request, res.Total
+	return msg
+}
+
+// summary - returns the statistics of the target.
+func (t *pingTarget) summary() pingSummaryMessage {
+	s := pingSummaryMessage{
+		Status:   "success",
+		Alias:    t.alias,
+		Endpoint: t.endpoint,
+		Sent:     t.sent,
+		Failed:   t.failed,
+		DNS:      newPingStats(t.dns),
+		Connect:  newPingStats(t.connect),
+		TLS:      newPingStats(t.tls),
+		Request:  newPingStats(t.request),
+		Total:    newPingStats(t.total),
+		now:      UTCNow(),
+	}
+	if t.sent == t.failed {
+		s.Status = "error"
+	}
+	if skew := newPingStats(t.skews); skew != nil {
+		s.ClockSkew = &skew.P50
+	}
+	if t.certificate != nil {
+		s.Certificate = &pingCertificate{
+			Subject: t.certificate.Subject.String(),
+			Issuer:  t.certificate.Issuer.String(),
+			Expiry:  t.certificate.NotAfter.UTC(),
+		}
+	}
+	return s
+}
+
+// checkPingSyntax - validates the arguments of the ping command.
+func checkPingSyntax(ctx *cli.Context) (count int, interval time.Duration) {
+	if !ctx.Args().Present() {
+		cli.ShowCommandHelpAndExit(ctx, "ping", 1) // last argument is exit code
+	}
+	if count = ctx.Int("count"); count < 0 {
+		fatalIf(errInvalidArgument().Trace(ctx.String("count")), "Number of requests cannot be negative.")
+	}
+	interval, e := time.ParseDuration(ctx.String("interval"))
+	if e != nil || interval <= 0 {
+		fatalIf(errInvalidArgument().Trace(ctx.String("interval")), "Invalid interval `"+ctx.String("interval")+"`.")
+	}
+	return count, interval
+}
+
+// newPingTarget - returns the target pinged for an alias.
+func newPingTarget(aliasedURL string) (*pingTarget, *probe.Error) {
+	alias, path := url2Alias(aliasedURL)
+	if path != "" {
+		return nil, errInvalidArgument().Trace(aliasedURL)
+	}
+	_, _, hostCfg, err := expandAlias(alias)
+	if err != nil {
+		return nil, err.Trace(aliasedURL)
+	}
+	if hostCfg == nil {
+		return nil, errInvalidAliasedURL(aliasedURL).Trace(aliasedURL)
+	}
+	endpoint, e := url.Parse(hostCfg.URL)
+	if e != nil || endpoint.Host == "" {
+		return nil, errInvalidURL(hostCfg.URL).Trace(aliasedURL)
+	}
+	return &pingTarget{
+		alias:    alias,
+		endpoint: endpoint.Scheme + "://" + endpoint.Host + "/",
+		insecure: globalInsecure,
+	}, nil
+}
+
+// mainPing is the entry point for ping command.
+func mainPing(ctx *cli.Context) error {
+	count, interval := checkPingSyntax(ctx)
+
+	console.SetColor("Alias", color.New(color.FgCyan, color.Bold))
+	console.SetColor("Latency", color.New(color.FgGreen))
+	console.SetColor("PingWarning", color.New(color.FgYellow, color.Bold))
+	console.SetColor("PingError", color.New(color.FgRed, color.Bold))
+
+	var targets []*pingTarget
+	for _, arg := range ctx.Args() {
+		target, err := newPingTarget(strings.TrimSuffix(arg, "/"))
+		fatalIf(err, "Unable to ping `"+arg+"`. Expected an alias.")
+		targets = append(targets, target)
+	}
+
+	trapCh := signalTrap(os.Interrupt, syscall.SIGTERM)
+loop:
+	for seq := 1; count == 0 || seq <= count; seq++ {
+		for _, target := range targets {
+			printMsg(target.ping(seq))
+		}
+		if seq == count {
+			break
+		}
+		select {
+		case <-trapCh:
+			break loop
+		case <-time.After(interval):
+		}
+	}
+
+	// Fail if an alias did not reply at all.
+	var unreachable bool
+	for _, target := range targets {
+		summary := target.summary()
+		printMsg(summary)
+		unreachable = unreachable || summary.Status == "error"
+	}
+	if unreachable {
+		return exitStatus(globalErrorExitStatus)
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+/*
+ * MinIO Client (C) 2019 MinIO, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"io/ioutil"
+	"log"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewPingStats(t *testing.T) {
+	if stats := newPingStats(nil); stats != nil {
+		t.Fatalf("expected no stats without samples, got %+v", stats)
+	}
+
+	var samples []time.Duration
+	for i := 100; i >= 1; i-- {
+		samples = append(samples, time.Duration(i)*time.Millisecond)
+	}
+	expected := pingStats{
+		Min: time.Millisecond,
+		Avg: 50500 * time.Microsecond,
+		Max: 100 * time.Millisecond,
+		P50: 50 * time.Millisecond,
+		P90: 90 * time.Millisecond,
+		P99: 99 * time.Millisecond,
+	}
+	if stats := newPingStats(samples); *stats != expected {
+		t.Fatalf("expected %+v, got %+v", expected, *stats)
+	}
+	if samples[0] != 100*time.Millisecond {
+		t.Fatalf("samples must not be reordered")
+	}
+
+	one := pingStats{Min: time.Second, Avg: time.Second, Max: time.Second, P50: time.Second, P90: time.Second, P99: time.Second}
+	if stats := newPingStats([]time.Duration{time.Second}); *stats != one {
+		t.Fatalf("expected %+v, got %+v", one, *stats)
+	}
+}
+
+func TestPingEndpoint(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Date", time.Now().Add(time.Hour).UTC().Format(http.TimeFormat))
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	// Silence the handshake error of the verifying client.
+	server.Config.ErrorLog = log.New(ioutil.Discard, "", 0)
+	server.StartTLS()
+	defer server.Close()
+
+	res, e := pingEndpoint(server.URL+"/", true)
+	if e != nil {
+		t.Fatal(e)
+	}
+	if res.HTTPStatus != http.StatusForbidden {
+		t.Fatalf("expected status %d, got %d", http.StatusForbidden, res.HTTPStatus)
+	}
+	if !res.HasDate || res.ClockSkew < time.Hour-2*time.Second || res.ClockSkew > time.Hour+2*time.Second {
+		t.Fatalf("expected a clock skew of one hour, got %s", res.ClockSkew)
+	}
+	if res.Connect <= 0 || res.TLS <= 0 || res.Total <= 0 {
+		t.Fatalf("expected connect, tls and total timings, got %+v", res)
+	}
+	if res.Certificate == nil || !res.Certificate.NotAfter.Equal(server.Certificate().NotAfter) {
+		t.Fatalf("expected the server certificate")
+	}
+
+	// Without --insecure the self-signed certificate is rejected, it is
+	// still returned.
+	res, e = pingEndpoint(server.URL+"/", false)
+	if e == nil {
+		t.Fatalf("expected an error verifying the certificate")
+	}
+	if res.Certificate == nil || !res.Certificate.Equal(server.Certificate()) {
+		t.Fatalf("expected the rejected server certificate")
+	}
+
+	defer func(rootCAs *x509.CertPool) { globalRootCAs = rootCAs }(globalRootCAs)
+	globalRootCAs = x509.NewCertPool()
+	globalRootCAs.AddCert(server.Certificate())
+	if _, e = pingEndpoint(server.URL+"/", false); e != nil {
+		t.Fatalf("expected the trusted certificate to be verified, got %v", e)
+	}
+}
+
+func TestPingExpiredCertificate(t *testing.T) {
+	key, e := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if e != nil {
+		t.Fatal(e)
+	}
+	expiry := time.Now().Add(-24 * time.Hour).Truncate(time.Second).UTC()
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "expired"},
+		NotBefore:             expiry.Add(-24 * time.Hour),
+		NotAfter:              expiry,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		IPAddresses:           []net.IP{net.IPv4(127, 0, 0, 1)},
+	}
+	der, e := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if e != nil {
+		t.Fatal(e)
+	}
+	cert, e := x509.ParseCertificate(der)
+	if e != nil {
+		t.Fatal(e)
+	}
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.Config.ErrorLog = log.New(ioutil.Discard, "", 0)
+	server.TLS = &tls.Config{Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}}}
+	server.StartTLS()
+	defer server.Close()
+
+	defer func(rootCAs *x509.CertPool) { globalRootCAs = rootCAs }(globalRootCAs)
+	globalRootCAs = x509.NewCertPool()
+	globalRootCAs.AddCert(cert)
+
+	target := &pingTarget{alias: "expired", endpoint: server.URL + "/"}
+	if msg := target.ping(1); msg.Error == "" {
+		t.Fatalf("expected the expired certificate to be rejected")
+	}
+	summary := target.summary()
+	if summary.Status != "error" {
+		t.Fatalf("expected an error status, got %s", summary.Status)
+	}
+	if summary.Certificate == nil || !summary.Certificate.Expiry.Equal(expiry) {
+		t.Fatalf("expected the expiry of the rejected certificate, got %+v", summary.Certificate)
+	}
+	if !strings.Contains(summary.String(), "(expired)") {
+		t.Fatalf("expected the certificate to be reported as expired, got %q", summary.String())
+	}
+}
@@ -17,7 +17,10 @@
 package cmd
 
 import (
+	"io"
 	"os"
+	"os/signal"
+	"sync"
 	"syscall"
 
 	"github.com/minio/cli"
@@ -30,13 +33,21 @@ var (
 			Name:  "encrypt",
 			Usage: "encrypt objects (using server-side encryption with server managed keys)",
 		},
+		cli.StringFlag{
+			Name:  "compress",
+			Usage: "compress the stream with 'gzip' or 'zstd' before uploading it",
+		},
+		cli.BoolFlag{
+			Name:  "stdout",
+			Usage: "write the stream to standard output as well",
+		},
 	}
 )
 
 // Display contents of a file.
 var pipeCmd = cli.Command{
 	Name:   "pipe",
-	Usage:  "stream STDIN to an object",
+	Usage:  "stream STDIN to one or more objects",
 	Action: mainPipe,
 	Before: setGlobalsFromContext,
 	Flags:  append(append(pipeFlags, ioFlags...), globalFlags...),
@@ -44,7 +55,7 @@ var pipeCmd = cli.Command{
   {{.HelpName}} - {{.Usage}}
 
 USAGE:
-  {{.HelpName}} [FLAGS] [TARGET]
+  {{.HelpName}} [FLAGS] [TARGET...]
 {{if .VisibleFlags}}
 FLAGS:
   {{range .VisibleFlags}}{{.}}
@@ -65,37 +76,128 @@ EXAMPLES:
 
   4. Stream MySQL database dump to Amazon S3 directly.
      {{.Prompt}} mysqldump -u root -p ******* accountsdb | {{.HelpName}} s3/sql-backups/backups/accountsdb-oct-9-2015.sql
+
+  5. Stream a MySQL database dump compressed with zstd to Amazon S3, the codec is recorded in the
+     Content-Encoding of the object.
+     {{.Prompt}} mysqldump -u root -p ******* accountsdb | {{.HelpName}} --compress zstd s3/sql-backups/backups/accountsdb.sql.zst
+
+  6. Stream a MySQL database dump to two sites at once, each encrypted with its own key.
+     {{.Prompt}} mysqldump -u root -p ******* accountsdb | {{.HelpName}} --encrypt-key "site1/backups=32byteslongsecretkeymustbegiven1,site2/backups=32byteslongsecretkeymustbegiven2" site1/backups/accountsdb.sql site2/backups/accountsdb.sql
+
+  7. Stream a MySQL database dump to Amazon S3 and pass it through to a local compressed copy.
+     {{.Prompt}} mysqldump -u root -p ******* accountsdb | {{.HelpName}} --stdout s3/sql-backups/accountsdb.sql | gzip > accountsdb.sql.gz
 `,
 }
 
-func pipe(targetURL string, encKeyDB map[string][]prefixSSEPair) *probe.Error {
-	if targetURL == "" {
+// pipeStdout - stdout receiving the stream along with the targets, it
+// keeps its failure to be reported once the targets are done.
+type pipeStdout struct {
+	io.Writer
+	err error
+}
+
+func (s *pipeStdout) Write(p []byte) (int, error) {
+	n, e := s.Writer.Write(p)
+	if e != nil {
+		s.err = e
+	}
+	return n, e
+}
+
+// isBrokenPipe - returns true if the error is a closed pipe (EPIPE).
+func isBrokenPipe(e error) bool {
+	pathErr, ok := e.(*os.PathError)
+	return ok && pathErr.Err == syscall.EPIPE
+}
+
+// pipeTargets - streams stdin to all targets at once, the stream is
+// written to stdout as well when it is not nil. A failing target or
+// stdout is reported and left out, the others receive the whole stream.
+// A closed stdout is not a failure.
+func pipeTargets(stdin io.Reader, targetURLs []string, stdout io.Writer, codec string, encKeyDB map[string][]prefixSSEPair) error {
+	reader := stdin
+	var metadata map[string]string
+	if codec != "" {
+		metadata = make(map[string]string)
+		compressReader := newCompressReader(stdin, codec, metadata)
+		defer compressReader.Close()
+		reader = compressReader
+	}
+
+	if len(targetURLs) == 0 {
 		// When no target is specified, pipe cat's stdin to stdout.
-		return catOut(os.Stdin, -1).Trace()
+		fatalIf(catOut(reader, -1).Trace("stdout"), "Unable to write to one or more targets.")
+		return nil
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]*probe.Error, len(targetURLs))
+	var writers []io.Writer
+	pipeWriters := make([]*io.PipeWriter, len(targetURLs))
+	for i, targetURL := range targetURLs {
+		alias, _ := url2Alias(targetURL)
+		sseKey := getSSE(targetURL, encKeyDB[alias])
+		pipeReader, pipeWriter := io.Pipe()
+		writers = append(writers, pipeWriter)
+		pipeWriters[i] = pipeWriter
+
+		wg.Add(1)
+		go func(i int, targetURL string) {
+			defer wg.Done()
+			// Stream from stdin to the object until EOF.
+			// Ignore size, since os.Stat() would not return proper size all the time
+			// for local filesystem for example /proc files.
+			_, err := putTargetStreamWithURL(targetURL, pipeReader, -1, metadata, sseKey)
+			if err != nil {
+				errs[i] = err.Trace(targetURL)
+				// Leave this target out, the others go on.
+				pipeReader.CloseWithError(err.ToGoError())
+				return
+			}
+			pipeReader.Close()
+		}(i, targetURL)
+	}
+	var pipeOut *pipeStdout
+	if stdout != nil {
+		pipeOut = &pipeStdout{Writer: stdout}
+		writers = append(writers, pipeOut)
 	}
-	alias, _ := url2Alias(targetURL)
-	sseKey := getSSE(targetURL, encKeyDB[alias])
-
-	// Stream from stdin to multiple objects until EOF.
-	// Ignore size, since os.Stat() would not return proper size all the time
-	// for local filesystem for example /proc files.
-	_, err := putTargetStreamWithURL(targetURL, os.Stdin, -1, sseKey)
-	// TODO: See if this check is necessary.
-	switch e := err.ToGoError().(type) {
-	case *os.PathError:
-		if e.Err == syscall.EPIPE {
+
+	_, e := io.Copy(newFanOutWriter(writers...), reader)
+	for _, pipeWriter := range pipeWriters {
+		// Targets still reading see the end of stdin, or its error.
+		pipeWriter.CloseWithError(e)
+	}
+	wg.Wait()
+
+	var failed bool
+	for i, err := range errs {
+		if err == nil {
+			continue
+		}
+		if isBrokenPipe(err.ToGoError()) {
 			// stdin closed by the user. Gracefully exit.
-			return nil
+			continue
 		}
+		errorIf(err, "Unable to write to `"+targetURLs[i]+"`.")
+		failed = true
+	}
+	if pipeOut != nil && pipeOut.err != nil && !isBrokenPipe(pipeOut.err) {
+		errorIf(probe.NewError(pipeOut.err).Trace("stdout"), "Unable to write to stdout.")
+		failed = true
 	}
-	return err.Trace(targetURL)
+	if failed {
+		return exitStatus(globalErrorExitStatus)
+	}
+	return nil
 }
 
 // check pipe input arguments.
 func checkPipeSyntax(ctx *cli.Context) {
-	if len(ctx.Args()) > 1 {
-		cli.ShowCommandHelpAndExit(ctx, "pipe", 1) // last argument is exit code.
+	if ctx.Bool("stdout") && !ctx.Args().Present() {
+		fatalIf(errInvalidArgument(), "--stdout requires at least one target.")
 	}
+	checkCompressSyntax(ctx)
 }
 
 // mainPipe is the main entry point for pipe command.
@@ -107,16 +209,17 @@ func mainPipe(ctx *cli.Context) error {
 	// validate pipe input arguments.
 	checkPipeSyntax(ctx)
 
-	if len(ctx.Args()) == 0 {
-		err = pipe("", nil)
-		fatalIf(err.Trace("stdout"), "Unable to write to one or more targets.")
-	} else {
-		// extract URLs.
-		URLs := ctx.Args()
-		err = pipe(URLs[0], encKeyDB)
-		fatalIf(err.Trace(URLs[0]), "Unable to write to one or more targets.")
+	var stdout io.Writer
+	if ctx.Bool("stdout") {
+		// A closed stdout is left out instead of terminating the
+		// process, the targets still receive the whole stream.
+		signal.Ignore(syscall.SIGPIPE)
+		stdout = os.Stdout
+		// In case of a user showing the stream in a terminal, avoid
+		// printing control and other bad characters.
+		if isTerminal() {
+			stdout = newPrettyStdout(os.Stdout)
+		}
 	}
-
-	// Done.
-	return nil
+	return pipeTargets(os.Stdin, ctx.Args(), stdout, ctx.String("compress"), encKeyDB)
 }
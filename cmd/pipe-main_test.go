@@ -0,0 +1,112 @@
+/*
+ * MinIO Client (C) 2019 MinIO, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package cmd
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/minio/mc/pkg/probe"
+)
+
+// brokenStdout - stdout failing with e once limit bytes were written.
+type brokenStdout struct {
+	bytes.Buffer
+	limit int
+	e     error
+}
+
+func (w *brokenStdout) Write(p []byte) (int, error) {
+	if w.Len()+len(p) > w.limit {
+		return 0, w.e
+	}
+	return w.Buffer.Write(p)
+}
+
+func TestPipeTargets(t *testing.T) {
+	dir, e := ioutil.TempDir("", "pipe-")
+	if e != nil {
+		t.Fatal(e)
+	}
+	defer os.RemoveAll(dir)
+	// Local targets have no alias to expand.
+	defer func(load func() (*configV9, *probe.Error)) { loadMcConfig = load }(loadMcConfig)
+	loadMcConfig = func() (*configV9, *probe.Error) { return newMcConfig(), nil }
+	// A target below a regular file cannot be created.
+	notDir := filepath.Join(dir, "file")
+	if e = ioutil.WriteFile(notDir, nil, 0600); e != nil {
+		t.Fatal(e)
+	}
+
+	data := strings.Repeat("pipe data\n", 100000)
+	epipe := &os.PathError{Op: "write", Path: "/dev/stdout", Err: syscall.EPIPE}
+	testCases := []struct {
+		targets  []string
+		stdout   *brokenStdout
+		failed   bool
+		complete []int
+	}{
+		// Multiple targets.
+		{[]string{"a", "b"}, nil, false, []int{0, 1}},
+		// A failing target leaves the others complete.
+		{[]string{"a", "file/b", "c"}, nil, true, []int{0, 2}},
+		// Stdout receives the stream along with the targets.
+		{[]string{"a"}, &brokenStdout{limit: len(data)}, false, []int{0}},
+		// A closed stdout is not a failure.
+		{[]string{"a", "b"}, &brokenStdout{limit: 10, e: epipe}, false, []int{0, 1}},
+		// Other stdout failures are, the targets still are complete.
+		{[]string{"a", "b"}, &brokenStdout{limit: 10, e: io.ErrShortWrite}, true, []int{0, 1}},
+	}
+
+	for i, testCase := range testCases {
+		var targetURLs []string
+		for _, target := range testCase.targets {
+			if target == "file/b" {
+				targetURLs = append(targetURLs, filepath.Join(notDir, "b"))
+				continue
+			}
+			targetURLs = append(targetURLs, filepath.Join(dir, "test"+strconv.Itoa(i), target))
+		}
+
+		var stdout io.Writer
+		if testCase.stdout != nil {
+			stdout = testCase.stdout
+		}
+		e = pipeTargets(strings.NewReader(data), targetURLs, stdout, "", nil)
+		if failed := e != nil; failed != testCase.failed {
+			t.Fatalf("Test %d: expected failure %t, got %v", i+1, testCase.failed, e)
+		}
+		for _, j := range testCase.complete {
+			got, e := ioutil.ReadFile(targetURLs[j])
+			if e != nil {
+				t.Fatalf("Test %d: %v", i+1, e)
+			}
+			if string(got) != data {
+				t.Fatalf("Test %d: expected the whole stream in `%s`, got %d bytes", i+1, targetURLs[j], len(got))
+			}
+		}
+		if testCase.stdout != nil && testCase.stdout.e == nil && testCase.stdout.String() != data {
+			t.Fatalf("Test %d: expected the whole stream on stdout, got %d bytes", i+1, testCase.stdout.Len())
+		}
+	}
+}
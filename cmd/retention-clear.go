@@ -0,0 +1,56 @@
+/*
+ * MinIO Client (C) 2019 MinIO, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import "github.com/minio/cli"
+
+var retentionClearCmd = cli.Command{
+	Name:   "clear",
+	Usage:  "clear retention of objects",
+	Action: mainRetentionClear,
+	Before: setGlobalsFromContext,
+	Flags:  append(append(retentionFlags, retentionChangeFlags...), globalFlags...),
+	CustomHelpTemplate: `NAME:
+  {{.HelpName}} - {{.Usage}}
+
+USAGE:
+  {{.HelpName}} [FLAGS] TARGET
+
+FLAGS:
+  {{range .VisibleFlags}}{{.}}
+  {{end}}
+EXAMPLES:
+  1. Clear governance retention of an object.
+     {{.Prompt}} {{.HelpName}} --bypass-governance myminio/mybucket/reports/2019.csv
+
+  2. Clear governance retention of all objects with the prefix "tmp/" newer than 7 days.
+     {{.Prompt}} {{.HelpName}} --recursive --newer-than 7d --bypass-governance myminio/mybucket/tmp/
+
+  3. Preview clearing retention of all objects with the prefix "tmp/".
+     {{.Prompt}} {{.HelpName}} --recursive --fake myminio/mybucket/tmp/
+`,
+}
+
+// mainRetentionClear is the handle for "mc retention clear" command.
+func mainRetentionClear(ctx *cli.Context) error {
+	if len(ctx.Args()) != 1 {
+		cli.ShowCommandHelpAndExit(ctx, "clear", 1) // last argument is exit code
+	}
+	setRetentionColors()
+
+	return setRetention(newRetentionOptions(ctx))
+}
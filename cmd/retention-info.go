@@ -0,0 +1,117 @@
+/*
+ * MinIO Client (C) 2019 MinIO, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/minio/cli"
+	json "github.com/minio/mc/pkg/colorjson"
+	"github.com/minio/mc/pkg/console"
+	"github.com/minio/mc/pkg/probe"
+	minio "github.com/minio/minio-go/v6"
+)
+
+var retentionInfoCmd = cli.Command{
+	Name:   "info",
+	Usage:  "show retention of objects",
+	Action: mainRetentionInfo,
+	Before: setGlobalsFromContext,
+	Flags:  append(retentionFlags, globalFlags...),
+	CustomHelpTemplate: `NAME:
+  {{.HelpName}} - {{.Usage}}
+
+USAGE:
+  {{.HelpName}} [FLAGS] TARGET
+
+FLAGS:
+  {{range .VisibleFlags}}{{.}}
+  {{end}}
+EXAMPLES:
+  1. Show retention of an object.
+     {{.Prompt}} {{.HelpName}} myminio/mybucket/reports/2019.csv
+
+  2. Show retention of all objects with the prefix "reports/".
+     {{.Prompt}} {{.HelpName}} --recursive myminio/mybucket/reports/
+
+  3. Show retention of all objects with the prefix "logs/" older than 1 year, in JSON.
+     {{.Prompt}} {{.HelpName}} --recursive --older-than 365d --json myminio/mybucket/logs/
+`,
+}
+
+// retentionInfoMessage - retention of an object.
+type retentionInfoMessage struct {
+	Status      string              `json:"status"`
+	URL         string              `json:"url"`
+	VersionID   string              `json:"versionId,omitempty"`
+	Mode        minio.RetentionMode `json:"mode,omitempty"`
+	RetainUntil *time.Time          `json:"retainUntilDate,omitempty"`
+}
+
+// Colorized message for console printing.
+func (m retentionInfoMessage) String() string {
+	if m.Mode == "" || m.RetainUntil == nil {
+		return fmt.Sprintf("[%-10s] [%-23s] %s", "-", "-", m.URL)
+	}
+	mode := console.Colorize("RetentionMode", fmt.Sprintf("%-10s", m.Mode))
+	retainUntil := console.Colorize("RetainUntil", m.RetainUntil.Local().Format(printDate))
+	if m.RetainUntil.Before(UTCNow()) {
+		retainUntil = console.Colorize("RetentionExpired", m.RetainUntil.Local().Format(printDate))
+	}
+	return fmt.Sprintf("[%s] [%s] %s", mode, retainUntil, m.URL)
+}
+
+// JSON'ified message for scripting.
+func (m retentionInfoMessage) JSON() string {
+	m.Status = "success"
+	msgBytes, e := json.MarshalIndent(m, "", " ")
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+	return string(msgBytes)
+}
+
+// mainRetentionInfo is the handle for "mc retention info" command.
+func mainRetentionInfo(ctx *cli.Context) error {
+	if len(ctx.Args()) != 1 {
+		cli.ShowCommandHelpAndExit(ctx, "info", 1) // last argument is exit code
+	}
+
+	console.SetColor("RetentionMode", color.New(color.FgCyan, color.Bold))
+	console.SetColor("RetainUntil", color.New(color.FgGreen))
+	console.SetColor("RetentionExpired", color.New(color.FgYellow))
+
+	opts := newRetentionOptions(ctx)
+	return forEachObject(opts.urlStr, opts.isRecursive, opts.olderThan, opts.newerThan,
+		"Unable to get retention of", func(clnt Client, content *clientContent) *probe.Error {
+			mode, retainUntil, err := clnt.GetObjectRetention(opts.versionID)
+			if err != nil {
+				return err
+			}
+			msg := retentionInfoMessage{
+				Status:      "success",
+				URL:         content.URL.String(),
+				VersionID:   opts.versionID,
+				RetainUntil: retainUntil,
+			}
+			if mode != nil {
+				msg.Mode = *mode
+			}
+			printMsg(msg)
+			return nil
+		})
+}
@@ -17,7 +17,6 @@
 package cmd
 
 import (
-	"encoding/json"
 	"errors"
 	"fmt"
 	"strconv"
@@ -26,23 +25,66 @@ import (
 
 	"github.com/fatih/color"
 	"github.com/minio/cli"
+	json "github.com/minio/mc/pkg/colorjson"
 	"github.com/minio/mc/pkg/console"
 	"github.com/minio/mc/pkg/probe"
 	minio "github.com/minio/minio-go/v6"
 )
 
+// Flags common across retention sub-commands.
+var retentionFlags = []cli.Flag{
+	cli.BoolFlag{
+		Name:  "recursive, r",
+		Usage: "apply to all objects with the prefix",
+	},
+	cli.StringFlag{
+		Name:  "version-id",
+		Usage: "apply to a specific version of the object",
+	},
+	cli.StringFlag{
+		Name:  "older-than",
+		Usage: "only objects older than L days, M hours and N minutes",
+	},
+	cli.StringFlag{
+		Name:  "newer-than",
+		Usage: "only objects newer than L days, M hours and N minutes",
+	},
+}
+
+// Flags of retention sub-commands which change the retention.
+var retentionChangeFlags = []cli.Flag{
+	cli.BoolFlag{
+		Name:  "bypass-governance",
+		Usage: "allow shortening or clearing governance retention",
+	},
+	cli.BoolFlag{
+		Name:  "fake",
+		Usage: "perform a fake retention change",
+	},
+}
+
 var retentionCmd = cli.Command{
-	Name:   "retention",
-	Usage:  "set object retention for objects with a given prefix",
-	Action: mainRetention,
-	Before: setGlobalsFromContext,
-	Flags:  globalFlags,
+	Name:            "retention",
+	Usage:           "set, clear or show retention of objects",
+	HideHelpCommand: true,
+	Action:          mainRetention,
+	Before:          setGlobalsFromContext,
+	Flags:           globalFlags,
+	Subcommands: []cli.Command{
+		retentionSetCmd,
+		retentionClearCmd,
+		retentionInfoCmd,
+	},
 	CustomHelpTemplate: `NAME:
   {{.HelpName}} - {{.Usage}}
 
 USAGE:
+  {{.HelpName}} COMMAND [COMMAND FLAGS | -h] [ARGUMENTS...]
   {{.HelpName}} [FLAGS] TARGET [governance | compliance] [VALIDITY]
 
+COMMANDS:
+  {{range .VisibleCommands}}{{join .Names ", "}}{{ "\t" }}{{.Usage}}
+  {{end}}
 FLAGS:
   {{range .VisibleFlags}}{{.}}
   {{end}}
@@ -52,16 +94,22 @@ VALIDITY:
 EXAMPLES:
    1. Set object retention for objects in a given prefix
      $ {{.HelpName}} myminio/mybucket/prefix compliance 30d
+
+   2. Show retention of all objects in a given prefix
+     $ {{.HelpName}} info --recursive myminio/mybucket/prefix
 `,
 }
 
 // Structured message depending on the type of console.
 type retentionCmdMessage struct {
-	Mode     minio.RetentionMode `json:"mode"`
-	Validity *string             `json:"validity"`
-	URLPath  string              `json:"urlpath"`
-	Status   string              `json:"status"`
-	Err      error               `json:"error"`
+	Mode      minio.RetentionMode `json:"mode"`
+	Validity  *string             `json:"validity"`
+	URLPath   string              `json:"urlpath"`
+	VersionID string              `json:"versionId,omitempty"`
+	Status    string              `json:"status"`
+	Err       error               `json:"error"`
+	// isFake is set when the retention is not actually changed.
+	isFake bool
 }
 
 // Colorized message for console printing.
@@ -69,6 +117,12 @@ func (m retentionCmdMessage) String() string {
 	if m.Err != nil {
 		return console.Colorize("RetentionMessageFailure", "Cannot set object retention on `"+m.URLPath+"`."+m.Err.Error())
 	}
+	if m.isFake {
+		if m.Mode == "" {
+			return console.Colorize("RetentionSuccess", "Would clear object retention on `"+m.URLPath+"`.")
+		}
+		return console.Colorize("RetentionSuccess", fmt.Sprintf("Would set object retention to `%s` for %s on `%s`.", m.Mode, *m.Validity, m.URLPath))
+	}
 	return ""
 }
 
@@ -79,22 +133,84 @@ func (m retentionCmdMessage) JSON() string {
 	return string(msgBytes)
 }
 
-// setRetention - Set Retention for all objects within a given prefix.
-func setRetention(urlStr string, mode *minio.RetentionMode, validity *uint, unit *minio.ValidityUnit) error {
-	clnt, err := newClient(urlStr)
+// retentionOptions - target, filters and the retention to set, a nil
+// mode clears the retention.
+type retentionOptions struct {
+	urlStr           string
+	versionID        string
+	isRecursive      bool
+	olderThan        string
+	newerThan        string
+	bypassGovernance bool
+	isFake           bool
+	mode             *minio.RetentionMode
+	validity         *uint
+	unit             *minio.ValidityUnit
+}
+
+// newRetentionOptions - parses the flags common across retention sub-commands.
+func newRetentionOptions(ctx *cli.Context) retentionOptions {
+	opts := retentionOptions{
+		urlStr:           ctx.Args().First(),
+		versionID:        ctx.String("version-id"),
+		isRecursive:      ctx.Bool("recursive"),
+		olderThan:        ctx.String("older-than"),
+		newerThan:        ctx.String("newer-than"),
+		bypassGovernance: ctx.Bool("bypass-governance"),
+		isFake:           ctx.Bool("fake"),
+	}
+	if opts.versionID != "" && opts.isRecursive {
+		fatalIf(probe.NewError(errors.New("invalid argument")), "--version-id cannot be used with --recursive.")
+	}
+	if opts.olderThan != "" {
+		isOlder(UTCNow(), opts.olderThan)
+	}
+	if opts.newerThan != "" {
+		isNewer(UTCNow(), opts.newerThan)
+	}
+	return opts
+}
+
+// parseRetentionArgs - parses the retention mode and validity arguments.
+func parseRetentionArgs(modeStr, validityStr string) (*minio.RetentionMode, *uint, *minio.ValidityUnit) {
+	m := minio.RetentionMode(strings.ToUpper(modeStr))
+	if !m.IsValid() {
+		fatalIf(probe.NewError(errors.New("invalid argument")), "invalid retention mode '%v'", m)
+	}
+	if validityStr == "" {
+		fatalIf(probe.NewError(errors.New("invalid argument")), "invalid validity '%v'", validityStr)
+	}
+
+	unitStr := string(validityStr[len(validityStr)-1])
+	ui64, err := strconv.ParseUint(validityStr[:len(validityStr)-1], 10, 64)
 	if err != nil {
-		fatalIf(err.Trace(), "Cannot parse the provided url.")
+		fatalIf(probe.NewError(errors.New("invalid argument")), "invalid validity '%v'", validityStr)
 	}
-	alias, _, _ := mustExpandAlias(urlStr)
+	validity := uint(ui64)
+
+	var unit minio.ValidityUnit
+	switch unitStr {
+	case "d", "D":
+		unit = minio.Days
+	case "y", "Y":
+		unit = minio.Years
+	default:
+		fatalIf(probe.NewError(errors.New("invalid argument")), "invalid validity format '%v'", validityStr)
+	}
+	return &m, &validity, &unit
+}
+
+// setRetention - Set or clear Retention for the objects of the target.
+func setRetention(opts retentionOptions) error {
 	retainUntilDate := func() (time.Time, error) {
-		if validity == nil {
-			return timeSentinel, fmt.Errorf("invalid validity '%v'", validity)
+		if opts.validity == nil {
+			return timeSentinel, fmt.Errorf("invalid validity '%v'", opts.validity)
 		}
 		t := UTCNow()
-		if *unit == minio.Years {
-			t = t.AddDate(int(*validity), 0, 0)
+		if *opts.unit == minio.Years {
+			t = t.AddDate(int(*opts.validity), 0, 0)
 		} else {
-			t = t.AddDate(0, 0, int(*validity))
+			t = t.AddDate(0, 0, int(*opts.validity))
 		}
 		timeStr := t.Format(time.RFC3339)
 
@@ -107,124 +223,97 @@ func setRetention(urlStr string, mode *minio.RetentionMode, validity *uint, unit
 		return t1, nil
 	}
 	validityStr := func() *string {
-		if validity == nil {
+		if opts.validity == nil {
 			return nil
 		}
 
 		unitStr := "d"
-		if *unit == minio.Years {
+		if *opts.unit == minio.Years {
 			unitStr = "y"
 		}
-		s := fmt.Sprint(*validity, unitStr)
+		s := fmt.Sprint(*opts.validity, unitStr)
 		return &s
 	}
 
-	var cErr error
+	var retainUntil *time.Time
+	if opts.mode != nil {
+		t, e := retainUntilDate()
+		fatalIf(probe.NewError(e), "Invalid retention date")
+		retainUntil = &t
+	}
+
+	msg := retentionCmdMessage{
+		Validity:  validityStr(),
+		VersionID: opts.versionID,
+		isFake:    opts.isFake,
+	}
+	if opts.mode != nil {
+		msg.Mode = *opts.mode
+	}
+
+	var count int
 	errorsFound := false
-	for content := range clnt.List(true, false, false, DirNone) {
-		if content.Err != nil {
-			errorIf(content.Err.Trace(clnt.GetURL().String()), "Unable to list folder.")
-			cErr = exitStatus(globalErrorExitStatus) // Set the exit status.
-			continue
-		}
-		retainUntil, err := retainUntilDate()
-		if err != nil {
-			errorIf(content.Err.Trace(clnt.GetURL().String()), "Invalid retention date")
-			continue
-		}
-		newClnt, perr := newClientFromAlias(alias, content.URL.String())
-		if perr != nil {
-			errorIf(content.Err.Trace(clnt.GetURL().String()), "Invalid URL")
-			continue
-		}
-		probeErr := newClnt.PutObjectRetention(mode, &retainUntil)
-		if probeErr != nil {
-			errorsFound = true
-			printMsg(retentionCmdMessage{
-				Mode:     *mode,
-				Validity: validityStr(),
-				Status:   "failure",
-				URLPath:  content.URL.Path,
-				Err:      probeErr.ToGoError(),
-			})
-		} else {
-			if globalJSON {
-				printMsg(retentionCmdMessage{
-					Mode:     *mode,
-					Validity: validityStr(),
-					Status:   "success",
-					URLPath:  content.URL.Path,
-				})
+	cErr := forEachObject(opts.urlStr, opts.isRecursive, opts.olderThan, opts.newerThan,
+		"Unable to set retention of", func(clnt Client, content *clientContent) *probe.Error {
+			msg.URLPath = content.URL.Path
+			msg.Status = "success"
+			msg.Err = nil
+			if !opts.isFake {
+				if err := clnt.PutObjectRetention(opts.versionID, opts.mode, retainUntil, opts.bypassGovernance); err != nil {
+					errorsFound = true
+					msg.Status = "failure"
+					msg.Err = err.ToGoError()
+					printMsg(msg)
+					return nil
+				}
+			}
+			count++
+			if globalJSON || opts.isFake {
+				printMsg(msg)
 			}
+			return nil
+		})
+	if cErr == nil && !globalJSON && !opts.isFake {
+		action := "set"
+		if opts.mode == nil {
+			action = "cleared"
 		}
-	}
-	if cErr == nil && !globalJSON {
 		if errorsFound {
-			console.Print(console.Colorize("RetentionPartialFailure", fmt.Sprintf("Errors found while setting retention on objects with prefix `%s`.\n", urlStr)))
+			console.Print(console.Colorize("RetentionPartialFailure", fmt.Sprintf("Errors found while setting retention on objects with prefix `%s`.\n", opts.urlStr)))
 		} else {
-			console.Print(console.Colorize("RetentionSuccess", fmt.Sprintf("Object retention successfully set for prefix `%s`.\n", urlStr)))
+			console.Print(console.Colorize("RetentionSuccess", fmt.Sprintf("Object retention successfully %s for %d object(s) with prefix `%s`.\n", action, count, opts.urlStr)))
 		}
 	}
+	if cErr == nil && errorsFound {
+		cErr = exitStatus(globalErrorExitStatus)
+	}
 	return cErr
 }
 
-// main for retention command.
-func mainRetention(ctx *cli.Context) error {
+// setRetentionColors - colors of the messages of retention changes.
+func setRetentionColors() {
 	console.SetColor("RetentionSuccess", color.New(color.FgGreen, color.Bold))
 	console.SetColor("RetentionPartialFailure", color.New(color.FgRed, color.Bold))
 	console.SetColor("RetentionMessageFailure", color.New(color.FgYellow))
+}
+
+// main for retention command.
+func mainRetention(ctx *cli.Context) error {
+	args := ctx.Args()
+	if len(args) != 3 {
+		// Sub-commands like "set", "clear", "info" have their own main.
+		cli.ShowAppHelpAndExit(ctx, 1)
+	}
+	setRetentionColors()
 
 	// Parse encryption keys per command.
 	_, err := getEncKeys(ctx)
 	fatalIf(err, "Unable to parse encryption keys.")
 
-	// lock specific flags.
-	clearLock := ctx.Bool("clear")
-
-	args := ctx.Args()
-
-	var urlStr string
-	var mode *minio.RetentionMode
-	var validity *uint
-	var unit *minio.ValidityUnit
-
-	switch l := len(args); l {
-	case 3:
-		urlStr = args[0]
-		if clearLock {
-			fatalIf(probe.NewError(errors.New("invalid argument")), "clear flag must be passed with target alone")
-		}
-
-		m := minio.RetentionMode(strings.ToUpper(args[1]))
-		if !m.IsValid() {
-			fatalIf(probe.NewError(errors.New("invalid argument")), "invalid retention mode '%v'", m)
-		}
-
-		mode = &m
-
-		validityStr := args[2]
-		unitStr := string(validityStr[len(validityStr)-1])
-
-		validityStr = validityStr[:len(validityStr)-1]
-		ui64, err := strconv.ParseUint(validityStr, 10, 64)
-		if err != nil {
-			fatalIf(probe.NewError(errors.New("invalid argument")), "invalid validity '%v'", args[2])
-		}
-		u := uint(ui64)
-		validity = &u
-
-		switch unitStr {
-		case "d", "D":
-			d := minio.Days
-			unit = &d
-		case "y", "Y":
-			y := minio.Years
-			unit = &y
-		default:
-			fatalIf(probe.NewError(errors.New("invalid argument")), "invalid validity format '%v'", args[2])
-		}
-	default:
-		cli.ShowCommandHelpAndExit(ctx, "retention", 1)
-	}
-	return setRetention(urlStr, mode, validity, unit)
+	// Retention set with the target followed by mode and validity
+	// applies to all objects with the prefix.
+	opts := newRetentionOptions(ctx)
+	opts.isRecursive = true
+	opts.mode, opts.validity, opts.unit = parseRetentionArgs(args[1], args[2])
+	return setRetention(opts)
 }
@@ -0,0 +1,65 @@
+/*
+ * MinIO Client (C) 2019 MinIO, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import "github.com/minio/cli"
+
+var retentionSetCmd = cli.Command{
+	Name:   "set",
+	Usage:  "set retention of objects",
+	Action: mainRetentionSet,
+	Before: setGlobalsFromContext,
+	Flags:  append(append(retentionFlags, retentionChangeFlags...), globalFlags...),
+	CustomHelpTemplate: `NAME:
+  {{.HelpName}} - {{.Usage}}
+
+USAGE:
+  {{.HelpName}} [FLAGS] TARGET [governance | compliance] VALIDITY
+
+FLAGS:
+  {{range .VisibleFlags}}{{.}}
+  {{end}}
+VALIDITY:
+  This argument must be formatted like Nd or Ny where 'd' denotes days and 'y' denotes years e.g. 10d, 3y.
+
+EXAMPLES:
+  1. Set compliance retention of 30 days on an object.
+     {{.Prompt}} {{.HelpName}} myminio/mybucket/reports/2019.csv compliance 30d
+
+  2. Set governance retention of 1 year on a specific version of an object.
+     {{.Prompt}} {{.HelpName}} --version-id "3/L4kqtJlcpXroDTDmJ+rmSpXd3dIbrHY+MTRCxf3vjVBH40Nr8X8gdRQBpUMLUo" myminio/mybucket/reports/2019.csv governance 1y
+
+  3. Shorten governance retention of all objects with the prefix "logs/" to 10 days.
+     {{.Prompt}} {{.HelpName}} --recursive --bypass-governance myminio/mybucket/logs/ governance 10d
+
+  4. Preview setting retention of objects with the prefix "logs/" older than 30 days.
+     {{.Prompt}} {{.HelpName}} --recursive --older-than 30d --fake myminio/mybucket/logs/ compliance 1y
+`,
+}
+
+// mainRetentionSet is the handle for "mc retention set" command.
+func mainRetentionSet(ctx *cli.Context) error {
+	args := ctx.Args()
+	if len(args) != 3 {
+		cli.ShowCommandHelpAndExit(ctx, "set", 1) // last argument is exit code
+	}
+	setRetentionColors()
+
+	opts := newRetentionOptions(ctx)
+	opts.mode, opts.validity, opts.unit = parseRetentionArgs(args[1], args[2])
+	return setRetention(opts)
+}
@@ -22,12 +22,15 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/fatih/color"
 	"github.com/minio/cli"
 	json "github.com/minio/mc/pkg/colorjson"
 	"github.com/minio/mc/pkg/console"
 	"github.com/minio/mc/pkg/probe"
+	minio "github.com/minio/minio-go/v6"
 )
 
 // rm specific flags.
@@ -65,6 +68,14 @@ var (
 			Name:  "newer-than",
 			Usage: "remove objects newer than L days, M hours and N minutes",
 		},
+		cli.BoolFlag{
+			Name:  "skip-locked",
+			Usage: "skip objects locked by retention or legal hold",
+		},
+		cli.BoolFlag{
+			Name:  "bypass-governance",
+			Usage: "allow removing objects under governance retention",
+		},
 	}
 )
 
@@ -117,6 +128,12 @@ EXAMPLES:
 
   10. Remove an encrypted object from Amazon S3 cloud storage.
       {{.Prompt}} {{.HelpName}} --encrypt-key "s3/sql-backups/=32byteslongsecretkeymustbegiven1" s3/sql-backups/1999/old-backup.tgz
+
+  11. Remove all objects recursively from bucket 'jazz-songs', skipping objects locked by retention or legal hold.
+      {{.Prompt}} {{.HelpName}} --recursive --force --skip-locked s3/jazz-songs/
+
+  12. Remove all objects recursively from bucket 'jazz-songs', including objects under governance retention.
+      {{.Prompt}} {{.HelpName}} --recursive --force --bypass-governance s3/jazz-songs/
 `,
 }
 
@@ -180,16 +197,179 @@ func checkRmSyntax(ctx *cli.Context, encKeyDB map[string][]prefixSSEPair) {
 	}
 }
 
-func removeSingle(url string, isIncomplete bool, isFake, isForce bool, olderThan, newerThan string, encKeyDB map[string][]prefixSSEPair) error {
+// rmLockedMessage - object skipped as it is protected by retention or legal hold.
+type rmLockedMessage struct {
+	Status string `json:"status"`
+	Key    string `json:"key"`
+}
+
+// Colorized message for console printing.
+func (r rmLockedMessage) String() string {
+	return console.Colorize("RemoveSkipped", fmt.Sprintf("Skipping locked `%s`.", r.Key))
+}
+
+// JSON'ified message for scripting.
+func (r rmLockedMessage) JSON() string {
+	r.Status = "skipped"
+	msgBytes, e := json.MarshalIndent(r, "", " ")
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+	return string(msgBytes)
+}
+
+// rmSummaryMessage - totals of a remove operation.
+type rmSummaryMessage struct {
+	Status        string `json:"status"`
+	Removed       int64  `json:"removed"`
+	SkippedLocked int64  `json:"skippedLocked"`
+	Failed        int64  `json:"failed"`
+	// isFake is set when nothing was actually removed.
+	isFake bool
+}
+
+// Colorized message for console printing.
+func (r rmSummaryMessage) String() string {
+	removed := "removed"
+	if r.isFake {
+		removed = "to be removed"
+	}
+	return console.Colorize("RemoveSummary", fmt.Sprintf("Total: %d object(s) %s, %d locked object(s) skipped, %d failed.",
+		r.Removed, removed, r.SkippedLocked, r.Failed))
+}
+
+// JSON'ified message for scripting.
+func (r rmSummaryMessage) JSON() string {
+	r.Status = "success"
+	msgBytes, e := json.MarshalIndent(r, "", " ")
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+	return string(msgBytes)
+}
+
+// rmOptions holds the options of a remove operation.
+type rmOptions struct {
+	isIncomplete bool
+	isFake       bool
+	isForce      bool
+	isBypass     bool
+	isSkipLocked bool
+	olderThan    string
+	newerThan    string
+	encKeyDB     map[string][]prefixSSEPair
+	// summary accumulates the totals across all targets.
+	summary *rmSummaryMessage
+}
+
+// isObjectLocked - returns true if the object cannot be removed because
+// of its legal hold or retention, governance retention does not lock
+// the object when it is bypassed.
+func isObjectLocked(clnt Client, isBypass bool) (bool, *probe.Error) {
+	// Legal hold and retention are requested at once.
+	var (
+		mode        *minio.RetentionMode
+		retainUntil *time.Time
+		retErr      *probe.Error
+		wg          sync.WaitGroup
+	)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		mode, retainUntil, retErr = clnt.GetObjectRetention("")
+	}()
+	legalHold, err := clnt.GetObjectLegalHold()
+	wg.Wait()
+	if err != nil {
+		if _, ok := err.ToGoError().(APINotImplemented); ok {
+			return false, nil
+		}
+		return false, err.Trace(clnt.GetURL().String())
+	}
+	if legalHold == legalHoldOn {
+		return true, nil
+	}
+	if retErr != nil {
+		return false, retErr.Trace(clnt.GetURL().String())
+	}
+	if mode == nil || retainUntil == nil || retainUntil.Before(UTCNow()) {
+		return false, nil
+	}
+	return !(*mode == minio.Governance && isBypass), nil
+}
+
+// Number of objects whose lock is checked concurrently by --skip-locked.
+const rmLockCheckWorkers = 16
+
+// rmLockCheck is a listed content along with the result of its lock check.
+type rmLockCheck struct {
+	content *clientContent
+	locked  bool
+	err     *probe.Error
+}
+
+// checkLocks - checks the locks of the contents selected by isChecked,
+// at most rmLockCheckWorkers at a time. Results are sent in listing
+// order so that folders are still removed after their contents.
+func checkLocks(contentCh <-chan *clientContent, isChecked func(*clientContent) bool, isLocked func(*clientContent) (bool, *probe.Error)) <-chan rmLockCheck {
+	checkCh := make(chan rmLockCheck)
+	pendingCh := make(chan chan rmLockCheck, rmLockCheckWorkers)
+	go func() {
+		defer close(pendingCh)
+		for content := range contentCh {
+			resultCh := make(chan rmLockCheck, 1)
+			pendingCh <- resultCh
+			if !isChecked(content) {
+				resultCh <- rmLockCheck{content: content}
+				continue
+			}
+			go func(content *clientContent) {
+				locked, err := isLocked(content)
+				resultCh <- rmLockCheck{content: content, locked: locked, err: err}
+			}(content)
+		}
+	}()
+	go func() {
+		defer close(checkCh)
+		for resultCh := range pendingCh {
+			checkCh <- <-resultCh
+		}
+	}()
+	return checkCh
+}
+
+// isRmSkipped - returns true for prefix levels and for contents
+// filtered out by --older-than and --newer-than.
+func isRmSkipped(content *clientContent, opts rmOptions) bool {
+	if content.Time.IsZero() {
+		return true
+	}
+	// Skip objects older than --older-than parameter, if specified
+	if opts.olderThan != "" && isOlder(content.Time, opts.olderThan) {
+		return true
+	}
+	// Skip objects newer than --newer-than parameter if specified
+	return opts.newerThan != "" && isNewer(content.Time, opts.newerThan)
+}
+
+// removeErrorIf - prints a remove error, objects protected by retention
+// or legal hold are reported without the generic error details.
+func removeErrorIf(pErr *probe.Error, url string) {
+	if locked, ok := pErr.ToGoError().(ObjectLocked); ok {
+		errorIf(pErr.Trace(url), "Unable to remove `"+locked.Object+"`.")
+		return
+	}
+	errorIf(pErr.Trace(url), "Failed to remove `"+url+"`.")
+}
+
+func removeSingle(url string, opts rmOptions) error {
 	isRecursive := false
-	contents, pErr := statURL(url, isIncomplete, isRecursive, encKeyDB)
+	contents, pErr := statURL(url, opts.isIncomplete, isRecursive, opts.encKeyDB)
 	if pErr != nil {
 		errorIf(pErr.Trace(url), "Failed to remove `"+url+"`.")
+		opts.summary.Failed++
 		return exitStatus(globalErrorExitStatus)
 	}
 	if len(contents) == 0 {
-		if !isForce {
+		if !opts.isForce {
 			errorIf(errDummy().Trace(url), "Failed to remove `"+url+"`. Target object is not found")
+			opts.summary.Failed++
 			return exitStatus(globalErrorExitStatus)
 		}
 		return nil
@@ -198,27 +378,43 @@ func removeSingle(url string, isIncomplete bool, isFake, isForce bool, olderThan
 	content := contents[0]
 
 	// Skip objects older than older--than parameter if specified
-	if olderThan != "" && isOlder(content.Time, olderThan) {
+	if opts.olderThan != "" && isOlder(content.Time, opts.olderThan) {
 		return nil
 	}
 
 	// Skip objects older than older--than parameter if specified
-	if newerThan != "" && isNewer(content.Time, newerThan) {
+	if opts.newerThan != "" && isNewer(content.Time, opts.newerThan) {
 		return nil
 	}
 
+	targetAlias, targetURL, _ := mustExpandAlias(url)
+	clnt, pErr := newClientFromAlias(targetAlias, targetURL)
+	if pErr != nil {
+		errorIf(pErr.Trace(url), "Invalid argument `"+url+"`.")
+		opts.summary.Failed++
+		return exitStatus(globalErrorExitStatus) // End of journey.
+	}
+
+	if opts.isSkipLocked && !opts.isIncomplete && !content.Type.IsDir() {
+		locked, pErr := isObjectLocked(clnt, opts.isBypass)
+		if pErr != nil {
+			errorIf(pErr.Trace(url), "Unable to check retention of `"+url+"`.")
+			opts.summary.Failed++
+			return exitStatus(globalErrorExitStatus)
+		}
+		if locked {
+			printMsg(rmLockedMessage{Key: url})
+			opts.summary.SkippedLocked++
+			return nil
+		}
+	}
+
 	printMsg(rmMessage{
 		Key:  url,
 		Size: content.Size,
 	})
 
-	if !isFake {
-		targetAlias, targetURL, _ := mustExpandAlias(url)
-		clnt, pErr := newClientFromAlias(targetAlias, targetURL)
-		if pErr != nil {
-			errorIf(pErr.Trace(url), "Invalid argument `"+url+"`.")
-			return exitStatus(globalErrorExitStatus) // End of journey.
-		}
+	if !opts.isFake {
 		if !strings.HasSuffix(targetURL, string(clnt.GetURL().Separator)) && content.Type.IsDir() {
 			targetURL = targetURL + string(clnt.GetURL().Separator)
 		}
@@ -227,10 +423,13 @@ func removeSingle(url string, isIncomplete bool, isFake, isForce bool, olderThan
 		contentCh <- &clientContent{URL: *newClientURL(targetURL)}
 		close(contentCh)
 		isRemoveBucket := false
-		errorCh := clnt.Remove(isIncomplete, isRemoveBucket, contentCh)
+		errorCh := clnt.Remove(opts.isIncomplete, isRemoveBucket, opts.isBypass, contentCh)
+		failed := false
 		for pErr := range errorCh {
 			if pErr != nil {
-				errorIf(pErr.Trace(url), "Failed to remove `"+url+"`.")
+				removeErrorIf(pErr, url)
+				opts.summary.Failed++
+				failed = true
 				switch pErr.ToGoError().(type) {
 				case PathInsufficientPermission:
 					// Ignore Permission error.
@@ -239,11 +438,15 @@ func removeSingle(url string, isIncomplete bool, isFake, isForce bool, olderThan
 				return exitStatus(globalErrorExitStatus)
 			}
 		}
+		if failed {
+			return nil
+		}
 	}
+	opts.summary.Removed++
 	return nil
 }
 
-func removeRecursive(url string, isIncomplete bool, isFake bool, olderThan, newerThan string, encKeyDB map[string][]prefixSSEPair) error {
+func removeRecursive(url string, opts rmOptions) error {
 	targetAlias, targetURL, _ := mustExpandAlias(url)
 	clnt, pErr := newClientFromAlias(targetAlias, targetURL)
 	if pErr != nil {
@@ -253,10 +456,34 @@ func removeRecursive(url string, isIncomplete bool, isFake bool, olderThan, newe
 	contentCh := make(chan *clientContent)
 	isRemoveBucket := false
 
-	errorCh := clnt.Remove(isIncomplete, isRemoveBucket, contentCh)
+	errorCh := clnt.Remove(opts.isIncomplete, isRemoveBucket, opts.isBypass, contentCh)
+
+	// Objects sent for removal are counted as removed once all
+	// the errors have been received, folders are not counted.
+	var sent, failed int64
+	var cErr error
+	defer func() {
+		opts.summary.Removed += sent - failed
+		opts.summary.Failed += failed
+	}()
+
+	// Locks are checked concurrently ahead of the removals.
+	isLockChecked := func(content *clientContent) bool {
+		return opts.isSkipLocked && !opts.isIncomplete && content.Err == nil &&
+			!content.Type.IsDir() && !isRmSkipped(content, opts)
+	}
+	isLocked := func(content *clientContent) (bool, *probe.Error) {
+		objectClnt, pErr := newClientFromAlias(targetAlias, content.URL.String())
+		if pErr != nil {
+			return false, pErr
+		}
+		return isObjectLocked(objectClnt, opts.isBypass)
+	}
 
 	isRecursive := true
-	for content := range clnt.List(isRecursive, isIncomplete, false, DirLast) {
+	listCh := clnt.List(isRecursive, opts.isIncomplete, false, DirLast)
+	for check := range checkLocks(listCh, isLockChecked, isLocked) {
+		content := check.content
 		if content.Err != nil {
 			errorIf(content.Err.Trace(url), "Failed to remove `"+url+"` recursively.")
 			switch content.Err.ToGoError().(type) {
@@ -269,18 +496,20 @@ func removeRecursive(url string, isIncomplete bool, isFake bool, olderThan, newe
 		}
 		urlString := content.URL.Path
 
-		if !content.Time.IsZero() {
-			// Skip objects older than --older-than parameter, if specified
-			if olderThan != "" && isOlder(content.Time, olderThan) {
-				continue
-			}
+		if isRmSkipped(content, opts) {
+			continue
+		}
 
-			// Skip objects newer than --newer-than parameter if specified
-			if newerThan != "" && isNewer(content.Time, newerThan) {
-				continue
-			}
-		} else {
-			// Skip prefix levels.
+		if check.err != nil {
+			errorIf(check.err.Trace(urlString), "Unable to check retention of `"+targetAlias+urlString+"`.")
+			// Not sent for removal, so not deducted from the removed objects.
+			opts.summary.Failed++
+			cErr = exitStatus(globalErrorExitStatus)
+			continue
+		}
+		if check.locked {
+			printMsg(rmLockedMessage{Key: targetAlias + urlString})
+			opts.summary.SkippedLocked++
 			continue
 		}
 
@@ -289,38 +518,46 @@ func removeRecursive(url string, isIncomplete bool, isFake bool, olderThan, newe
 			Size: content.Size,
 		})
 
-		if !isFake {
-			sent := false
-			for !sent {
+		if !opts.isFake {
+			if !content.Type.IsDir() {
+				sent++
+			}
+			for sending := true; sending; {
 				select {
 				case contentCh <- content:
-					sent = true
+					sending = false
 				case pErr := <-errorCh:
-					errorIf(pErr.Trace(urlString), "Failed to remove `"+urlString+"`.")
+					removeErrorIf(pErr, urlString)
+					failed++
 					switch pErr.ToGoError().(type) {
-					case PathInsufficientPermission:
-						// Ignore Permission error.
+					case PathInsufficientPermission, ObjectLocked:
+						// Ignore Permission error and locked objects.
+						cErr = exitStatus(globalErrorExitStatus)
 						continue
 					}
 					close(contentCh)
 					return exitStatus(globalErrorExitStatus)
 				}
 			}
+		} else if !content.Type.IsDir() {
+			opts.summary.Removed++
 		}
 	}
 
 	close(contentCh)
 	for pErr := range errorCh {
-		errorIf(pErr.Trace(url), "Failed to remove `"+url+"` recursively.")
+		removeErrorIf(pErr, url)
+		failed++
 		switch pErr.ToGoError().(type) {
-		case PathInsufficientPermission:
-			// Ignore Permission error.
+		case PathInsufficientPermission, ObjectLocked:
+			// Ignore Permission error and locked objects.
+			cErr = exitStatus(globalErrorExitStatus)
 			continue
 		}
 		return exitStatus(globalErrorExitStatus)
 	}
 
-	return nil
+	return cErr
 }
 
 // main for rm command.
@@ -333,25 +570,32 @@ func mainRm(ctx *cli.Context) error {
 	checkRmSyntax(ctx, encKeyDB)
 
 	// rm specific flags.
-	isIncomplete := ctx.Bool("incomplete")
 	isRecursive := ctx.Bool("recursive")
-	isFake := ctx.Bool("fake")
 	isStdin := ctx.Bool("stdin")
-	olderThan := ctx.String("older-than")
-	newerThan := ctx.String("newer-than")
-	isForce := ctx.Bool("force")
+	opts := rmOptions{
+		isIncomplete: ctx.Bool("incomplete"),
+		isFake:       ctx.Bool("fake"),
+		isForce:      ctx.Bool("force"),
+		isBypass:     ctx.Bool("bypass-governance"),
+		isSkipLocked: ctx.Bool("skip-locked"),
+		olderThan:    ctx.String("older-than"),
+		newerThan:    ctx.String("newer-than"),
+		encKeyDB:     encKeyDB,
+		summary:      &rmSummaryMessage{isFake: ctx.Bool("fake")},
+	}
 
 	// Set color.
 	console.SetColor("Remove", color.New(color.FgGreen, color.Bold))
+	console.SetColor("RemoveSkipped", color.New(color.FgYellow))
+	console.SetColor("RemoveSummary", color.New(color.FgCyan, color.Bold))
 
 	var rerr error
 	var e error
-	// Support multiple targets.
-	for _, url := range ctx.Args() {
+	remove := func(url string) {
 		if isRecursive {
-			e = removeRecursive(url, isIncomplete, isFake, olderThan, newerThan, encKeyDB)
+			e = removeRecursive(url, opts)
 		} else {
-			e = removeSingle(url, isIncomplete, isFake, isForce, olderThan, newerThan, encKeyDB)
+			e = removeSingle(url, opts)
 		}
 
 		if rerr == nil {
@@ -359,23 +603,22 @@ func mainRm(ctx *cli.Context) error {
 		}
 	}
 
-	if !isStdin {
-		return rerr
+	// Support multiple targets.
+	for _, url := range ctx.Args() {
+		remove(url)
 	}
 
-	scanner := bufio.NewScanner(os.Stdin)
-	for scanner.Scan() {
-		url := scanner.Text()
-		if isRecursive {
-			e = removeRecursive(url, isIncomplete, isFake, olderThan, newerThan, encKeyDB)
-		} else {
-			e = removeSingle(url, isIncomplete, isFake, isForce, olderThan, newerThan, encKeyDB)
-		}
-
-		if rerr == nil {
-			rerr = e
+	if isStdin {
+		scanner := bufio.NewScanner(os.Stdin)
+		for scanner.Scan() {
+			remove(scanner.Text())
 		}
 	}
 
+	// Summarize operations which may involve more than one object.
+	summary := opts.summary
+	if isRecursive || isStdin || len(ctx.Args()) > 1 || summary.SkippedLocked > 0 || summary.Failed > 0 {
+		printMsg(*summary)
+	}
 	return rerr
 }
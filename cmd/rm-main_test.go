@@ -0,0 +1,80 @@
+/*
+ * MinIO Client (C) 2019 MinIO, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/minio/mc/pkg/probe"
+)
+
+func TestCheckLocks(t *testing.T) {
+	contentCh := make(chan *clientContent)
+	go func() {
+		defer close(contentCh)
+		for i := 0; i < 100; i++ {
+			contentCh <- &clientContent{URL: *newClientURL("/bucket/" + strconv.Itoa(i))}
+		}
+	}()
+
+	var running, maxRunning int32
+	var mutex sync.Mutex
+	isChecked := func(content *clientContent) bool {
+		// Every other object is not checked.
+		i, _ := strconv.Atoi(content.URL.Path[len("/bucket/"):])
+		return i%2 == 0
+	}
+	isLocked := func(content *clientContent) (bool, *probe.Error) {
+		n := atomic.AddInt32(&running, 1)
+		mutex.Lock()
+		if n > maxRunning {
+			maxRunning = n
+		}
+		mutex.Unlock()
+		time.Sleep(time.Millisecond)
+		atomic.AddInt32(&running, -1)
+		i, _ := strconv.Atoi(content.URL.Path[len("/bucket/"):])
+		return i%4 == 0, nil
+	}
+
+	i := 0
+	for check := range checkLocks(contentCh, isChecked, isLocked) {
+		if check.content.URL.Path != "/bucket/"+strconv.Itoa(i) {
+			t.Fatalf("Expected /bucket/%d, got %s", i, check.content.URL.Path)
+		}
+		if check.err != nil {
+			t.Fatalf("Unexpected error: %v", check.err)
+		}
+		if check.locked != (i%4 == 0) {
+			t.Fatalf("Expected /bucket/%d locked to be %t", i, i%4 == 0)
+		}
+		i++
+	}
+	if i != 100 {
+		t.Fatalf("Expected 100 checks, got %d", i)
+	}
+	if maxRunning > rmLockCheckWorkers+1 {
+		t.Fatalf("Expected at most %d concurrent checks, got %d", rmLockCheckWorkers+1, maxRunning)
+	}
+	if maxRunning < 2 {
+		t.Fatalf("Expected concurrent checks, got %d", maxRunning)
+	}
+}
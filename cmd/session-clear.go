@@ -102,6 +102,7 @@ func forceClear(sid string, session *sessionV8) {
 	// Remove obsolete session files.
 	removeSessionFile(sid)
 	removeSessionDataFile(sid)
+	removeSessionSyncedFile(sid)
 	printMsg(clearSessionMessage{Status: "forced", SessionID: sid})
 }
 
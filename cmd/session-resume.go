@@ -67,6 +67,11 @@ func sessionExecute(s *sessionV8) {
 		sseServer := s.Header.CommandStringFlags["encrypt"]
 		encKeyDB, _ := parseAndValidateEncryptionKeys(sseKeys, sseServer)
 		doCopySession(s, encKeyDB)
+	case "mirror":
+		sseKeys := s.Header.CommandStringFlags["encrypt-key"]
+		sseServer := s.Header.CommandStringFlags["encrypt"]
+		encKeyDB, _ := parseAndValidateEncryptionKeys(sseKeys, sseServer)
+		doMirrorSession(s, encKeyDB)
 	}
 }
 
@@ -139,10 +144,7 @@ func resumeSession(sessionID string) {
 		fatalIf(probe.NewError(e), "Unable to change working folder to root path while resuming session.")
 	}
 	sessionExecute(s)
-	err = s.Close()
-	fatalIf(err.Trace(), "Unable to close session file properly.")
-
-	err = s.Delete()
+	err = s.closeOrDelete()
 	fatalIf(err.Trace(), "Unable to clear session files properly.")
 
 	// change folder back to saved path.
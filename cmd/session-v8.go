@@ -35,22 +35,24 @@ import (
 
 // sessionV8Header for resumable sessions.
 type sessionV8Header struct {
-	Version            string            `json:"version"`
-	When               time.Time         `json:"time"`
-	RootPath           string            `json:"workingFolder"`
-	GlobalBoolFlags    map[string]bool   `json:"globalBoolFlags"`
-	GlobalIntFlags     map[string]int    `json:"globalIntFlags"`
-	GlobalStringFlags  map[string]string `json:"globalStringFlags"`
-	CommandType        string            `json:"commandType"`
-	CommandArgs        []string          `json:"cmdArgs"`
-	CommandBoolFlags   map[string]bool   `json:"cmdBoolFlags"`
-	CommandIntFlags    map[string]int    `json:"cmdIntFlags"`
-	CommandStringFlags map[string]string `json:"cmdStringFlags"`
-	LastCopied         string            `json:"lastCopied"`
-	LastRemoved        string            `json:"lastRemoved"`
-	TotalBytes         int64             `json:"totalBytes"`
-	TotalObjects       int64             `json:"totalObjects"`
-	UserMetaData       map[string]string `json:"metaData"`
+	Version                 string              `json:"version"`
+	When                    time.Time           `json:"time"`
+	RootPath                string              `json:"workingFolder"`
+	GlobalBoolFlags         map[string]bool     `json:"globalBoolFlags"`
+	GlobalIntFlags          map[string]int      `json:"globalIntFlags"`
+	GlobalStringFlags       map[string]string   `json:"globalStringFlags"`
+	CommandType             string              `json:"commandType"`
+	CommandArgs             []string            `json:"cmdArgs"`
+	CommandBoolFlags        map[string]bool     `json:"cmdBoolFlags"`
+	CommandIntFlags         map[string]int      `json:"cmdIntFlags"`
+	CommandStringFlags      map[string]string   `json:"cmdStringFlags"`
+	CommandStringSliceFlags map[string][]string `json:"cmdStringSliceFlags,omitempty"`
+	LastCopied              string              `json:"lastCopied"`
+	LastRemoved             string              `json:"lastRemoved"`
+	FailedCopies            map[string][]string `json:"failedCopies,omitempty"`
+	TotalBytes              int64               `json:"totalBytes"`
+	TotalObjects            int64               `json:"totalObjects"`
+	UserMetaData            map[string]string   `json:"metaData"`
 }
 
 // sessionMessage container for session messages
@@ -174,6 +176,7 @@ func newSessionV8(sessionID string) *sessionV8 {
 	s.Header.CommandBoolFlags = make(map[string]bool)
 	s.Header.CommandIntFlags = make(map[string]int)
 	s.Header.CommandStringFlags = make(map[string]string)
+	s.Header.CommandStringSliceFlags = make(map[string][]string)
 	s.Header.UserMetaData = make(map[string]string)
 	s.Header.When = UTCNow()
 	s.mutex = new(sync.Mutex)
@@ -243,6 +246,85 @@ func (s *sessionV8) Save() *probe.Error {
 	return nil
 }
 
+// addFailedCopy - records a target which failed to receive its source
+// while other targets did, so that resuming copies it again.
+func (s *sessionV8) addFailedCopy(cpURLs URLs) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.Header.FailedCopies == nil {
+		s.Header.FailedCopies = make(map[string][]string)
+	}
+	source := cpURLs.SourceContent.URL.String()
+	target := cpURLs.TargetContent.URL.String()
+	for _, failed := range s.Header.FailedCopies[source] {
+		if failed == target {
+			return
+		}
+	}
+	s.Header.FailedCopies[source] = append(s.Header.FailedCopies[source], target)
+}
+
+// isFailedCopy - returns true if the copy was recorded as failed.
+func (s *sessionV8) isFailedCopy(cpURLs URLs) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	target := cpURLs.TargetContent.URL.String()
+	for _, failed := range s.Header.FailedCopies[cpURLs.SourceContent.URL.String()] {
+		if failed == target {
+			return true
+		}
+	}
+	return false
+}
+
+// removeFailedCopy - forgets a failed copy which succeeded, returns
+// false if the copy was not recorded as failed.
+func (s *sessionV8) removeFailedCopy(cpURLs URLs) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	source := cpURLs.SourceContent.URL.String()
+	target := cpURLs.TargetContent.URL.String()
+	targets := s.Header.FailedCopies[source]
+	for i, failed := range targets {
+		if failed != target {
+			continue
+		}
+		targets = append(targets[:i], targets[i+1:]...)
+		if len(targets) == 0 {
+			delete(s.Header.FailedCopies, source)
+		} else {
+			s.Header.FailedCopies[source] = targets
+		}
+		return true
+	}
+	return false
+}
+
+// hasFailedCopies - returns true if some targets failed to receive
+// their source.
+func (s *sessionV8) hasFailedCopies() bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return len(s.Header.FailedCopies) > 0
+}
+
+// closeOrDelete - removes the session once it completed, it is kept
+// when copies to some targets failed so that resuming copies them again.
+func (s *sessionV8) closeOrDelete() *probe.Error {
+	if !s.hasFailedCopies() {
+		return s.Delete()
+	}
+	if err := s.Close(); err != nil {
+		return err.Trace(s.SessionID)
+	}
+	console.Infoln("Copies to some targets failed. To copy them again `mc session resume " + s.SessionID + "`")
+	return nil
+}
+
 // setGlobals captures the state of global variables into session header.
 // Used by newSession.
 func (s *sessionV8) setGlobals() {
@@ -366,6 +448,9 @@ func (s *sessionV8) Delete() *probe.Error {
 	// Remove session backup file if any, ignore any error.
 	os.Remove(sessionFile + ".old")
 
+	// Remove list of synced entries if any, ignore any error.
+	removeSessionSyncedFile(s.SessionID)
+
 	return nil
 }
 
@@ -107,6 +107,18 @@ func getSessionDataFile(sid string) (string, *probe.Error) {
 	return sessionDataFile, nil
 }
 
+// getSessionSyncedFile - get the file listing the entries already
+// synced by a given mirror session.
+func getSessionSyncedFile(sid string) (string, *probe.Error) {
+	sessionDir, err := getSessionDir()
+	if err != nil {
+		return "", err.Trace()
+	}
+
+	sessionSyncedFile := filepath.Join(sessionDir, sid+".synced")
+	return sessionSyncedFile, nil
+}
+
 // getSessionIDs - get all active sessions.
 func getSessionIDs() (sids []string) {
 	sessionDir, err := getSessionDir()
@@ -130,6 +142,15 @@ func removeSessionFile(sid string) {
 	os.Remove(sessionFile)
 }
 
+// removeSessionSyncedFile - remove the session synced file, ending with .synced
+func removeSessionSyncedFile(sid string) {
+	syncedFile, err := getSessionSyncedFile(sid)
+	if err != nil {
+		return
+	}
+	os.Remove(syncedFile)
+}
+
 // removeSessionDataFile - remove the session data file, ending with .data
 func removeSessionDataFile(sid string) {
 	dataFile, err := getSessionDataFile(sid)
@@ -58,3 +58,60 @@ func (s *TestSuite) TestSession(c *C) {
 	_, e = os.Stat(session.DataFP.Name())
 	c.Assert(e, NotNil)
 }
+
+func (s *TestSuite) TestSessionSyncedList(c *C) {
+	err := createSessionDir()
+	c.Assert(err, IsNil)
+
+	session := newSessionV8(getHash("mirror", []string{"mybucket", "myminio/mybucket"}))
+	removeSessionSyncedFile(session.SessionID)
+	synced, err := loadSessionSyncedList(session.SessionID)
+	c.Assert(err, IsNil)
+
+	copied := URLs{TargetAlias: "myminio", TargetContent: &clientContent{URL: *newClientURL("/mybucket/object1")}}
+	removed := URLs{TargetAlias: "myminio", TargetContent: &clientContent{URL: *newClientURL("/mybucket/object2")}}
+	c.Assert(synced.isSynced(copied), Equals, false)
+	c.Assert(synced.add(copied), IsNil)
+	c.Assert(synced.isSynced(copied), Equals, true)
+	c.Assert(synced.Close(), IsNil)
+
+	synced, err = loadSessionSyncedList(session.SessionID)
+	c.Assert(err, IsNil)
+	c.Assert(synced.isSynced(copied), Equals, true)
+	c.Assert(synced.isSynced(removed), Equals, false)
+	c.Assert(synced.Close(), IsNil)
+
+	c.Assert(session.Close(), IsNil)
+	c.Assert(session.Delete(), IsNil)
+	syncedFile, err := getSessionSyncedFile(session.SessionID)
+	c.Assert(err, IsNil)
+	_, e := os.Stat(syncedFile)
+	c.Assert(os.IsNotExist(e), Equals, true)
+}
+
+func (s *TestSuite) TestSessionFailedCopies(c *C) {
+	err := createSessionDir()
+	c.Assert(err, IsNil)
+
+	session := newSessionV8(getHash("cp", []string{"mybucket", "myminio/mybucket", "--target", "other/mybucket"}))
+	source := &clientContent{URL: *newClientURL("/mybucket/object1")}
+	primary := URLs{SourceContent: source, TargetContent: &clientContent{URL: *newClientURL("/mybucket/object1")}}
+	extra := URLs{SourceContent: source, TargetContent: &clientContent{URL: *newClientURL("/other/object1")}}
+
+	session.addFailedCopy(extra)
+	session.addFailedCopy(extra)
+	c.Assert(session.isFailedCopy(extra), Equals, true)
+	c.Assert(session.isFailedCopy(primary), Equals, false)
+	c.Assert(session.Close(), IsNil)
+
+	savedSession, err := loadSessionV8(session.SessionID)
+	c.Assert(err, IsNil)
+	c.Assert(savedSession.Header.FailedCopies["/mybucket/object1"], DeepEquals, []string{"/other/object1"})
+	c.Assert(savedSession.removeFailedCopy(primary), Equals, false)
+	c.Assert(savedSession.removeFailedCopy(extra), Equals, true)
+	c.Assert(savedSession.isFailedCopy(extra), Equals, false)
+	c.Assert(len(savedSession.Header.FailedCopies), Equals, 0)
+
+	c.Assert(savedSession.Close(), IsNil)
+	c.Assert(savedSession.Delete(), IsNil)
+}
@@ -0,0 +1,262 @@
+/*
+ * MinIO Client (C) 2019 MinIO, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// sqlAggregateColumn - an aggregated column of a query, merged across
+// the results of all queried objects.
+type sqlAggregateColumn struct {
+	name string
+	fn   string
+	// results across objects.
+	value   string
+	number  float64
+	count   float64
+	isFloat bool
+	isSet   bool
+}
+
+// sqlAggregate - merges aggregate results of the same query on many
+// objects into a single result.
+type sqlAggregate struct {
+	columns []*sqlAggregateColumn
+}
+
+var sqlAliasRegexp = regexp.MustCompile(`(?i)^(?:as\s+)?("?)([a-z_][a-z0-9_]*)"?$`)
+
+// splitSQLTopLevel - splits s at sep outside of parentheses and quotes.
+func splitSQLTopLevel(s string, sep byte) []string {
+	var parts []string
+	var depth int
+	var quote byte
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case c == sep && depth == 0:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// closingSQLParen - index of the parenthesis closing the one s starts
+// with, -1 if not found.
+func closingSQLParen(s string) int {
+	var depth int
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// indexSQLKeyword - index of a keyword outside of parentheses and
+// quotes, surrounded by spaces, -1 if not found.
+func indexSQLKeyword(s, keyword string) int {
+	var depth int
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case depth == 0 && i > 0 && unicode.IsSpace(rune(s[i-1])) && len(s) > i+len(keyword) &&
+			strings.EqualFold(s[i:i+len(keyword)], keyword) && unicode.IsSpace(rune(s[i+len(keyword)])):
+			return i
+		}
+	}
+	return -1
+}
+
+// newSQLAggregate - parses the projection of an aggregate query and
+// returns the query to run on each object, averages are queried as
+// a sum and a count to be merged.
+func newSQLAggregate(query string) (*sqlAggregate, string, error) {
+	query = strings.TrimSpace(query)
+	if len(query) < len("select ") || !strings.EqualFold(query[:len("select")], "select") ||
+		!unicode.IsSpace(rune(query[len("select")])) {
+		return nil, "", errors.New("query must start with SELECT")
+	}
+	from := indexSQLKeyword(query, "from")
+	if from < 0 {
+		return nil, "", errors.New("query must have a FROM clause")
+	}
+
+	aggregate := &sqlAggregate{}
+	var projection []string
+	for i, item := range splitSQLTopLevel(query[len("select"):from], ',') {
+		item = strings.TrimSpace(item)
+		open := strings.IndexByte(item, '(')
+		if open < 0 {
+			return nil, "", fmt.Errorf("column `%s` is not an aggregate function", item)
+		}
+		fn := strings.ToLower(strings.TrimSpace(item[:open]))
+		switch fn {
+		case "count", "sum", "min", "max", "avg":
+		default:
+			return nil, "", fmt.Errorf("column `%s` is not one of COUNT, SUM, MIN, MAX or AVG", item)
+		}
+		args := splitSQLTopLevel(item[open:], ' ')
+		arg := args[0]
+		if closingSQLParen(arg) != len(arg)-1 {
+			return nil, "", fmt.Errorf("column `%s` is not a single aggregate function", item)
+		}
+		column := &sqlAggregateColumn{name: fmt.Sprintf("_%d", i+1), fn: fn}
+		if alias := strings.TrimSpace(strings.Join(args[1:], " ")); alias != "" {
+			m := sqlAliasRegexp.FindStringSubmatch(alias)
+			if m == nil {
+				return nil, "", fmt.Errorf("column `%s` is not a single aggregate function", item)
+			}
+			column.name = m[2]
+		}
+		aggregate.columns = append(aggregate.columns, column)
+
+		if fn == "avg" {
+			projection = append(projection, "sum"+arg, "count"+arg)
+			continue
+		}
+		projection = append(projection, strings.TrimSpace(item[:open])+arg)
+	}
+	return aggregate, "select " + strings.Join(projection, ", ") + " " + query[from:], nil
+}
+
+// header - names of the aggregated columns.
+func (a *sqlAggregate) header() []string {
+	names := make([]string, len(a.columns))
+	for i, column := range a.columns {
+		names[i] = column.name
+	}
+	return names
+}
+
+// add - merges the result of the query on an object.
+func (a *sqlAggregate) add(record []string) error {
+	var i int
+	next := func() (string, error) {
+		if i >= len(record) {
+			return "", errors.New("unexpected number of aggregated columns")
+		}
+		i++
+		return record[i-1], nil
+	}
+	for _, column := range a.columns {
+		value, e := next()
+		if e != nil {
+			return e
+		}
+		var count string
+		if column.fn == "avg" {
+			if count, e = next(); e != nil {
+				return e
+			}
+		}
+		// Aggregates of objects without any matching records are null.
+		if value == "" {
+			continue
+		}
+		number, nErr := strconv.ParseFloat(value, 64)
+		switch column.fn {
+		case "min", "max":
+			isLess := value < column.value
+			if cNumber, cErr := strconv.ParseFloat(column.value, 64); nErr == nil && cErr == nil {
+				isLess = number < cNumber
+			}
+			if !column.isSet || (column.fn == "min") == isLess {
+				column.value = value
+			}
+		default:
+			if nErr != nil {
+				return fmt.Errorf("unexpected %s value `%s`", column.fn, value)
+			}
+			column.number += number
+			column.isFloat = column.isFloat || strings.ContainsAny(value, ".eE")
+			if column.fn == "avg" {
+				n, e := strconv.ParseFloat(count, 64)
+				if e != nil {
+					return fmt.Errorf("unexpected count value `%s`", count)
+				}
+				column.count += n
+			}
+		}
+		column.isSet = true
+	}
+	if i != len(record) {
+		return errors.New("unexpected number of aggregated columns")
+	}
+	return nil
+}
+
+// result - merged values of the aggregated columns.
+func (a *sqlAggregate) result() []string {
+	values := make([]string, len(a.columns))
+	for i, column := range a.columns {
+		switch {
+		case column.fn == "count" && !column.isSet:
+			values[i] = "0"
+		case !column.isSet:
+		case column.fn == "min" || column.fn == "max":
+			values[i] = column.value
+		case column.fn == "avg":
+			values[i] = strconv.FormatFloat(column.number/column.count, 'f', -1, 64)
+		case column.isFloat:
+			values[i] = strconv.FormatFloat(column.number, 'f', -1, 64)
+		default:
+			values[i] = strconv.FormatFloat(column.number, 'f', 0, 64)
+		}
+	}
+	return values
+}
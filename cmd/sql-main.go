@@ -20,6 +20,8 @@ import (
 	"bufio"
 	"compress/bzip2"
 	"compress/gzip"
+	"encoding/csv"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -27,10 +29,14 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
 
+	"github.com/fatih/color"
 	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/console"
 	"github.com/minio/mc/pkg/probe"
+	minio "github.com/minio/minio-go/v6"
 	"github.com/minio/minio/pkg/mimedb"
 )
 
@@ -55,7 +61,7 @@ var (
 		},
 		cli.StringFlag{
 			Name:  "compression",
-			Usage: "input compression type",
+			Usage: "input compression type 'NONE', 'GZIP' or 'BZIP2'",
 		},
 		cli.StringFlag{
 			Name:  "csv-output",
@@ -69,6 +75,14 @@ var (
 			Name:  "json-output",
 			Usage: "json output serialization option",
 		},
+		cli.StringFlag{
+			Name:  "format",
+			Usage: "output format 'csv' with a header, 'json' or 'table', csv and table are printed once all the results are received",
+		},
+		cli.BoolFlag{
+			Name:  "aggregate",
+			Usage: "merge aggregate results of all objects into a single result",
+		},
 	}
 )
 
@@ -120,6 +134,18 @@ EXAMPLES:
      {{.Prompt}} {{.HelpName}} --compression GZIP --csv-input "rd=\n,fh=USE,fd=;" \
            --csv-output "rd=\n" --csv-output-header "device_id,uptime,lat,lon" \
            --query "select * from S3Object" myminio/iot-devices/data.csv
+
+  7. Run a query on a local file, queries on local files are evaluated by mc itself.
+     {{.Prompt}} {{.HelpName}} --query "select s.device_id from S3Object s where cast(s.power as float) > 0.5 limit 10" \
+           ./iot-devices/power-ratio.csv.gz
+
+  8. Run a query on a set of objects recursively and display the results in a table.
+     {{.Prompt}} {{.HelpName}} --recursive --format table --query "select s.device_id, s.power from S3Object s limit 5" \
+           myminio/iot-devices/
+
+  9. Count the records of all objects with a given prefix, instead of one count per object.
+     {{.Prompt}} {{.HelpName}} --recursive --aggregate --query "select count(*), avg(cast(s.power as float)) from S3Object s" \
+           myminio/iot-devices/2019/
 `,
 }
 
@@ -362,9 +388,17 @@ func getSQLOpts(ctx *cli.Context, csvHdrs []string) (s SelectObjectOpts) {
 	is := getInputSerializationOpts(ctx)
 	os := getOutputSerializationOpts(ctx, csvHdrs)
 
+	compressionType := minio.SelectCompressionType(strings.ToUpper(ctx.String("compression")))
+	switch compressionType {
+	case "", minio.SelectCompressionNONE, minio.SelectCompressionGZIP, minio.SelectCompressionBZIP:
+	default:
+		fatalIf(errInvalidArgument().Trace(ctx.String("compression")), "Invalid compression type, valid types are NONE, GZIP and BZIP2")
+	}
+
 	return SelectObjectOpts{
-		InputSerOpts:  is,
-		OutputSerOpts: os,
+		InputSerOpts:    is,
+		OutputSerOpts:   os,
+		CompressionType: compressionType,
 	}
 }
 
@@ -378,7 +412,233 @@ func isCSVOrJSON(inOpts map[string]map[string]string) bool {
 	return false
 }
 
-func sqlSelect(targetURL, expression string, encKeyDB map[string][]prefixSSEPair, selOpts SelectObjectOpts, csvHdrs []string, writeHdr bool) *probe.Error {
+// sqlOutput - output of query results, results are written as they
+// are received unless they are decoded to be printed with a header,
+// in a table or merged across objects.
+type sqlOutput struct {
+	format    string
+	aggregate *sqlAggregate
+	// query run on each object.
+	query string
+
+	// Decoded records, the header is the union of their fields in
+	// the order they are first seen.
+	header []string
+	rows   [][]string
+}
+
+// newSQLOutput - validates the output flags.
+func newSQLOutput(ctx *cli.Context) *sqlOutput {
+	output := &sqlOutput{
+		format: strings.ToLower(ctx.String("format")),
+		query:  ctx.String("query"),
+	}
+	switch output.format {
+	case "", "csv", "json", "table":
+	default:
+		fatalIf(errInvalidArgument().Trace(ctx.String("format")), "Invalid output format, valid formats are csv, json and table")
+	}
+	if output.format == "" && globalJSON {
+		output.format = "json"
+	}
+	if ctx.IsSet("format") {
+		for _, flag := range []string{"csv-output", "json-output", "csv-output-header"} {
+			if ctx.IsSet(flag) {
+				fatalIf(errInvalidArgument(), "--format cannot be used with --"+flag)
+			}
+		}
+	}
+	if ctx.Bool("aggregate") {
+		if ctx.IsSet("csv-output-header") {
+			fatalIf(errInvalidArgument(), "--aggregate cannot be used with --csv-output-header")
+		}
+		var e error
+		output.aggregate, output.query, e = newSQLAggregate(output.query)
+		fatalIf(probe.NewError(e), "Unable to run an aggregate query")
+	}
+	return output
+}
+
+// isDecoded - returns true if the results are decoded instead of
+// being written as they are received.
+func (o *sqlOutput) isDecoded() bool {
+	return o.aggregate != nil || o.format == "csv" || o.format == "table"
+}
+
+// selectOpts - serialization of the results to decode or to write in
+// json, records are decoded from JSON to know the names of their fields.
+func (o *sqlOutput) selectOpts(selOpts SelectObjectOpts) SelectObjectOpts {
+	switch {
+	case o.aggregate != nil:
+		selOpts.OutputSerOpts = map[string]map[string]string{"csv": {}}
+	case o.isDecoded(), o.format == "json":
+		selOpts.OutputSerOpts = map[string]map[string]string{"json": {}}
+	}
+	return selOpts
+}
+
+// decode - decodes the results of the query on an object.
+func (o *sqlOutput) decode(r io.Reader) error {
+	if o.aggregate != nil {
+		records, e := csv.NewReader(r).ReadAll()
+		if e != nil {
+			return e
+		}
+		for _, record := range records {
+			if e = o.aggregate.add(record); e != nil {
+				return e
+			}
+		}
+		return nil
+	}
+
+	decoder := json.NewDecoder(r)
+	decoder.UseNumber()
+	for {
+		keys, values, e := decodeSQLRecord(decoder)
+		if e == io.EOF {
+			return nil
+		}
+		if e != nil {
+			return e
+		}
+		o.addRecord(keys, values)
+	}
+}
+
+// decodeSQLRecord - decodes the fields of a JSON record in their order.
+func decodeSQLRecord(decoder *json.Decoder) (keys, values []string, e error) {
+	token, e := decoder.Token()
+	if e != nil {
+		return nil, nil, e
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '{' {
+		return nil, nil, fmt.Errorf("unexpected record `%v`", token)
+	}
+	for decoder.More() {
+		if token, e = decoder.Token(); e != nil {
+			return nil, nil, e
+		}
+		var value json.RawMessage
+		if e = decoder.Decode(&value); e != nil {
+			return nil, nil, e
+		}
+		var str string
+		if json.Unmarshal(value, &str) != nil {
+			str = string(value)
+		}
+		keys = append(keys, fmt.Sprint(token))
+		values = append(values, str)
+	}
+	_, e = decoder.Token()
+	return keys, values, e
+}
+
+// addRecord - keeps a record to be printed with the fields of all
+// records as columns, records may not have the same fields when they
+// are selected with * or come from different objects.
+func (o *sqlOutput) addRecord(keys, values []string) {
+	row := make([]string, len(o.header))
+	for i, key := range keys {
+		column := -1
+		for j, name := range o.header {
+			if name == key {
+				column = j
+				break
+			}
+		}
+		if column < 0 {
+			o.header = append(o.header, key)
+			row = append(row, "")
+			column = len(row) - 1
+		}
+		row[column] = values[i]
+	}
+	o.rows = append(o.rows, row)
+}
+
+// flush - prints the decoded results which are not printed yet.
+func (o *sqlOutput) flush() {
+	if o.aggregate != nil {
+		values := o.aggregate.result()
+		switch o.format {
+		case "json":
+			fields := make([]string, len(values))
+			for i, name := range o.aggregate.header() {
+				value := values[i]
+				if value == "" {
+					value = "null"
+				} else if _, e := strconv.ParseFloat(value, 64); e != nil {
+					value = strconv.Quote(value)
+				}
+				fields[i] = strconv.Quote(name) + ":" + value
+			}
+			fmt.Println("{" + strings.Join(fields, ",") + "}")
+			return
+		case "table":
+			o.header = o.aggregate.header()
+			o.rows = [][]string{values}
+		default:
+			csvWriter := csv.NewWriter(os.Stdout)
+			if o.format == "csv" {
+				csvWriter.Write(o.aggregate.header())
+			}
+			csvWriter.Write(values)
+			csvWriter.Flush()
+			return
+		}
+	}
+	if len(o.header) == 0 {
+		return
+	}
+	if o.format == "csv" {
+		e := o.writeCSV(os.Stdout)
+		fatalIf(probe.NewError(e), "Unable to display the results")
+		return
+	}
+	if o.format != "table" {
+		return
+	}
+
+	rows := o.paddedRows()
+	colors := []*color.Color{color.New(color.FgCyan, color.Bold)}
+	for range o.rows {
+		colors = append(colors, color.New())
+	}
+	alignRight := make([]bool, len(o.header))
+	for i := range alignRight {
+		alignRight[i] = true
+		for _, row := range rows[1:] {
+			if _, e := strconv.ParseFloat(row[i], 64); e != nil && row[i] != "" {
+				alignRight[i] = false
+				break
+			}
+		}
+	}
+	e := console.NewTable(colors, alignRight, 0).DisplayTable(rows)
+	fatalIf(probe.NewError(e), "Unable to display the results")
+}
+
+// paddedRows - returns the header followed by the records, records
+// without some of the fields are padded.
+func (o *sqlOutput) paddedRows() [][]string {
+	rows := [][]string{o.header}
+	for _, row := range o.rows {
+		rows = append(rows, append(row, make([]string, len(o.header)-len(row))...))
+	}
+	return rows
+}
+
+// writeCSV - writes the header and the records in csv.
+func (o *sqlOutput) writeCSV(w io.Writer) error {
+	csvWriter := csv.NewWriter(w)
+	if e := csvWriter.WriteAll(o.paddedRows()); e != nil {
+		return e
+	}
+	return csvWriter.Error()
+}
+
+func sqlSelect(targetURL string, encKeyDB map[string][]prefixSSEPair, selOpts SelectObjectOpts, csvHdrs []string, writeHdr bool, output *sqlOutput) *probe.Error {
 	alias, _, _, err := expandAlias(targetURL)
 	if err != nil {
 		return err.Trace(targetURL)
@@ -390,12 +650,16 @@ func sqlSelect(targetURL, expression string, encKeyDB map[string][]prefixSSEPair
 	}
 
 	sseKey := getSSE(targetURL, encKeyDB[alias])
-	outputer, err := targetClnt.Select(expression, sseKey, selOpts)
+	outputer, err := targetClnt.Select(output.query, sseKey, output.selectOpts(selOpts))
 	if err != nil {
-		return err.Trace(targetURL, expression)
+		return err.Trace(targetURL, output.query)
 	}
 	defer outputer.Close()
 
+	if output.isDecoded() {
+		return probe.NewError(output.decode(outputer))
+	}
+
 	// write csv header to stdout
 	if len(csvHdrs) > 0 && writeHdr {
 		fmt.Println(strings.Join(csvHdrs, ","))
@@ -432,7 +696,6 @@ func mainSQL(ctx *cli.Context) error {
 	var (
 		csvHdrs []string
 		selOpts SelectObjectOpts
-		query   string
 	)
 	// Parse encryption keys per command.
 	encKeyDB, err := getEncKeys(ctx)
@@ -440,44 +703,58 @@ func mainSQL(ctx *cli.Context) error {
 
 	// validate sql input arguments.
 	checkSQLSyntax(ctx)
+	output := newSQLOutput(ctx)
+
+	// Errors of an object are reported without stopping the queries
+	// on the other objects.
+	var failures int
+	writeHdr := true
+	query := func(url string) {
+		if writeHdr {
+			_, csvHdrs, selOpts = getAndValidateArgs(ctx, encKeyDB, url)
+		}
+		if err := sqlSelect(url, encKeyDB, selOpts, csvHdrs, writeHdr, output); err != nil {
+			errorIf(err.Trace(url), "Unable to run sql on `"+url+"`.")
+			failures++
+		}
+		writeHdr = false
+	}
+
 	// extract URLs.
 	URLs := ctx.Args()
-	writeHdr := true
 	for _, url := range URLs {
 		if !isAliasURLDir(url, encKeyDB) {
-			if writeHdr {
-				query, csvHdrs, selOpts = getAndValidateArgs(ctx, encKeyDB, url)
-			}
-			errorIf(sqlSelect(url, query, encKeyDB, selOpts, csvHdrs, writeHdr).Trace(url), "Unable to run sql")
-			writeHdr = false
+			query(url)
 			continue
 		}
 		targetAlias, targetURL, _ := mustExpandAlias(url)
 		clnt, err := newClientFromAlias(targetAlias, targetURL)
 		if err != nil {
 			errorIf(err.Trace(url), "Unable to initialize target `"+url+"`.")
+			failures++
 			continue
 		}
 
 		for content := range clnt.List(ctx.Bool("recursive"), false, false, DirNone) {
 			if content.Err != nil {
 				errorIf(content.Err.Trace(url), "Unable to list on target `"+url+"`.")
+				failures++
 				continue
 			}
-			if writeHdr {
-				query, csvHdrs, selOpts = getAndValidateArgs(ctx, encKeyDB, targetAlias+content.URL.Path)
-			}
 			contentType := mimedb.TypeByExtension(filepath.Ext(content.URL.Path))
 			for _, cTypeSuffix := range supportedContentTypes {
 				if strings.Contains(contentType, cTypeSuffix) {
-					errorIf(sqlSelect(targetAlias+content.URL.Path, query,
-						encKeyDB, selOpts, csvHdrs, writeHdr).Trace(content.URL.String()), "Unable to run sql")
+					query(targetAlias + content.URL.Path)
+					break
 				}
-				writeHdr = false
 			}
 		}
 	}
+	output.flush()
 
+	if failures > 0 {
+		return exitStatus(globalErrorExitStatus)
+	}
 	// Done.
 	return nil
 }
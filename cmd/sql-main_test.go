@@ -17,6 +17,8 @@
 package cmd
 
 import (
+	"bytes"
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -138,3 +140,114 @@ func TestParseSerializationOpts(t *testing.T) {
 		}
 	}
 }
+
+func TestSQLAggregate(t *testing.T) {
+	testCases := []struct {
+		query   string
+		rewrite string
+		header  []string
+		records [][]string
+		result  []string
+		errMsg  string
+	}{
+		{
+			query:   "select count(*) as n, avg(cast(s.age as int)), max(s.age) from S3Object s where s.city = 'paris, tx'",
+			rewrite: "select count(*), sum(cast(s.age as int)), count(cast(s.age as int)), max(s.age) from S3Object s where s.city = 'paris, tx'",
+			header:  []string{"n", "_2", "_3"},
+			records: [][]string{{"3", "106", "3", "45"}, {"0", "", "0", ""}, {"1", "50", "1", "50"}},
+			result:  []string{"4", "39", "50"},
+		},
+		{
+			query:   "SELECT SUM(s.price) total, MIN(s.day) FROM S3Object s",
+			rewrite: "select SUM(s.price), MIN(s.day) FROM S3Object s",
+			header:  []string{"total", "_2"},
+			records: [][]string{{"1.5", "2019-03-01"}, {"2", "2019-01-15"}},
+			result:  []string{"3.5", "2019-01-15"},
+		},
+		{
+			query:  "select s.name from S3Object s",
+			errMsg: "column `s.name` is not an aggregate function",
+		},
+		{
+			query:  "select sum(s.a)+sum(s.b) from S3Object s",
+			errMsg: "column `sum(s.a)+sum(s.b)` is not a single aggregate function",
+		},
+		{
+			query:  "select count(*)",
+			errMsg: "query must have a FROM clause",
+		},
+	}
+
+	for i, testCase := range testCases {
+		aggregate, rewrite, err := newSQLAggregate(testCase.query)
+		if testCase.errMsg != "" {
+			if err == nil || err.Error() != testCase.errMsg {
+				t.Fatalf("Test %d: expected error `%s`, got `%v`", i+1, testCase.errMsg, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("Test %d: unexpected error: %v", i+1, err)
+		}
+		if rewrite != testCase.rewrite {
+			t.Fatalf("Test %d: expected query `%s`, got `%s`", i+1, testCase.rewrite, rewrite)
+		}
+		if header := strings.Join(aggregate.header(), ","); header != strings.Join(testCase.header, ",") {
+			t.Fatalf("Test %d: expected header `%s`, got `%s`", i+1, strings.Join(testCase.header, ","), header)
+		}
+		for _, record := range testCase.records {
+			if err = aggregate.add(record); err != nil {
+				t.Fatalf("Test %d: unexpected error: %v", i+1, err)
+			}
+		}
+		if result := strings.Join(aggregate.result(), ","); result != strings.Join(testCase.result, ",") {
+			t.Fatalf("Test %d: expected result `%s`, got `%s`", i+1, strings.Join(testCase.result, ","), result)
+		}
+	}
+}
+
+func TestSQLOutputSelectOpts(t *testing.T) {
+	csvOutput := map[string]map[string]string{"csv": {}}
+	jsonOutput := map[string]map[string]string{"json": {}}
+	testCases := []struct {
+		output   *sqlOutput
+		expected map[string]map[string]string
+	}{
+		// Results are written as requested by the output flags.
+		{&sqlOutput{}, csvOutput},
+		{&sqlOutput{format: "json"}, jsonOutput},
+		{&sqlOutput{format: "csv"}, jsonOutput},
+		{&sqlOutput{format: "table"}, jsonOutput},
+		{&sqlOutput{format: "json", aggregate: &sqlAggregate{}}, csvOutput},
+	}
+	for i, testCase := range testCases {
+		selOpts := testCase.output.selectOpts(SelectObjectOpts{OutputSerOpts: csvOutput})
+		if !reflect.DeepEqual(selOpts.OutputSerOpts, testCase.expected) {
+			t.Fatalf("Test %d: expected %v, got %v", i+1, testCase.expected, selOpts.OutputSerOpts)
+		}
+	}
+}
+
+func TestSQLOutputCSV(t *testing.T) {
+	output := &sqlOutput{format: "csv"}
+	// Records of different objects, some without all the fields and
+	// with their fields in another order.
+	results := []string{
+		`{"name":"ann","age":34}{"name":"bob"}`,
+		`{"age":45,"name":"cid","city":"paris, tx"}`,
+	}
+	for _, result := range results {
+		if e := output.decode(strings.NewReader(result)); e != nil {
+			t.Fatalf("Unexpected error: %v", e)
+		}
+	}
+
+	var buf bytes.Buffer
+	if e := output.writeCSV(&buf); e != nil {
+		t.Fatalf("Unexpected error: %v", e)
+	}
+	expected := "name,age,city\nann,34,\nbob,,\ncid,45,\"paris, tx\"\n"
+	if buf.String() != expected {
+		t.Fatalf("Expected %q, got %q", expected, buf.String())
+	}
+}
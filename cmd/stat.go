@@ -39,6 +39,9 @@ type statMessage struct {
 	Expires           time.Time         `json:"expires"`
 	EncryptionHeaders map[string]string `json:"encryption,omitempty"`
 	Metadata          map[string]string `json:"metadata"`
+	RetentionMode     string            `json:"retentionMode,omitempty"`
+	RetainUntil       string            `json:"retainUntilDate,omitempty"`
+	LegalHold         string            `json:"legalHold,omitempty"`
 }
 
 // String colorized string message.
@@ -55,6 +58,16 @@ func printStat(stat statMessage) {
 	if !stat.Expires.IsZero() {
 		console.Println(fmt.Sprintf("%-10s: %s ", "Expires", stat.Expires.Format(printDate)))
 	}
+	if stat.RetentionMode != "" {
+		retainUntil := stat.RetainUntil
+		if t, e := time.Parse(time.RFC3339, retainUntil); e == nil {
+			retainUntil = t.Local().Format(printDate)
+		}
+		console.Println(fmt.Sprintf("%-10s: %s until %s ", "Retention", stat.RetentionMode, retainUntil))
+	}
+	if stat.LegalHold != "" {
+		console.Println(fmt.Sprintf("%-10s: %s ", "LegalHold", stat.LegalHold))
+	}
 	var maxKey = 0
 	for k := range stat.Metadata {
 		if len(k) > maxKey {
@@ -109,6 +122,13 @@ func parseStat(c *clientContent) statMessage {
 	content.ETag = strings.TrimSuffix(content.ETag, "\"")
 	content.Expires = c.Expires
 	content.EncryptionHeaders = c.EncryptionHeaders
+	if mode, ok := lookupMetadata(c.Metadata, AmzObjectLockMode); ok && mode != "" {
+		content.RetentionMode = strings.ToUpper(mode)
+		content.RetainUntil, _ = lookupMetadata(c.Metadata, AmzObjectLockRetainUntilDate)
+	}
+	if legalHold, ok := lookupMetadata(c.Metadata, AmzObjectLockLegalHold); ok {
+		content.LegalHold = strings.ToUpper(legalHold)
+	}
 	return content
 }
 
@@ -0,0 +1,796 @@
+/*
+ * MinIO Client (C) 2019 MinIO, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/fatih/color"
+	"github.com/minio/cli"
+	json "github.com/minio/mc/pkg/colorjson"
+	"github.com/minio/mc/pkg/console"
+	"github.com/minio/mc/pkg/probe"
+)
+
+// sync specific flags.
+var (
+	syncFlags = []cli.Flag{
+		cli.StringFlag{
+			Name:  "conflict",
+			Usage: "resolve objects changed on both sides using 'newer-wins', 'source-wins' or 'keep-both'",
+			Value: string(syncNewerWins),
+		},
+		cli.StringFlag{
+			Name:  "conflict-suffix",
+			Usage: "suffix added before the extension of the SECOND version of conflicting objects with 'keep-both'",
+			Value: ".conflict",
+		},
+		cli.BoolFlag{
+			Name:  "fake",
+			Usage: "perform a fake sync operation",
+		},
+		cli.StringSliceFlag{
+			Name:  "exclude",
+			Usage: "exclude object(s) that match specified object name pattern",
+		},
+		cli.StringSliceFlag{
+			Name:  "include",
+			Usage: "include only object(s) that match specified object name pattern",
+		},
+	}
+)
+
+// Synchronize two folders both ways.
+var syncCmd = cli.Command{
+	Name:   "sync",
+	Usage:  "synchronize object(s) both ways between two sites",
+	Action: mainSync,
+	Before: setGlobalsFromContext,
+	Flags:  append(append(syncFlags, ioFlags...), globalFlags...),
+	CustomHelpTemplate: `NAME:
+  {{.HelpName}} - {{.Usage}}
+
+USAGE:
+  {{.HelpName}} [FLAGS] FIRST SECOND
+
+FLAGS:
+  {{range .VisibleFlags}}{{.}}
+  {{end}}
+DESCRIPTION:
+  Sync keeps the state of FIRST and SECOND as of the last sync in the mc config folder. Objects created,
+  updated or removed on one side since the last sync are created, updated or removed on the other side.
+  Objects changed on both sides are conflicts, resolved by '--conflict':
+    newer-wins   the most recently modified version is kept on both sides (default).
+    source-wins  the version of FIRST is kept on both sides.
+    keep-both    the version of FIRST is kept, the version of SECOND is renamed with '--conflict-suffix'.
+  An object removed on one side and updated on the other is restored, unless 'source-wins' is used.
+  Objects present on both sides are compared by ETag before being reported as conflicts, local files
+  by MD5 sum. Objects whose ETag is not their MD5 sum, when uploaded in parts or encrypted, are only
+  compared with local files by size and reported as unverified.
+
+ENVIRONMENT VARIABLES:
+   MC_ENCRYPT:      list of comma delimited prefixes
+   MC_ENCRYPT_KEY:  list of comma delimited prefix=secret values
+
+EXAMPLES:
+  01. Synchronize a local folder with a bucket on MinIO cloud storage.
+      {{.Prompt}} {{.HelpName}} ~/Documents play/team-docs
+
+  02. Synchronize a local folder with a bucket, keeping both versions of objects changed on both sides.
+      {{.Prompt}} {{.HelpName}} --conflict keep-both ~/Documents play/team-docs
+
+  03. Display the changes a sync would perform without performing them.
+      {{.Prompt}} {{.HelpName}} --fake ~/Documents play/team-docs
+
+  04. Synchronize only *.md objects of a local folder with a bucket.
+      {{.Prompt}} {{.HelpName}} --include "*.md" ~/notes s3/notes
+`,
+}
+
+const uaSyncAppName = "mc-sync"
+
+// syncConflictPolicy - resolution of objects changed on both sides.
+type syncConflictPolicy string
+
+const (
+	syncNewerWins  syncConflictPolicy = "newer-wins"
+	syncSourceWins syncConflictPolicy = "source-wins"
+	syncKeepBoth   syncConflictPolicy = "keep-both"
+)
+
+// syncAction - action performed on a single object.
+type syncAction int
+
+const (
+	syncActionNone         syncAction = iota // in sync, nothing to do.
+	syncActionRecord                         // in sync, update the state only.
+	syncActionForget                         // removed on both sides, forget the state.
+	syncActionCopyFirst                      // copy FIRST to SECOND.
+	syncActionCopySecond                     // copy SECOND to FIRST.
+	syncActionRemoveFirst                    // remove on FIRST.
+	syncActionRemoveSecond                   // remove on SECOND.
+	syncActionKeepBoth                       // keep both versions, SECOND is renamed.
+	syncActionUnverified                     // same size, contents cannot be compared, update the state only.
+)
+
+// syncComparison - result of the comparison of the contents of an
+// object on both sides.
+type syncComparison int
+
+const (
+	syncContentDiffer     syncComparison = iota
+	syncContentEqual                     // same ETag or MD5 sum.
+	syncContentUnverified                // same size, the ETag is not an MD5 sum.
+)
+
+// syncContentReader - reads what is missing from the listings to
+// compare contents, only called for contents without ETag.
+type syncContentReader struct {
+	// md5Sum - returns the hex encoded MD5 sum of content, empty if it
+	// cannot be read.
+	md5Sum func(content *clientContent) string
+	// isEncrypted - returns true if the object is encrypted, its ETag
+	// is not its MD5 sum then.
+	isEncrypted func(content *clientContent) bool
+}
+
+// isMD5ETag - returns true if etag may be the MD5 sum of an object,
+// which is not the case of objects uploaded in parts.
+func isMD5ETag(etag string) bool {
+	if len(etag) != 2*md5.Size {
+		return false
+	}
+	_, e := hex.DecodeString(etag)
+	return e == nil
+}
+
+// compareSyncContents - compares the contents of first and second by
+// ETag when both have one. Contents without ETag, such as local files,
+// are compared by their MD5 sum, which is also the ETag of objects
+// uploaded in a single part without encryption.
+func compareSyncContents(first, second *clientContent, reader syncContentReader) syncComparison {
+	if first.Size != second.Size {
+		return syncContentDiffer
+	}
+	if first.ETag != "" && second.ETag != "" {
+		if first.ETag == second.ETag {
+			return syncContentEqual
+		}
+		return syncContentDiffer
+	}
+	if first.ETag == "" && second.ETag == "" {
+		firstSum := reader.md5Sum(first)
+		if firstSum != "" && firstSum == reader.md5Sum(second) {
+			return syncContentEqual
+		}
+		return syncContentDiffer
+	}
+
+	local, object := first, second
+	if first.ETag != "" {
+		local, object = second, first
+	}
+	if !isMD5ETag(object.ETag) {
+		return syncContentUnverified
+	}
+	sum := reader.md5Sum(local)
+	switch {
+	case sum == "":
+		return syncContentDiffer
+	case strings.EqualFold(sum, object.ETag):
+		return syncContentEqual
+	case reader.isEncrypted(object):
+		return syncContentUnverified
+	}
+	return syncContentDiffer
+}
+
+// planSyncAction - decides the action for an object given its content
+// on both sides, nil if missing, and its state as of the last sync.
+// reader is only called to compare contents without ETag.
+func planSyncAction(first, second *clientContent, entry syncStateEntry, hasEntry bool, policy syncConflictPolicy, reader syncContentReader) (action syncAction, isConflict bool) {
+	if first == nil && second == nil {
+		if hasEntry {
+			return syncActionForget, false
+		}
+		return syncActionNone, false
+	}
+
+	if !hasEntry {
+		switch {
+		case second == nil:
+			return syncActionCopyFirst, false
+		case first == nil:
+			return syncActionCopySecond, false
+		}
+		switch compareSyncContents(first, second, reader) {
+		case syncContentEqual:
+			return syncActionRecord, false
+		case syncContentUnverified:
+			return syncActionUnverified, false
+		}
+		return resolveSyncConflict(first, second, policy), true
+	}
+
+	firstChanged := syncFingerprint(first) != entry.First
+	secondChanged := syncFingerprint(second) != entry.Second
+	switch {
+	case !firstChanged && !secondChanged:
+		return syncActionNone, false
+	case firstChanged && !secondChanged:
+		if first == nil {
+			return syncActionRemoveSecond, false
+		}
+		return syncActionCopyFirst, false
+	case !firstChanged && secondChanged:
+		if second == nil {
+			return syncActionRemoveFirst, false
+		}
+		return syncActionCopySecond, false
+	}
+
+	// Changed on both sides.
+	if first == nil || second == nil {
+		if first == nil && second == nil {
+			return syncActionForget, false
+		}
+		return resolveSyncConflict(first, second, policy), true
+	}
+	switch compareSyncContents(first, second, reader) {
+	case syncContentEqual:
+		return syncActionRecord, false
+	case syncContentUnverified:
+		return syncActionUnverified, false
+	}
+	return resolveSyncConflict(first, second, policy), true
+}
+
+// resolveSyncConflict - resolves an object changed on both sides.
+func resolveSyncConflict(first, second *clientContent, policy syncConflictPolicy) syncAction {
+	if policy == syncSourceWins {
+		if first == nil {
+			return syncActionRemoveSecond
+		}
+		return syncActionCopyFirst
+	}
+
+	// An object removed on one side and updated on the other is restored.
+	switch {
+	case first == nil:
+		return syncActionCopySecond
+	case second == nil:
+		return syncActionCopyFirst
+	case policy == syncKeepBoth:
+		return syncActionKeepBoth
+	case second.Time.After(first.Time):
+		return syncActionCopySecond
+	}
+	return syncActionCopyFirst
+}
+
+// syncConflictKey - name of the SECOND version of a conflicting object,
+// suffix is added before the extension.
+func syncConflictKey(key, suffix string) string {
+	ext := path.Ext(key)
+	if ext == path.Base(key) {
+		// Hidden file without extension.
+		ext = ""
+	}
+	return strings.TrimSuffix(key, ext) + suffix + ext
+}
+
+// syncMessage container for sync messages
+type syncMessage struct {
+	Status     string `json:"status"`
+	Action     string `json:"action"`
+	Source     string `json:"source,omitempty"`
+	Target     string `json:"target"`
+	Resolution string `json:"resolution,omitempty"`
+	Size       int64  `json:"size,omitempty"`
+}
+
+// String colorized sync message
+func (s syncMessage) String() string {
+	switch s.Action {
+	case "remove":
+		return console.Colorize("SyncRemove", fmt.Sprintf("Removing `%s`.", s.Target))
+	case "conflict":
+		return console.Colorize("SyncConflict", fmt.Sprintf("Conflict between `%s` and `%s`, resolved with `%s`.", s.Source, s.Target, s.Resolution))
+	case "unverified":
+		return console.Colorize("SyncUnverified", fmt.Sprintf("`%s` and `%s` have the same size, their contents cannot be compared.", s.Source, s.Target))
+	}
+	return console.Colorize("Sync", fmt.Sprintf("`%s` -> `%s`", s.Source, s.Target))
+}
+
+// JSON jsonified sync message
+func (s syncMessage) JSON() string {
+	s.Status = "success"
+	syncMessageBytes, e := json.MarshalIndent(s, "", " ")
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+
+	return string(syncMessageBytes)
+}
+
+// syncSide - one of the two synchronized folders.
+type syncSide struct {
+	alias  string
+	urlStr string // expanded URL, ending with a separator.
+}
+
+// url - expanded URL of key on this side.
+func (s syncSide) url(key string) string {
+	return urlJoinPath(s.urlStr, key)
+}
+
+// displayPath - aliased path of key on this side, for messages.
+func (s syncSide) displayPath(key string) string {
+	return filepath.ToSlash(filepath.Join(s.alias, newClientURL(s.url(key)).Path))
+}
+
+type syncJob struct {
+	first, second syncSide
+
+	policy         syncConflictPolicy
+	conflictSuffix string
+	isFake         bool
+	filter         urlFilter
+	encKeyDB       map[string][]prefixSSEPair
+
+	state *syncState
+
+	// Hold operation status information
+	status Status
+
+	queueCh  chan func() URLs
+	parallel *ParallelManager
+
+	// channel for status messages
+	statusCh chan URLs
+}
+
+// copyObject - copies key from one side to the other, returns the
+// fingerprint of the copied object.
+func (sj *syncJob) copyObject(ctx context.Context, from, to syncSide, fromKey, toKey string, content *clientContent) (string, *probe.Error) {
+	sURLs := URLs{
+		SourceAlias:   from.alias,
+		SourceContent: content,
+		TargetAlias:   to.alias,
+		TargetContent: &clientContent{URL: *newClientURL(to.url(toKey))},
+	}
+	if sURLs = uploadSourceToTargetURL(ctx, sURLs, sj.status, sj.encKeyDB); sURLs.Error != nil {
+		return "", sURLs.Error.Trace(from.url(fromKey), to.url(toKey))
+	}
+	return sj.statObject(to, toKey)
+}
+
+// statObject - returns the fingerprint of key on side.
+func (sj *syncJob) statObject(side syncSide, key string) (string, *probe.Error) {
+	clnt, err := newClientFromAlias(side.alias, side.url(key))
+	if err != nil {
+		return "", err.Trace(side.url(key))
+	}
+	sse := getSSE(side.displayPath(key), sj.encKeyDB[side.alias])
+	content, err := clnt.Stat(false, false, false, sse)
+	if err != nil {
+		return "", err.Trace(side.url(key))
+	}
+	return syncFingerprint(content), nil
+}
+
+// md5Sum - returns the hex encoded MD5 sum of the content of key on
+// side, empty if it cannot be read.
+func (sj *syncJob) md5Sum(side syncSide, key string) string {
+	reader, err := getSourceStreamFromURL(side.displayPath(key), sj.encKeyDB)
+	if err != nil {
+		errorIf(err.Trace(side.url(key)), "Unable to read `"+side.displayPath(key)+"` to compare it.")
+		return ""
+	}
+	defer reader.Close()
+	hash := md5.New()
+	if _, e := io.Copy(hash, reader); e != nil {
+		errorIf(probe.NewError(e).Trace(side.url(key)), "Unable to read `"+side.displayPath(key)+"` to compare it.")
+		return ""
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// isEncrypted - returns true if key on side is encrypted.
+func (sj *syncJob) isEncrypted(side syncSide, key string) bool {
+	sse := getSSE(side.displayPath(key), sj.encKeyDB[side.alias])
+	if sse != nil {
+		return true
+	}
+	clnt, err := newClientFromAlias(side.alias, side.url(key))
+	if err != nil {
+		return false
+	}
+	content, err := clnt.Stat(false, true, false, sse)
+	if err != nil {
+		return false
+	}
+	return len(content.EncryptionHeaders) > 0
+}
+
+// removeObject - removes key on side.
+func (sj *syncJob) removeObject(side syncSide, key string) *probe.Error {
+	clnt, err := newClientFromAlias(side.alias, side.url(key))
+	if err != nil {
+		return err.Trace(side.url(key))
+	}
+	clnt.AddUserAgent(uaSyncAppName, Version)
+	contentCh := make(chan *clientContent, 1)
+	contentCh <- &clientContent{URL: clnt.GetURL()}
+	close(contentCh)
+	isRemoveBucket := false
+	for err = range clnt.Remove(false, isRemoveBucket, false, contentCh) {
+		if err != nil {
+			return err.Trace(side.url(key))
+		}
+	}
+	return nil
+}
+
+// doSync - performs action on key and updates the state accordingly.
+func (sj *syncJob) doSync(ctx context.Context, key string, first, second *clientContent, action syncAction) URLs {
+	sURLs := URLs{SourceAlias: sj.first.alias, SourceContent: first, TargetAlias: sj.second.alias, TargetContent: second}
+	if sj.isFake {
+		if first != nil && (action == syncActionCopyFirst || action == syncActionKeepBoth) {
+			sj.status.Add(first.Size)
+		} else if second != nil && action == syncActionCopySecond {
+			sj.status.Add(second.Size)
+		}
+		sj.status.Update()
+		return sURLs.WithError(nil)
+	}
+
+	switch action {
+	case syncActionCopyFirst:
+		fp, err := sj.copyObject(ctx, sj.first, sj.second, key, key, first)
+		if err != nil {
+			return sURLs.WithError(err)
+		}
+		sj.state.set(key, syncStateEntry{First: syncFingerprint(first), Second: fp})
+	case syncActionCopySecond:
+		fp, err := sj.copyObject(ctx, sj.second, sj.first, key, key, second)
+		if err != nil {
+			return sURLs.WithError(err)
+		}
+		sj.state.set(key, syncStateEntry{First: fp, Second: syncFingerprint(second)})
+	case syncActionRemoveFirst:
+		if err := sj.removeObject(sj.first, key); err != nil {
+			return sURLs.WithError(err)
+		}
+		sj.state.remove(key)
+	case syncActionRemoveSecond:
+		if err := sj.removeObject(sj.second, key); err != nil {
+			return sURLs.WithError(err)
+		}
+		sj.state.remove(key)
+	case syncActionKeepBoth:
+		// Save the SECOND version under the conflict name on both
+		// sides before it is overwritten by the FIRST version.
+		conflictKey := syncConflictKey(key, sj.conflictSuffix)
+		secondFp, err := sj.copyObject(ctx, sj.second, sj.second, key, conflictKey, second)
+		if err != nil {
+			return sURLs.WithError(err)
+		}
+		firstFp, err := sj.copyObject(ctx, sj.second, sj.first, key, conflictKey, second)
+		if err != nil {
+			return sURLs.WithError(err)
+		}
+		sj.state.set(conflictKey, syncStateEntry{First: firstFp, Second: secondFp})
+
+		fp, err := sj.copyObject(ctx, sj.first, sj.second, key, key, first)
+		if err != nil {
+			return sURLs.WithError(err)
+		}
+		sj.state.set(key, syncStateEntry{First: syncFingerprint(first), Second: fp})
+	}
+	return sURLs.WithError(nil)
+}
+
+// printSyncAction - prints the action planned on key.
+func (sj *syncJob) printSyncAction(key string, first, second *clientContent, action syncAction, isConflict bool) {
+	if isConflict {
+		sj.status.PrintMsg(syncMessage{
+			Action:     "conflict",
+			Source:     sj.first.displayPath(key),
+			Target:     sj.second.displayPath(key),
+			Resolution: string(sj.policy),
+		})
+	}
+	switch action {
+	case syncActionCopyFirst:
+		sj.status.PrintMsg(syncMessage{Action: "copy", Source: sj.first.displayPath(key), Target: sj.second.displayPath(key), Size: first.Size})
+	case syncActionCopySecond:
+		sj.status.PrintMsg(syncMessage{Action: "copy", Source: sj.second.displayPath(key), Target: sj.first.displayPath(key), Size: second.Size})
+	case syncActionRemoveFirst:
+		sj.status.PrintMsg(syncMessage{Action: "remove", Target: sj.first.displayPath(key)})
+	case syncActionRemoveSecond:
+		sj.status.PrintMsg(syncMessage{Action: "remove", Target: sj.second.displayPath(key)})
+	case syncActionKeepBoth:
+		conflictKey := syncConflictKey(key, sj.conflictSuffix)
+		sj.status.PrintMsg(syncMessage{Action: "copy", Source: sj.second.displayPath(key), Target: sj.second.displayPath(conflictKey), Size: second.Size})
+		sj.status.PrintMsg(syncMessage{Action: "copy", Source: sj.second.displayPath(key), Target: sj.first.displayPath(conflictKey), Size: second.Size})
+		sj.status.PrintMsg(syncMessage{Action: "copy", Source: sj.first.displayPath(key), Target: sj.second.displayPath(key), Size: first.Size})
+	}
+}
+
+// startSync - compares both sides with the last synced state and
+// queues the actions to perform.
+func (sj *syncJob) startSync(ctx context.Context) {
+	firstClnt, err := newClientFromAlias(sj.first.alias, sj.first.urlStr)
+	if err != nil {
+		sj.statusCh <- URLs{Error: err.Trace(sj.first.urlStr)}
+		return
+	}
+	secondClnt, err := newClientFromAlias(sj.second.alias, sj.second.urlStr)
+	if err != nil {
+		sj.statusCh <- URLs{Error: err.Trace(sj.second.urlStr)}
+		return
+	}
+
+	// Keys seen in the listing, the others were removed on both sides.
+	seen := make(map[string]struct{})
+	isListingComplete := true
+
+	isMetadata, isRecursive, returnSimilar := false, true, true
+	for diffMsg := range difference(firstClnt, secondClnt, sj.first.urlStr, sj.second.urlStr, isMetadata, isRecursive, returnSimilar, DirNone) {
+		if diffMsg.Error != nil {
+			isListingComplete = false
+			sj.statusCh <- URLs{Error: diffMsg.Error}
+			continue
+		}
+		if diffMsg.Diff == differInType {
+			isListingComplete = false
+			sj.statusCh <- URLs{Error: errInvalidTarget(diffMsg.SecondURL).Trace(diffMsg.FirstURL)}
+			continue
+		}
+
+		key := strings.TrimPrefix(diffMsg.FirstURL, sj.first.urlStr)
+		if diffMsg.FirstURL == "" {
+			key = strings.TrimPrefix(diffMsg.SecondURL, sj.second.urlStr)
+		}
+		key = filepath.ToSlash(key)
+		seen[key] = struct{}{}
+		if sj.filter.isExcluded(key) {
+			continue
+		}
+
+		first, second := diffMsg.firstContent, diffMsg.secondContent
+		entry, hasEntry := sj.state.get(key)
+		side := func(content *clientContent) syncSide {
+			if content == first {
+				return sj.first
+			}
+			return sj.second
+		}
+		reader := syncContentReader{
+			md5Sum: func(content *clientContent) string {
+				return sj.md5Sum(side(content), key)
+			},
+			isEncrypted: func(content *clientContent) bool {
+				return sj.isEncrypted(side(content), key)
+			},
+		}
+		action, isConflict := planSyncAction(first, second, entry, hasEntry, sj.policy, reader)
+		switch action {
+		case syncActionNone:
+			continue
+		case syncActionUnverified:
+			sj.status.PrintMsg(syncMessage{Action: "unverified", Source: sj.first.displayPath(key), Target: sj.second.displayPath(key)})
+			fallthrough
+		case syncActionRecord:
+			sj.state.set(key, syncStateEntry{First: syncFingerprint(first), Second: syncFingerprint(second)})
+			continue
+		}
+
+		sj.printSyncAction(key, first, second, action, isConflict)
+		switch action {
+		case syncActionCopyFirst, syncActionKeepBoth:
+			sj.status.Add(first.Size)
+		case syncActionCopySecond:
+			sj.status.Add(second.Size)
+		}
+		sj.status.SetTotal(sj.status.Get()).Update()
+		sj.status.AddCounts(1)
+
+		sj.queueCh <- func() URLs {
+			return sj.doSync(ctx, key, first, second, action)
+		}
+	}
+
+	// Forget objects removed on both sides, only when both sides
+	// were fully listed.
+	if isListingComplete && !sj.isFake {
+		sj.state.mutex.Lock()
+		for key := range sj.state.Entries {
+			if _, ok := seen[key]; !ok {
+				delete(sj.state.Entries, key)
+			}
+		}
+		sj.state.mutex.Unlock()
+	}
+}
+
+// monitorSyncStatus - reports errors of the sync actions.
+func (sj *syncJob) monitorSyncStatus() (errDuringSync bool) {
+	sj.status.Start()
+	defer sj.status.Finish()
+
+	for sURLs := range sj.statusCh {
+		if sURLs.Error != nil {
+			errorIf(sURLs.Error.Trace(), "Failed to perform sync.")
+			errDuringSync = true
+		}
+	}
+	return
+}
+
+// sync - synchronizes both sides and saves the new state.
+func (sj *syncJob) sync(ctx context.Context) bool {
+	go func() {
+		sj.startSync(ctx)
+		close(sj.queueCh)
+		sj.parallel.wait()
+		close(sj.statusCh)
+	}()
+
+	errDuringSync := sj.monitorSyncStatus()
+	if !sj.isFake {
+		fatalIf(sj.state.Save().Trace(), "Unable to save sync state.")
+	}
+	return errDuringSync
+}
+
+func newSyncJob(first, second syncSide, policy syncConflictPolicy, conflictSuffix string, isFake bool, filter urlFilter, state *syncState, encKeyDB map[string][]prefixSSEPair) *syncJob {
+	sj := syncJob{
+		first:          first,
+		second:         second,
+		policy:         policy,
+		conflictSuffix: conflictSuffix,
+		isFake:         isFake,
+		filter:         filter,
+		encKeyDB:       encKeyDB,
+		state:          state,
+		statusCh:       make(chan URLs),
+	}
+
+	sj.parallel, sj.queueCh = newParallelManager(sj.statusCh)
+
+	// we'll define the status to use here,
+	// do we want the quiet status? or the progressbar
+	var status = NewProgressStatus(sj.parallel)
+	if globalQuiet || globalJSON {
+		status = NewQuietStatus(sj.parallel)
+	}
+	sj.status = status
+
+	return &sj
+}
+
+// checkSyncSyntax - validate all the passed arguments
+func checkSyncSyntax(ctx *cli.Context, encKeyDB map[string][]prefixSSEPair) {
+	if len(ctx.Args()) != 2 {
+		cli.ShowCommandHelpAndExit(ctx, "sync", 1) // last argument is exit code
+	}
+	for _, arg := range ctx.Args() {
+		if strings.TrimSpace(arg) == "" {
+			fatalIf(errInvalidArgument().Trace(ctx.Args()...), "Unable to validate empty argument.")
+		}
+	}
+
+	switch syncConflictPolicy(ctx.String("conflict")) {
+	case syncNewerWins, syncSourceWins, syncKeepBoth:
+	default:
+		fatalIf(errInvalidArgument().Trace(ctx.String("conflict")), "Unknown conflict resolution `"+ctx.String("conflict")+"`.")
+	}
+	if ctx.String("conflict-suffix") == "" || strings.ContainsAny(ctx.String("conflict-suffix"), "/\\") {
+		fatalIf(errInvalidArgument().Trace(ctx.String("conflict-suffix")), "Invalid conflict suffix `"+ctx.String("conflict-suffix")+"`.")
+	}
+
+	// Sync only works between two folders, verify them below.
+	for _, url := range ctx.Args() {
+		_, content, err := url2Stat(url, false, false, encKeyDB)
+		fatalIf(err.Trace(url), "Unable to stat `"+url+"`.")
+		if !content.Type.IsDir() {
+			fatalIf(errInvalidArgument().Trace(url), "`"+url+"` is not a folder.")
+		}
+	}
+
+	_, firstURL, _ := mustExpandAlias(ctx.Args().Get(0))
+	_, secondURL, _ := mustExpandAlias(ctx.Args().Get(1))
+	if isURLContains(firstURL, secondURL, "/") || isURLContains(secondURL, firstURL, "/") {
+		fatalIf(errInvalidArgument().Trace(ctx.Args()...), "`"+ctx.Args().Get(0)+"` and `"+ctx.Args().Get(1)+"` cannot overlap.")
+	}
+}
+
+// newSyncSide - expands the aliased URL of one side of the sync.
+func newSyncSide(aliasedURL string) syncSide {
+	separator := string(newClientURL(aliasedURL).Separator)
+	if !strings.HasSuffix(aliasedURL, separator) {
+		aliasedURL += separator
+	}
+	alias, urlStr, _ := mustExpandAlias(aliasedURL)
+	if alias == "" {
+		// Local folders are identified by their absolute path.
+		if absPath, e := filepath.Abs(urlStr); e == nil {
+			urlStr = absPath + separator
+		}
+	}
+	return syncSide{alias: alias, urlStr: urlStr}
+}
+
+// mainSync is the entry point for sync command.
+func mainSync(ctx *cli.Context) error {
+	// Parse encryption keys per command.
+	encKeyDB, err := getEncKeys(ctx)
+	fatalIf(err, "Unable to parse encryption keys.")
+
+	// check 'sync' cli arguments.
+	checkSyncSyntax(ctx, encKeyDB)
+
+	// Additional command specific theme customization.
+	console.SetColor("Sync", color.New(color.FgGreen, color.Bold))
+	console.SetColor("SyncRemove", color.New(color.FgRed, color.Bold))
+	console.SetColor("SyncConflict", color.New(color.FgYellow, color.Bold))
+	console.SetColor("SyncUnverified", color.New(color.FgYellow))
+
+	filter, err := newURLFilter(ctx)
+	fatalIf(err, "Unable to parse include and exclude patterns.")
+
+	first := newSyncSide(ctx.Args().Get(0))
+	second := newSyncSide(ctx.Args().Get(1))
+
+	state, err := loadSyncState(first.alias+":"+first.urlStr, second.alias+":"+second.urlStr)
+	fatalIf(err, "Unable to load sync state.")
+
+	sj := newSyncJob(first, second,
+		syncConflictPolicy(ctx.String("conflict")),
+		ctx.String("conflict-suffix"),
+		ctx.Bool("fake"),
+		filter,
+		state,
+		encKeyDB)
+
+	ctxt, cancelSync := context.WithCancel(context.Background())
+	defer cancelSync()
+
+	go func() {
+		<-signalTrap(os.Interrupt, syscall.SIGTERM)
+		cancelSync()
+		// Save the objects synced so far.
+		if !sj.isFake {
+			errorIf(state.Save().Trace(), "Unable to save sync state.")
+		}
+		os.Exit(globalErrorExitStatus)
+	}()
+
+	if errorDetected := sj.sync(ctxt); errorDetected {
+		return exitStatus(globalErrorExitStatus)
+	}
+	return nil
+}
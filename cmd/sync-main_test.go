@@ -0,0 +1,113 @@
+/*
+ * MinIO Client (C) 2019 MinIO, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPlanSyncAction(t *testing.T) {
+	now := time.Now()
+	older := &clientContent{ETag: "old", Size: 1, Time: now.Add(-time.Hour)}
+	newer := &clientContent{ETag: "new", Size: 2, Time: now}
+	synced := syncStateEntry{First: "old", Second: "old"}
+
+	// Local files have no ETag, they are compared by MD5 sum.
+	local := &clientContent{Size: 1, Time: now}
+	edited := &clientContent{Size: 1, Time: now}
+	remote := &clientContent{ETag: "d41d8cd98f00b204e9800998ecf8427e", Size: 1, Time: now.Add(-time.Hour)}
+	md5Sums := map[*clientContent]string{
+		local:  "D41D8CD98F00B204E9800998ECF8427E",
+		edited: "9e107d9d372bb6826bd81d3542a419d6",
+	}
+	// Objects uploaded in parts or encrypted have an ETag which is not
+	// their MD5 sum.
+	multipart := &clientContent{ETag: "9b2cf535f27731c974343645a3985328-2", Size: 1, Time: now.Add(-time.Hour)}
+	encrypted := &clientContent{ETag: "a5d3c12a38d8b5e5b2b4c1d9f1e3a7b2", Size: 1, Time: now.Add(-time.Hour)}
+	reader := syncContentReader{
+		md5Sum:      func(content *clientContent) string { return md5Sums[content] },
+		isEncrypted: func(content *clientContent) bool { return content == encrypted },
+	}
+
+	testCases := []struct {
+		first, second *clientContent
+		entry         syncStateEntry
+		hasEntry      bool
+		policy        syncConflictPolicy
+		action        syncAction
+		isConflict    bool
+	}{
+		// Never synced.
+		{older, nil, syncStateEntry{}, false, syncNewerWins, syncActionCopyFirst, false},
+		{nil, older, syncStateEntry{}, false, syncNewerWins, syncActionCopySecond, false},
+		{older, older, syncStateEntry{}, false, syncNewerWins, syncActionRecord, false},
+		{older, newer, syncStateEntry{}, false, syncNewerWins, syncActionCopySecond, true},
+		// Changed on one side.
+		{older, older, synced, true, syncNewerWins, syncActionNone, false},
+		{newer, older, synced, true, syncNewerWins, syncActionCopyFirst, false},
+		{older, newer, synced, true, syncNewerWins, syncActionCopySecond, false},
+		{nil, older, synced, true, syncNewerWins, syncActionRemoveSecond, false},
+		{older, nil, synced, true, syncNewerWins, syncActionRemoveFirst, false},
+		{nil, nil, synced, true, syncNewerWins, syncActionForget, false},
+		// Changed on both sides.
+		{newer, newer, synced, true, syncNewerWins, syncActionRecord, false},
+		{newer, &clientContent{ETag: "other", Time: now.Add(-time.Minute)}, synced, true, syncNewerWins, syncActionCopyFirst, true},
+		{newer, &clientContent{ETag: "other", Time: now.Add(time.Minute)}, synced, true, syncNewerWins, syncActionCopySecond, true},
+		{&clientContent{ETag: "other"}, newer, synced, true, syncSourceWins, syncActionCopyFirst, true},
+		{&clientContent{ETag: "other"}, newer, synced, true, syncKeepBoth, syncActionKeepBoth, true},
+		// Removed on one side and updated on the other.
+		{nil, newer, synced, true, syncNewerWins, syncActionCopySecond, true},
+		{nil, newer, synced, true, syncKeepBoth, syncActionCopySecond, true},
+		{nil, newer, synced, true, syncSourceWins, syncActionRemoveSecond, true},
+		{newer, nil, synced, true, syncSourceWins, syncActionCopyFirst, true},
+		// Local files without ETag.
+		{local, remote, syncStateEntry{}, false, syncNewerWins, syncActionRecord, false},
+		{edited, remote, syncStateEntry{}, false, syncNewerWins, syncActionCopyFirst, true},
+		{edited, local, syncStateEntry{}, false, syncNewerWins, syncActionCopyFirst, true},
+		{edited, remote, synced, true, syncNewerWins, syncActionCopyFirst, true},
+		{&clientContent{Size: 1}, remote, syncStateEntry{}, false, syncNewerWins, syncActionCopySecond, true},
+		// Local files compared with objects whose ETag is not their MD5 sum.
+		{local, multipart, syncStateEntry{}, false, syncKeepBoth, syncActionUnverified, false},
+		{multipart, edited, synced, true, syncKeepBoth, syncActionUnverified, false},
+		{local, encrypted, syncStateEntry{}, false, syncKeepBoth, syncActionUnverified, false},
+		{newer, multipart, syncStateEntry{}, false, syncKeepBoth, syncActionKeepBoth, true},
+	}
+
+	for i, testCase := range testCases {
+		action, isConflict := planSyncAction(testCase.first, testCase.second, testCase.entry, testCase.hasEntry, testCase.policy, reader)
+		if action != testCase.action || isConflict != testCase.isConflict {
+			t.Errorf("Test %d: expected action %d and conflict %t, found %d and %t", i+1, testCase.action, testCase.isConflict, action, isConflict)
+		}
+	}
+}
+
+func TestSyncConflictKey(t *testing.T) {
+	testCases := []struct {
+		key, conflictKey string
+	}{
+		{"report.txt", "report.conflict.txt"},
+		{"dir.d/report", "dir.d/report.conflict"},
+		{"dir/.bashrc", "dir/.bashrc.conflict"},
+		{"archive.tar.gz", "archive.tar.conflict.gz"},
+	}
+	for i, testCase := range testCases {
+		if conflictKey := syncConflictKey(testCase.key, ".conflict"); conflictKey != testCase.conflictKey {
+			t.Errorf("Test %d: expected `%s`, found `%s`", i+1, testCase.conflictKey, conflictKey)
+		}
+	}
+}
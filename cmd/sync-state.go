@@ -0,0 +1,153 @@
+/*
+ * MinIO Client (C) 2019 MinIO, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+
+	"github.com/minio/mc/pkg/probe"
+)
+
+// Folder inside the config folder holding the state of sync pairs.
+const globalSyncDir = "sync"
+
+// Version of the sync state format.
+const globalSyncStateVersion = "1"
+
+// syncStateEntry - fingerprints of an object on both sides, as seen
+// at the end of the last sync.
+type syncStateEntry struct {
+	First  string `json:"first"`
+	Second string `json:"second"`
+}
+
+// syncState - last synced state of a pair of folders, used to detect
+// which side changed since the last sync.
+type syncState struct {
+	Version string                    `json:"version"`
+	First   string                    `json:"first"`
+	Second  string                    `json:"second"`
+	Entries map[string]syncStateEntry `json:"entries"`
+
+	mutex *sync.Mutex
+	file  string
+}
+
+// syncFingerprint - identifies the content of an object, the ETag is
+// used when available and size with modification time otherwise.
+func syncFingerprint(content *clientContent) string {
+	if content == nil {
+		return ""
+	}
+	if content.ETag != "" {
+		return content.ETag
+	}
+	return strconv.FormatInt(content.Size, 10) + "-" + strconv.FormatInt(content.Time.UnixNano(), 10)
+}
+
+// getSyncDir - get sync state directory.
+func getSyncDir() (string, *probe.Error) {
+	configDir, err := getMcConfigDir()
+	if err != nil {
+		return "", err.Trace()
+	}
+	return filepath.Join(configDir, globalSyncDir), nil
+}
+
+// loadSyncState - loads the state of the pair first and second, an
+// empty state is returned if they were never synced before.
+func loadSyncState(first, second string) (*syncState, *probe.Error) {
+	syncDir, err := getSyncDir()
+	if err != nil {
+		return nil, err.Trace()
+	}
+	s := &syncState{
+		Version: globalSyncStateVersion,
+		First:   first,
+		Second:  second,
+		Entries: make(map[string]syncStateEntry),
+		mutex:   new(sync.Mutex),
+		file:    filepath.Join(syncDir, getHash("sync", []string{first, second})+".json"),
+	}
+
+	data, e := ioutil.ReadFile(s.file)
+	if e != nil {
+		if os.IsNotExist(e) {
+			return s, nil
+		}
+		return nil, probe.NewError(e).Trace(s.file)
+	}
+	if e = json.Unmarshal(data, s); e != nil {
+		return nil, probe.NewError(e).Trace(s.file)
+	}
+	if s.Version != globalSyncStateVersion {
+		return nil, errInvalidArgument().Trace(s.file, s.Version)
+	}
+	if s.Entries == nil {
+		s.Entries = make(map[string]syncStateEntry)
+	}
+	return s, nil
+}
+
+// get - returns the last synced entry of key.
+func (s *syncState) get(key string) (syncStateEntry, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	entry, ok := s.Entries[key]
+	return entry, ok
+}
+
+// set - records key as synced with the given fingerprints.
+func (s *syncState) set(key string, entry syncStateEntry) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.Entries[key] = entry
+}
+
+// remove - forgets key, after it was removed on both sides.
+func (s *syncState) remove(key string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	delete(s.Entries, key)
+}
+
+// Save - writes the state atomically to the sync folder.
+func (s *syncState) Save() *probe.Error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if e := os.MkdirAll(filepath.Dir(s.file), 0700); e != nil {
+		return probe.NewError(e)
+	}
+	data, e := json.MarshalIndent(s, "", " ")
+	if e != nil {
+		return probe.NewError(e)
+	}
+	tmpFile := s.file + ".tmp"
+	if e = ioutil.WriteFile(tmpFile, data, 0600); e != nil {
+		return probe.NewError(e).Trace(tmpFile)
+	}
+	if e = os.Rename(tmpFile, s.file); e != nil {
+		return probe.NewError(e).Trace(s.file)
+	}
+	return nil
+}
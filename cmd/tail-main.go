@@ -0,0 +1,386 @@
+/*
+ * MinIO Client (C) 2019 MinIO, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/probe"
+	minio "github.com/minio/minio-go/v6"
+	"github.com/minio/minio-go/v6/pkg/encrypt"
+)
+
+// Size of the ranges read from the end of an object to find its last lines.
+const tailChunkSize = 64 * 1024
+
+var (
+	tailFlags = []cli.Flag{
+		cli.Int64Flag{
+			Name:  "n,lines",
+			Usage: "print the last 'n' lines",
+			Value: 10,
+		},
+		cli.Int64Flag{
+			Name:  "c,bytes",
+			Usage: "print the last 'c' bytes instead of lines",
+		},
+		cli.BoolFlag{
+			Name:  "f,follow",
+			Usage: "print data appended to the object as it grows",
+		},
+		cli.StringFlag{
+			Name:  "interval",
+			Usage: "interval between checks of the object size with --follow",
+			Value: "1s",
+		},
+		cli.BoolFlag{
+			Name:  "decompress",
+			Usage: "decompress 'gzip', 'bzip2' and 'zstd' compressed objects",
+		},
+	}
+)
+
+// Display the end of a file.
+var tailCmd = cli.Command{
+	Name:   "tail",
+	Usage:  "display last 'n' lines of an object",
+	Action: mainTail,
+	Before: setGlobalsFromContext,
+	Flags:  append(append(tailFlags, ioFlags...), globalFlags...),
+	CustomHelpTemplate: `NAME:
+  {{.HelpName}} - {{.Usage}}
+
+USAGE:
+  {{.HelpName}} [FLAGS] SOURCE [SOURCE...]
+
+FLAGS:
+  {{range .VisibleFlags}}{{.}}
+  {{end}}
+ENVIRONMENT VARIABLES:
+  MC_ENCRYPT_KEY:  list of comma delimited prefix=secret values
+
+NOTE:
+  Only the end of the object is downloaded, '--follow' checks the size of the object
+  periodically and downloads the data appended to it. With '--decompress', compressed
+  objects are downloaded entirely to be decompressed, the compression is detected from
+  the object name, its Content-Encoding or else its first bytes, which are downloaded
+  as well.
+
+EXAMPLES:
+  1. Display the last 20 lines of an object on Amazon S3.
+     {{.Prompt}} {{.HelpName}} -n 20 s3/logs/server.log
+
+  2. Display the last 100 bytes of a local file.
+     {{.Prompt}} {{.HelpName}} --bytes 100 /var/log/syslog
+
+  3. Keep displaying lines appended to an object, checking its size every 5 seconds.
+     {{.Prompt}} {{.HelpName}} -f --interval 5s s3/logs/server.log
+
+  4. Display the last line of server encrypted object on Amazon S3.
+     {{.Prompt}} {{.HelpName}} -n 1 --encrypt-key 's3/logs=32byteslongsecretkeymustbegiven1' s3/logs/server.log
+
+  5. Display the last 20 lines of a 'gzip' compressed object on Amazon S3.
+     {{.Prompt}} {{.HelpName}} -n 20 --decompress s3/logs/server.log.gz
+`,
+}
+
+// tailOptions - options of the tail command.
+type tailOptions struct {
+	nlines int64
+	// nbytes is negative to print lines.
+	nbytes     int64
+	follow     bool
+	decompress bool
+	interval   time.Duration
+}
+
+// checkTailSyntax - validates the arguments of the tail command.
+func checkTailSyntax(ctx *cli.Context) tailOptions {
+	opts := tailOptions{
+		nlines:     ctx.Int64("lines"),
+		nbytes:     -1,
+		follow:     ctx.Bool("follow"),
+		decompress: ctx.Bool("decompress"),
+	}
+	if opts.nlines < 0 {
+		fatalIf(errInvalidArgument().Trace(ctx.String("lines")), "Number of lines cannot be negative.")
+	}
+	if ctx.IsSet("bytes") {
+		if opts.nbytes = ctx.Int64("bytes"); opts.nbytes < 0 {
+			fatalIf(errInvalidArgument().Trace(ctx.String("bytes")), "Number of bytes cannot be negative.")
+		}
+	}
+	if opts.follow {
+		if len(ctx.Args()) != 1 || ctx.Args().First() == "-" {
+			fatalIf(errInvalidArgument().Trace(ctx.Args()...), "--follow requires exactly one object.")
+		}
+		var e error
+		opts.interval, e = time.ParseDuration(ctx.String("interval"))
+		if e != nil || opts.interval <= 0 {
+			fatalIf(errInvalidArgument().Trace(ctx.String("interval")), "Invalid interval `"+ctx.String("interval")+"`.")
+		}
+	}
+	return opts
+}
+
+// tailLinesOffset - returns the offset in buf[:end] of its last nlines
+// lines, nlines being positive. When buf has fewer lines the offset is
+// 0 and left is the number of lines still to find before buf.
+func tailLinesOffset(buf []byte, end int, nlines int64) (offset int, left int64) {
+	for ; nlines > 0; nlines-- {
+		i := bytes.LastIndexByte(buf[:end], '\n')
+		if i < 0 {
+			return 0, nlines
+		}
+		end = i
+	}
+	return end + 1, 0
+}
+
+// tailReadRange - reads length bytes of an object from offset.
+type tailReadRange func(offset, length int64) ([]byte, *probe.Error)
+
+// newTailReadRange - returns a function reading ranges of an object
+// with ranged GET requests.
+func newTailReadRange(clnt Client, sse encrypt.ServerSide) tailReadRange {
+	return func(offset, length int64) ([]byte, *probe.Error) {
+		reader, err := clnt.Get(offset, length, sse)
+		if err != nil {
+			return nil, err.Trace(clnt.GetURL().String())
+		}
+		defer reader.Close()
+		buf, e := ioutil.ReadAll(reader)
+		if e != nil {
+			return nil, probe.NewError(e).Trace(clnt.GetURL().String())
+		}
+		return buf, nil
+	}
+}
+
+// tailObject - prints the end of an object of size bytes, reading it
+// backwards in chunks until enough lines are found.
+func tailObject(readRange tailReadRange, size int64, opts tailOptions) *probe.Error {
+	if opts.nbytes >= 0 {
+		offset := size - opts.nbytes
+		if offset < 0 {
+			offset = 0
+		}
+		buf, err := readRange(offset, size-offset)
+		if err != nil {
+			return err
+		}
+		return catOut(bytes.NewReader(buf), -1)
+	}
+
+	chunks, err := tailObjectLines(readRange, size, opts.nlines)
+	if err != nil {
+		return err
+	}
+	readers := make([]io.Reader, len(chunks))
+	for i, chunk := range chunks {
+		readers[i] = bytes.NewReader(chunk)
+	}
+	return catOut(io.MultiReader(readers...), -1)
+}
+
+// tailObjectLines - returns the last nlines lines of an object of size
+// bytes in chunks, the object is read backwards and only the newlines
+// of the chunk last read are counted.
+func tailObjectLines(readRange tailReadRange, size, nlines int64) ([][]byte, *probe.Error) {
+	var chunks [][]byte
+	for offset := size; offset > 0 && nlines > 0; {
+		length := int64(tailChunkSize)
+		if length > offset {
+			length = offset
+		}
+		offset -= length
+		chunk, err := readRange(offset, length)
+		if err != nil {
+			return nil, err
+		}
+		end := len(chunk)
+		// The newline ending the last line does not start another line.
+		if offset+length == size && end > 0 && chunk[end-1] == '\n' {
+			end--
+		}
+		var start int
+		start, nlines = tailLinesOffset(chunk, end, nlines)
+		chunks = append(chunks, chunk[start:])
+	}
+	// Chunks were read from the end.
+	for i, j := 0, len(chunks)-1; i < j; i, j = i+1, j-1 {
+		chunks[i], chunks[j] = chunks[j], chunks[i]
+	}
+	return chunks, nil
+}
+
+// tailStream - prints the end of a stream which cannot be read backwards,
+// only its last lines or bytes are kept while reading it.
+func tailStream(r io.Reader, opts tailOptions) *probe.Error {
+	var buf []byte
+	if opts.nbytes >= 0 {
+		chunk := make([]byte, tailChunkSize)
+		for {
+			n, e := r.Read(chunk)
+			buf = append(buf, chunk[:n]...)
+			if int64(len(buf)) > opts.nbytes {
+				buf = buf[int64(len(buf))-opts.nbytes:]
+			}
+			if e == io.EOF {
+				break
+			}
+			if e != nil {
+				return probe.NewError(e)
+			}
+		}
+		return catOut(bytes.NewReader(buf), -1)
+	}
+
+	var lines [][]byte
+	br := bufio.NewReader(r)
+	for {
+		line, e := br.ReadBytes('\n')
+		if len(line) > 0 && opts.nlines > 0 {
+			if lines = append(lines, line); int64(len(lines)) > opts.nlines {
+				lines = lines[1:]
+			}
+		}
+		if e == io.EOF {
+			break
+		}
+		if e != nil {
+			return probe.NewError(e)
+		}
+	}
+	return catOut(bytes.NewReader(bytes.Join(lines, nil)), -1)
+}
+
+// tailFollow - prints the data appended to an object, the object is
+// printed from its start when it is truncated.
+func tailFollow(clnt Client, size int64, sse encrypt.ServerSide, opts tailOptions) *probe.Error {
+	readRange := newTailReadRange(clnt, sse)
+	for {
+		time.Sleep(opts.interval)
+		content, err := clnt.Stat(false, false, false, sse)
+		if err != nil {
+			return err.Trace(clnt.GetURL().String())
+		}
+		if content.Size < size {
+			size = 0
+		}
+		if content.Size == size {
+			continue
+		}
+		buf, err := readRange(size, content.Size-size)
+		if err != nil {
+			return err
+		}
+		if err = catOut(bytes.NewReader(buf), -1); err != nil {
+			return err
+		}
+		size += int64(len(buf))
+	}
+}
+
+// tailURL displays the end of a URL to stdout.
+func tailURL(sourceURL string, encKeyDB map[string][]prefixSSEPair, opts tailOptions) *probe.Error {
+	if sourceURL == "-" {
+		if !opts.decompress {
+			return tailStream(os.Stdin, opts)
+		}
+		reader, err := newDecompressReader(os.Stdin, "", nil)
+		if err != nil {
+			return err
+		}
+		return tailStream(reader, opts)
+	}
+
+	alias, _, _, err := expandAlias(sourceURL)
+	if err != nil {
+		return err.Trace(sourceURL)
+	}
+	clnt, content, err := url2Stat(sourceURL, false, false, encKeyDB)
+	if err != nil {
+		return err.Trace(sourceURL)
+	}
+	sse := getSSE(sourceURL, encKeyDB[alias])
+	readRange := newTailReadRange(clnt, sse)
+
+	// Compressed objects are detected by their magic bytes as well.
+	cType := minio.SelectCompressionNONE
+	if opts.decompress {
+		cType = compressionTypeFromMetadata(sourceURL, content.Metadata)
+		if cType == minio.SelectCompressionNONE && content.Size > 0 {
+			header, err := readRange(0, compressionMagicSize)
+			if err != nil {
+				return err.Trace(sourceURL)
+			}
+			cType = compressionTypeFromMagic(header)
+		}
+	}
+	if cType != minio.SelectCompressionNONE {
+		if opts.follow {
+			return probe.NewError(errors.New("compressed objects cannot be followed")).Trace(sourceURL)
+		}
+		reader, err := clnt.Get(0, -1, sse)
+		if err != nil {
+			return err.Trace(sourceURL)
+		}
+		decompressReader, err := newDecompressReader(reader, sourceURL, content.Metadata)
+		if err != nil {
+			reader.Close()
+			return err.Trace(sourceURL)
+		}
+		defer decompressReader.Close()
+		return tailStream(decompressReader, opts).Trace(sourceURL)
+	}
+
+	if err = tailObject(readRange, content.Size, opts); err != nil {
+		return err.Trace(sourceURL)
+	}
+	if opts.follow {
+		return tailFollow(clnt, content.Size, sse, opts).Trace(sourceURL)
+	}
+	return nil
+}
+
+// mainTail is the main entry point for tail command.
+func mainTail(ctx *cli.Context) error {
+	// Parse encryption keys per command.
+	encKeyDB, err := getEncKeys(ctx)
+	fatalIf(err, "Unable to parse encryption keys.")
+
+	opts := checkTailSyntax(ctx)
+
+	// handle std input data.
+	if !ctx.Args().Present() {
+		fatalIf(tailURL("-", encKeyDB, opts).Trace(), "Unable to read from standard input.")
+		return nil
+	}
+
+	for _, url := range ctx.Args() {
+		fatalIf(tailURL(url, encKeyDB, opts).Trace(url), "Unable to read from `"+url+"`.")
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+/*
+ * MinIO Client (C) 2019 MinIO, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/minio/mc/pkg/probe"
+)
+
+func TestTailLinesOffset(t *testing.T) {
+	testCases := []struct {
+		buf    string
+		end    int
+		nlines int64
+		offset int
+		left   int64
+	}{
+		{"a\nb\nc\n", 5, 2, 2, 0},
+		{"a\nb\nc", 5, 2, 2, 0},
+		{"a\nb\nc\n", 5, 3, 0, 1},
+		{"a\nb\nc\n", 6, 1, 6, 0},
+		{"\n\n", 1, 1, 1, 0},
+		{"", 0, 1, 0, 1},
+	}
+
+	for i, testCase := range testCases {
+		offset, left := tailLinesOffset([]byte(testCase.buf), testCase.end, testCase.nlines)
+		if offset != testCase.offset || left != testCase.left {
+			t.Fatalf("Test %d: expected (%d, %d), found (%d, %d)", i+1, testCase.offset, testCase.left, offset, left)
+		}
+	}
+}
+
+func TestTailObjectLines(t *testing.T) {
+	// Lines as long as or longer than a chunk.
+	long := strings.Repeat("x", tailChunkSize+10)
+	object := "first\n" + long + "\n" + strings.Repeat("y", tailChunkSize-1) + "\nlast\n"
+
+	testCases := []struct {
+		nlines   int64
+		expected string
+		read     int64
+	}{
+		{0, "", 0},
+		{1, "last\n", tailChunkSize},
+		{2, strings.Repeat("y", tailChunkSize-1) + "\nlast\n", 2 * tailChunkSize},
+		{3, long + "\n" + strings.Repeat("y", tailChunkSize-1) + "\nlast\n", int64(len(object))},
+		{10, object, int64(len(object))},
+	}
+
+	for i, testCase := range testCases {
+		var read int64
+		readRange := func(offset, length int64) ([]byte, *probe.Error) {
+			read += length
+			return []byte(object[offset : offset+length]), nil
+		}
+		chunks, err := tailObjectLines(readRange, int64(len(object)), testCase.nlines)
+		if err != nil {
+			t.Fatalf("Test %d: unexpected error: %v", i+1, err)
+		}
+		if got := string(bytes.Join(chunks, nil)); got != testCase.expected {
+			t.Fatalf("Test %d: expected %d bytes, got %d bytes", i+1, len(testCase.expected), len(got))
+		}
+		if read != testCase.read {
+			t.Fatalf("Test %d: expected %d bytes read, got %d", i+1, testCase.read, read)
+		}
+	}
+}
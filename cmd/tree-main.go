@@ -19,11 +19,15 @@ package cmd
 import (
 	"errors"
 	"fmt"
+	"path"
 	"path/filepath"
+	"sort"
 	"strings"
 
+	humanize "github.com/dustin/go-humanize"
 	"github.com/fatih/color"
 	"github.com/minio/cli"
+	json "github.com/minio/mc/pkg/colorjson"
 	"github.com/minio/mc/pkg/console"
 	"github.com/minio/mc/pkg/probe"
 )
@@ -40,6 +44,7 @@ type treeMessage struct {
 	Entry        string
 	IsDir        bool
 	BranchString string
+	Usage        string
 }
 
 // Colorized message for console printing.
@@ -48,11 +53,14 @@ func (t treeMessage) String() string {
 	if t.IsDir {
 		entryType = "Dir"
 	}
+	if t.Usage != "" {
+		return fmt.Sprintf("%s%s %s", t.BranchString, console.Colorize("Size", "["+t.Usage+"]"), console.Colorize(entryType, t.Entry))
+	}
 	return fmt.Sprintf("%s%s", t.BranchString, console.Colorize(entryType, t.Entry))
 }
 
 // JSON'ified message for scripting.
-// Does No-op. JSON requests are redirected to `ls -r --json`
+// Does No-op. JSON requests are printed as a whole tree by treeNode.
 func (t treeMessage) JSON() string {
 	fatalIf(probe.NewError(errors.New("JSON() should never be called here")), "Unable to list in tree format. Please report this issue at https://github.com/minio/mc/issues")
 	return ""
@@ -68,6 +76,18 @@ var treeFlags = []cli.Flag{
 		Usage: "sets the depth threshold",
 		Value: -1,
 	},
+	cli.BoolFlag{
+		Name:  "size, s",
+		Usage: "print the total size and object count of each folder and the size of each file",
+	},
+	cli.BoolFlag{
+		Name:  "du",
+		Usage: "print sizes as with '--size' and sort the entries of each folder by size, largest first",
+	},
+	cli.IntFlag{
+		Name:  "limit",
+		Usage: "print at most N entries of each folder",
+	},
 }
 
 // trees files and folders.
@@ -101,6 +121,12 @@ EXAMPLES:
 
    5. List all directories upto depth level '2' in tree format.
       {{.Prompt}} {{.HelpName}} --depth 2 myminio/mybucket/
+
+   6. List the 5 largest directories of each level of "mybucket" with their total size and object count.
+      {{.Prompt}} {{.HelpName}} --du --limit 5 myminio/mybucket/
+
+   7. Print the tree of "mybucket" with the sizes of all directories and objects as a JSON document.
+      {{.Prompt}} {{.HelpName}} --files --json myminio/mybucket/
 `,
 }
 
@@ -114,6 +140,10 @@ func checkTreeSyntax(ctx *cli.Context) {
 		}
 	}
 
+	if ctx.Int("limit") < 0 {
+		fatalIf(errInvalidArgument().Trace(ctx.String("limit")), "please set a proper limit, for example: '--limit 10' to print at most 10 entries of each folder")
+	}
+
 	if (args.Present()) && len(args) == 0 {
 		return
 	}
@@ -244,6 +274,188 @@ func doTree(url string, level int, leaf bool, branchString string, depth int, in
 	return nil
 }
 
+// treeNode - a folder or a file of a tree with its total size and
+// object count, folders only keep the entries which are printed.
+type treeNode struct {
+	Status         string      `json:"status,omitempty"`
+	Name           string      `json:"name"`
+	Type           string      `json:"type"`
+	Size           int64       `json:"size"`
+	Objects        int64       `json:"objects"`
+	Children       []*treeNode `json:"children,omitempty"`
+	OmittedEntries int         `json:"omittedEntries,omitempty"`
+	OmittedSize    int64       `json:"omittedSize,omitempty"`
+
+	// children indexes the entries of a folder by name.
+	children map[string]*treeNode
+}
+
+// child - returns the entry of a folder, it is added when missing.
+func (n *treeNode) child(name string, isDir bool) *treeNode {
+	if child, ok := n.children[name]; ok {
+		return child
+	}
+	child := &treeNode{Name: name, Type: "file"}
+	if isDir {
+		child.Type = "folder"
+		child.children = make(map[string]*treeNode)
+	}
+	n.children[name] = child
+	n.Children = append(n.Children, child)
+	return child
+}
+
+// sortBySize - sorts the entries of each folder by size, largest first.
+func (n *treeNode) sortBySize() {
+	sort.SliceStable(n.Children, func(i, j int) bool {
+		return n.Children[i].Size > n.Children[j].Size
+	})
+	for _, child := range n.Children {
+		child.sortBySize()
+	}
+}
+
+// limit - keeps at most limit entries of each folder, the others are
+// only accounted as omitted.
+func (n *treeNode) limit(limit int) {
+	if len(n.Children) > limit {
+		for _, child := range n.Children[limit:] {
+			n.OmittedEntries++
+			n.OmittedSize += child.Size
+		}
+		n.Children = n.Children[:limit]
+	}
+	for _, child := range n.Children {
+		child.limit(limit)
+	}
+}
+
+// usage - returns the size and object count as printed in the tree.
+func (n *treeNode) usage() string {
+	size := strings.Join(strings.Fields(humanize.IBytes(uint64(n.Size))), "")
+	if n.Type == "file" {
+		return size
+	}
+	return fmt.Sprintf("%s, %d object(s)", size, n.Objects)
+}
+
+// print - prints the entries of a folder in a tree format.
+func (n *treeNode) print(branchString string, showUsage bool) {
+	for i, child := range n.Children {
+		isLast := i == len(n.Children)-1 && n.OmittedEntries == 0
+		msg := treeMessage{
+			Entry:        child.Name,
+			IsDir:        child.Type == "folder",
+			BranchString: branchString + treeEntry,
+		}
+		if isLast {
+			msg.BranchString = branchString + treeLastEntry
+		}
+		if showUsage {
+			msg.Usage = child.usage()
+		}
+		printMsg(msg)
+
+		if isLast {
+			child.print(branchString+" "+treeLevel, showUsage)
+		} else {
+			child.print(branchString+treeNext+treeLevel, showUsage)
+		}
+	}
+	if n.OmittedEntries > 0 {
+		omitted := fmt.Sprintf("... %d more entries", n.OmittedEntries)
+		if showUsage {
+			omitted += ", " + strings.Join(strings.Fields(humanize.IBytes(uint64(n.OmittedSize))), "")
+		}
+		console.Println(branchString + treeLastEntry + console.Colorize("Omitted", omitted))
+	}
+}
+
+// String - tree format of the folder with its entries.
+func (n *treeNode) String() string {
+	return n.Name
+}
+
+// JSON - jsonified tree of the folder with its entries.
+func (n *treeNode) JSON() string {
+	n.Status = "success"
+	treeJSONBytes, e := json.MarshalIndent(n, "", " ")
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+
+	return string(treeJSONBytes)
+}
+
+// buildTree - lists a folder recursively into a tree, the entries are
+// kept up to the same levels as printed by doTree with depth.
+func buildTree(url string, depth int, includeFiles bool) (*treeNode, error) {
+	targetAlias, targetURL, _ := mustExpandAlias(url)
+	if !strings.HasSuffix(targetURL, "/") {
+		targetURL += "/"
+	}
+
+	clnt, err := newClientFromAlias(targetAlias, targetURL)
+	fatalIf(err.Trace(targetURL), "Unable to initialize target `"+targetURL+"`.")
+
+	return listTree(clnt, url, depth, includeFiles)
+}
+
+// listTree - lists the folder of clnt into a tree named name, entries
+// failing to list are reported and the tree is built from the others,
+// the error exit status is then returned along with the tree.
+func listTree(clnt Client, name string, depth int, includeFiles bool) (*treeNode, error) {
+	var listErr error
+	rootPath := path.Clean(filepath.ToSlash(clnt.GetURL().Path))
+	root := &treeNode{Name: name, Type: "folder", children: make(map[string]*treeNode)}
+	isRecursive := true
+	isIncomplete := false
+	for content := range clnt.List(isRecursive, isIncomplete, false, DirFirst) {
+		if content.Err != nil {
+			errorIf(content.Err.Trace(clnt.GetURL().String()), "Unable to tree.")
+			listErr = exitStatus(globalErrorExitStatus)
+			continue
+		}
+
+		isDir := content.Type.IsDir()
+		contentPath := path.Clean(filepath.ToSlash(content.URL.Path))
+		var entryPath string
+		switch {
+		case rootPath == ".":
+			entryPath = contentPath
+		case rootPath == "/":
+			entryPath = strings.TrimPrefix(contentPath, "/")
+		case strings.HasPrefix(contentPath, rootPath+"/"):
+			entryPath = strings.TrimPrefix(contentPath, rootPath+"/")
+		}
+		// Skip the listed folder itself.
+		if entryPath == "" || entryPath == "." {
+			continue
+		}
+
+		// Objects are accounted in all their parent folders.
+		node := root
+		if !isDir {
+			node.Size += content.Size
+			node.Objects++
+		}
+		entries := strings.Split(entryPath, "/")
+		for i, entry := range entries {
+			isLastEntry := i == len(entries)-1
+			if depth != -1 && i > depth {
+				break
+			}
+			if isLastEntry && !isDir && !includeFiles {
+				break
+			}
+			node = node.child(entry, !isLastEntry || isDir)
+			if !isDir {
+				node.Size += content.Size
+				node.Objects++
+			}
+		}
+	}
+	return root, listErr
+}
+
 // mainTree - is a handler for mc tree command
 func mainTree(ctx *cli.Context) error {
 
@@ -252,6 +464,8 @@ func mainTree(ctx *cli.Context) error {
 
 	console.SetColor("File", color.New(color.Bold))
 	console.SetColor("Dir", color.New(color.FgCyan, color.Bold))
+	console.SetColor("Size", color.New(color.FgYellow))
+	console.SetColor("Omitted", color.New(color.FgWhite, color.Italic))
 
 	args := ctx.Args()
 	// mimic operating system tool behavior.
@@ -261,24 +475,40 @@ func mainTree(ctx *cli.Context) error {
 
 	includeFiles := ctx.Bool("files")
 	depth := ctx.Int("depth")
+	showUsage := ctx.Bool("size") || ctx.Bool("du")
+	limit := ctx.Int("limit")
 
 	var cErr error
 	for _, targetURL := range args {
-		if !globalJSON {
+		// Trees are only built in memory when folders need to be
+		// summed up, sorted or limited.
+		if !globalJSON && !showUsage && limit == 0 {
 			if e := doTree(targetURL, 1, false, "", depth, includeFiles); e != nil {
 				cErr = e
 			}
-		} else {
-			targetAlias, targetURL, _ := mustExpandAlias(targetURL)
-			if !strings.HasSuffix(targetURL, "/") {
-				targetURL += "/"
-			}
-			clnt, err := newClientFromAlias(targetAlias, targetURL)
-			fatalIf(err.Trace(targetURL), "Unable to initialize target `"+targetURL+"`.")
-			if e := doList(clnt, true, false); e != nil {
-				cErr = e
-			}
+			continue
+		}
+
+		root, e := buildTree(targetURL, depth, includeFiles)
+		if e != nil {
+			cErr = e
+		}
+		if ctx.Bool("du") {
+			root.sortBySize()
+		}
+		if limit > 0 {
+			root.limit(limit)
+		}
+		if globalJSON {
+			printMsg(root)
+			continue
+		}
+		msg := treeMessage{Entry: root.Name, IsDir: true}
+		if showUsage {
+			msg.Usage = root.usage()
 		}
+		printMsg(msg)
+		root.print("", showUsage)
 	}
 	return cErr
 }
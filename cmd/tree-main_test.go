@@ -0,0 +1,167 @@
+/*
+ * MinIO Client (C) 2019 MinIO, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// newTestTree - lists a temporary folder with the given files into a tree.
+func newTestTree(t *testing.T, files map[string]int, depth int, includeFiles bool) *treeNode {
+	dir, e := ioutil.TempDir("", "tree-")
+	if e != nil {
+		t.Fatal(e)
+	}
+	defer os.RemoveAll(dir)
+	for name, size := range files {
+		path := filepath.Join(dir, name)
+		if e = os.MkdirAll(filepath.Dir(path), 0755); e != nil {
+			t.Fatal(e)
+		}
+		if e = ioutil.WriteFile(path, make([]byte, size), 0644); e != nil {
+			t.Fatal(e)
+		}
+	}
+	clnt, err := fsNew(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root, e := listTree(clnt, "root", depth, includeFiles)
+	if e != nil {
+		t.Fatal(e)
+	}
+	return root
+}
+
+// treeNames - returns the names of the entries of a tree, depth first.
+func treeNames(n *treeNode) []string {
+	var names []string
+	for _, child := range n.Children {
+		names = append(names, child.Name)
+		for _, name := range treeNames(child) {
+			names = append(names, child.Name+"/"+name)
+		}
+	}
+	return names
+}
+
+var testTreeFiles = map[string]int{"a/1": 1, "a/b/2": 2, "c": 50, "d/3": 10}
+
+func TestListTree(t *testing.T) {
+	testCases := []struct {
+		depth        int
+		includeFiles bool
+		names        []string
+	}{
+		{-1, true, []string{"a", "a/1", "a/b", "a/b/2", "c", "d", "d/3"}},
+		{-1, false, []string{"a", "a/b", "d"}},
+		{0, true, []string{"a", "c", "d"}},
+		{1, false, []string{"a", "a/b", "d"}},
+	}
+	for i, testCase := range testCases {
+		root := newTestTree(t, testTreeFiles, testCase.depth, testCase.includeFiles)
+		if names := treeNames(root); !reflect.DeepEqual(names, testCase.names) {
+			t.Errorf("Test %d: Expected %v, got %v", i+1, testCase.names, names)
+		}
+		// Objects are accounted in their folders, listed or not.
+		if root.Size != 63 || root.Objects != 4 {
+			t.Errorf("Test %d: Expected 63 bytes in 4 objects, got %d bytes in %d objects", i+1, root.Size, root.Objects)
+		}
+	}
+}
+
+func TestTreeUsage(t *testing.T) {
+	root := newTestTree(t, testTreeFiles, -1, true)
+	testCases := []struct {
+		node  *treeNode
+		usage string
+	}{
+		{root, "63B, 4 object(s)"},
+		{root.children["a"], "3B, 2 object(s)"},
+		{root.children["a"].children["b"], "2B, 1 object(s)"},
+		{root.children["c"], "50B"},
+	}
+	for i, testCase := range testCases {
+		if usage := testCase.node.usage(); usage != testCase.usage {
+			t.Errorf("Test %d: Expected %q, got %q", i+1, testCase.usage, usage)
+		}
+	}
+}
+
+func TestTreeSortAndLimit(t *testing.T) {
+	root := newTestTree(t, testTreeFiles, -1, true)
+	root.sortBySize()
+	if names := treeNames(root); !reflect.DeepEqual(names, []string{"c", "d", "d/3", "a", "a/b", "a/b/2", "a/1"}) {
+		t.Errorf("Unexpected order by size %v", names)
+	}
+
+	root.limit(1)
+	if names := treeNames(root); !reflect.DeepEqual(names, []string{"c"}) {
+		t.Errorf("Unexpected limited entries %v", names)
+	}
+	if root.OmittedEntries != 2 || root.OmittedSize != 13 {
+		t.Errorf("Expected 2 omitted entries of 13 bytes, got %d of %d bytes", root.OmittedEntries, root.OmittedSize)
+	}
+}
+
+func TestTreeJSON(t *testing.T) {
+	root := newTestTree(t, map[string]int{"a/1": 1, "c": 50}, -1, true)
+	root.limit(1)
+
+	var decoded map[string]interface{}
+	if e := json.Unmarshal([]byte(root.JSON()), &decoded); e != nil {
+		t.Fatal(e)
+	}
+	expected := map[string]interface{}{
+		"status":  "success",
+		"name":    "root",
+		"type":    "folder",
+		"size":    float64(51),
+		"objects": float64(2),
+		"children": []interface{}{
+			map[string]interface{}{
+				"name":    "a",
+				"type":    "folder",
+				"size":    float64(1),
+				"objects": float64(1),
+				"children": []interface{}{
+					map[string]interface{}{"name": "1", "type": "file", "size": float64(1), "objects": float64(1)},
+				},
+			},
+		},
+		"omittedEntries": float64(1),
+		"omittedSize":    float64(50),
+	}
+	if !reflect.DeepEqual(decoded, expected) {
+		t.Errorf("Expected %v, got %v", expected, decoded)
+	}
+}
+
+func TestListTreeError(t *testing.T) {
+	clnt, err := fsNew(filepath.Join(os.TempDir(), "tree-nonexistent", "folder"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, e := listTree(clnt, "root", -1, true); e == nil {
+		t.Error("Expected an error listing a missing folder")
+	}
+}
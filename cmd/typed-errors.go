@@ -112,6 +112,20 @@ var errOverWriteNotAllowed = func(URL string) *probe.Error {
 	return probe.NewError(overwriteNotAllowedErr{errors.New(msg)})
 }
 
+type removeLimitExceededErr error
+
+var errRemoveLimitExceeded = func(count int64, limit string) *probe.Error {
+	msg := fmt.Sprintf("Refusing to remove %d object(s) on target, exceeds `--max-delete` limit of %s.", count, limit)
+	return probe.NewError(removeLimitExceededErr(errors.New(msg))).Untrace()
+}
+
+type emptySourceRemoveErr error
+
+var errEmptySourceRemove = func(count int64) *probe.Error {
+	msg := fmt.Sprintf("Source is empty, refusing to remove %d object(s) on target. Use `--allow-empty-source` to override this behavior.", count)
+	return probe.NewError(emptySourceRemoveErr(errors.New(msg))).Untrace()
+}
+
 type sourceIsDirErr error
 
 var errSourceIsDir = func(URL string) *probe.Error {
@@ -0,0 +1,253 @@
+/*
+ * MinIO Client (C) 2019 MinIO, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/probe"
+)
+
+// Name of the ignore file honored at the root of cp and mirror sources.
+const mcIgnoreFile = ".mcignore"
+
+// ignoreRule is a single parsed line of an ignore file, following
+// gitignore semantics.
+type ignoreRule struct {
+	pattern  string
+	negate   bool // rule starts with '!', re-includes a previously ignored path.
+	dirOnly  bool // rule ends with '/', matches only directories.
+	anchored bool // rule contains a '/', matches relative to the root only.
+}
+
+// parseIgnoreRules reads gitignore style rules from reader, blank
+// lines and lines starting with '#' are skipped.
+func parseIgnoreRules(reader io.Reader) ([]ignoreRule, *probe.Error) {
+	var rules []ignoreRule
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		var rule ignoreRule
+		switch {
+		case strings.HasPrefix(line, "!"):
+			rule.negate = true
+			line = line[1:]
+		case strings.HasPrefix(line, `\!`), strings.HasPrefix(line, `\#`):
+			line = line[1:]
+		}
+		if strings.HasSuffix(line, "/") {
+			rule.dirOnly = true
+			line = strings.TrimRight(line, "/")
+		}
+		if strings.Contains(line, "/") {
+			rule.anchored = true
+			line = strings.TrimPrefix(line, "/")
+		}
+		if line == "" {
+			continue
+		}
+		rule.pattern = line
+		rules = append(rules, rule)
+	}
+	if e := scanner.Err(); e != nil {
+		return nil, probe.NewError(e)
+	}
+	return rules, nil
+}
+
+// match reports whether the rule matches the slash separated name,
+// relative to the root of the ignore file.
+func (r ignoreRule) match(name string, isDir bool) bool {
+	if r.dirOnly && !isDir {
+		return false
+	}
+	if r.anchored {
+		return globMatch(r.pattern, name)
+	}
+	// Patterns without a slash match at any level.
+	return globMatch(r.pattern, path.Base(name))
+}
+
+// globMatch matches name against a slash separated pattern, a '**'
+// path element matches zero or more path elements.
+func globMatch(pattern, name string) bool {
+	return globMatchElems(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func globMatchElems(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			// Collapse consecutive '**' elements.
+			for len(pattern) > 0 && pattern[0] == "**" {
+				pattern = pattern[1:]
+			}
+			if len(pattern) == 0 {
+				// A trailing '**' matches everything inside.
+				return len(name) > 0
+			}
+			for i := range name {
+				if globMatchElems(pattern, name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, e := path.Match(pattern[0], name[0]); e != nil || !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
+
+// matchIgnoreRules reports whether name is ignored by rules. As with
+// gitignore, the last matching rule wins and a path cannot be
+// re-included once one of its parent folders is ignored.
+func matchIgnoreRules(rules []ignoreRule, name string) bool {
+	if len(rules) == 0 {
+		return false
+	}
+	name = strings.Trim(filepath.ToSlash(name), "/")
+	if name == "" {
+		return false
+	}
+	elems := strings.Split(name, "/")
+	for i := 1; i <= len(elems); i++ {
+		isDir := i < len(elems)
+		if isIgnored(rules, strings.Join(elems[:i], "/"), isDir) {
+			return true
+		}
+	}
+	return false
+}
+
+func isIgnored(rules []ignoreRule, name string, isDir bool) bool {
+	ignored := false
+	for _, rule := range rules {
+		if rule.match(name, isDir) {
+			ignored = !rule.negate
+		}
+	}
+	return ignored
+}
+
+// urlFilter selects the objects that are copied by cp and mirror.
+type urlFilter struct {
+	includeOptions []string
+	excludeOptions []string
+	ignoreRules    []ignoreRule
+}
+
+// isExcluded reports whether the object at suffix, relative to the
+// source or target root, should be skipped.
+func (f urlFilter) isExcluded(suffix string) bool {
+	if matchExcludeOptions(f.excludeOptions, suffix) {
+		return true
+	}
+	if len(f.includeOptions) > 0 && !matchExcludeOptions(f.includeOptions, suffix) {
+		return true
+	}
+	return matchIgnoreRules(f.ignoreRules, suffix)
+}
+
+// readPatternFile reads one wildcard pattern per line from filename,
+// blank lines and lines starting with '#' are skipped.
+func readPatternFile(filename string) ([]string, *probe.Error) {
+	f, e := os.Open(filename)
+	if e != nil {
+		return nil, probe.NewError(e).Trace(filename)
+	}
+	defer f.Close()
+
+	var patterns []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		patterns = append(patterns, line)
+	}
+	if e = scanner.Err(); e != nil {
+		return nil, probe.NewError(e).Trace(filename)
+	}
+	return patterns, nil
+}
+
+// newURLFilter builds a filter from the --include, --exclude,
+// --include-from and --exclude-from flags.
+func newURLFilter(ctx *cli.Context) (urlFilter, *probe.Error) {
+	f := urlFilter{
+		includeOptions: ctx.StringSlice("include"),
+		excludeOptions: ctx.StringSlice("exclude"),
+	}
+	for _, filename := range ctx.StringSlice("include-from") {
+		patterns, err := readPatternFile(filename)
+		if err != nil {
+			return f, err.Trace(filename)
+		}
+		f.includeOptions = append(f.includeOptions, patterns...)
+	}
+	for _, filename := range ctx.StringSlice("exclude-from") {
+		patterns, err := readPatternFile(filename)
+		if err != nil {
+			return f, err.Trace(filename)
+		}
+		f.excludeOptions = append(f.excludeOptions, patterns...)
+	}
+	return f, nil
+}
+
+// withIgnoreFile returns a copy of the filter honoring the ignore
+// file found at the root of aliasedURL, if any.
+func (f urlFilter) withIgnoreFile(aliasedURL string, encKeyDB map[string][]prefixSSEPair) (urlFilter, *probe.Error) {
+	rules, err := loadIgnoreRules(aliasedURL, encKeyDB)
+	if err != nil {
+		return f, err.Trace(aliasedURL)
+	}
+	f.ignoreRules = rules
+	return f, nil
+}
+
+// loadIgnoreRules reads the ignore file at the root of aliasedURL,
+// a source without an ignore file has no rules.
+func loadIgnoreRules(aliasedURL string, encKeyDB map[string][]prefixSSEPair) ([]ignoreRule, *probe.Error) {
+	ignoreURL := urlJoinPath(aliasedURL, mcIgnoreFile)
+	_, st, err := url2Stat(ignoreURL, false, false, encKeyDB)
+	if err != nil || !st.Type.IsRegular() {
+		// Ignore file is missing or the source is not a folder.
+		return nil, nil
+	}
+	reader, err := getSourceStreamFromURL(ignoreURL, encKeyDB)
+	if err != nil {
+		return nil, err.Trace(ignoreURL)
+	}
+	defer reader.Close()
+	return parseIgnoreRules(reader)
+}
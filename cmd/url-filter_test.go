@@ -0,0 +1,95 @@
+/*
+ * MinIO Client (C) 2019 MinIO, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMatchIgnoreRules(t *testing.T) {
+	ignoreFile := `
+# build outputs
+*.o
+/vendor/
+build/
+!build/keep.txt
+docs/**/*.tmp
+**/cache/**
+!important.o
+\#literal
+`
+	rules, err := parseIgnoreRules(strings.NewReader(ignoreFile))
+	if err != nil {
+		t.Fatalf("Unable to parse ignore rules: %s", err)
+	}
+
+	testCases := []struct {
+		name    string
+		ignored bool
+	}{
+		{"main.go", false},
+		{"main.o", true},
+		{"pkg/lib/util.o", true},
+		{"important.o", false},
+		{"pkg/important.o", false},
+		{"vendor/github.com/x.go", true},
+		{"pkg/vendor/x.go", false},
+		{"vendor", false},
+		{"build/out.bin", true},
+		{"pkg/build/out.bin", true},
+		// A file cannot be re-included once its parent folder is ignored.
+		{"build/keep.txt", true},
+		{"build", false},
+		{"docs/a.tmp", true},
+		{"docs/a/b/c.tmp", true},
+		{"docs/a/b/c.md", false},
+		{"x/cache/y/z", true},
+		{"cache/z", true},
+		{"cache", false},
+		{"#literal", true},
+		{"/main.o", true},
+	}
+
+	for i, testCase := range testCases {
+		if ignored := matchIgnoreRules(rules, testCase.name); ignored != testCase.ignored {
+			t.Errorf("Test %d: expected ignored to be %t for `%s`, found %t", i+1, testCase.ignored, testCase.name, ignored)
+		}
+	}
+}
+
+func TestURLFilter(t *testing.T) {
+	testCases := []struct {
+		filter   urlFilter
+		suffix   string
+		excluded bool
+	}{
+		{urlFilter{}, "file.txt", false},
+		{urlFilter{excludeOptions: []string{"*.txt"}}, "dir/file.txt", true},
+		{urlFilter{includeOptions: []string{"*.txt"}}, "dir/file.txt", false},
+		{urlFilter{includeOptions: []string{"*.txt"}}, "dir/file.log", true},
+		{urlFilter{includeOptions: []string{"*.txt"}, excludeOptions: []string{"tmp*"}}, "tmp/file.txt", true},
+		{urlFilter{ignoreRules: []ignoreRule{{pattern: "*.log"}}}, "dir/file.log", true},
+		{urlFilter{includeOptions: []string{"*.log"}, ignoreRules: []ignoreRule{{pattern: "dir", dirOnly: true}}}, "dir/file.log", true},
+	}
+
+	for i, testCase := range testCases {
+		if excluded := testCase.filter.isExcluded(testCase.suffix); excluded != testCase.excluded {
+			t.Errorf("Test %d: expected excluded to be %t for `%s`, found %t", i+1, testCase.excluded, testCase.suffix, excluded)
+		}
+	}
+}
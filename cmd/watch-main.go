@@ -180,7 +180,7 @@ func mainWatch(ctx *cli.Context) error {
 			select {
 			case <-trapCh:
 				// Signal received we are done.
-				close(wo.doneChan)
+				wo.Close()
 				return
 			case event, ok := <-wo.Events():
 				if !ok {
@@ -67,6 +67,8 @@ type watchObject struct {
 	errorChan chan *probe.Error
 	// will stop the watcher goroutines
 	doneChan chan bool
+	// doneChan is closed only once
+	closeOnce sync.Once
 }
 
 // Events returns the chan receiving events
@@ -79,12 +81,12 @@ func (w *watchObject) Errors() chan *probe.Error {
 	return w.errorChan
 }
 
-// Close the watcher, will stop all goroutines
+// Close the watcher, will stop all goroutines. The events and errors
+// channels are closed by the client once its goroutines are done.
 func (w *watchObject) Close() {
-	// Cleanup
-	close(w.eventInfoChan)
-	close(w.errorChan)
-	close(w.doneChan)
+	w.closeOnce.Do(func() {
+		close(w.doneChan)
+	})
 }
 
 // Watcher can be used to have one or multiple clients watch for notifications